@@ -0,0 +1,77 @@
+// Command local serves the Lambda route table over plain HTTP for local
+// development, without Docker or the Lambda Runtime Interface Emulator.
+//
+// Usage:
+//
+//	go run ./cmd/local -addr :8080
+//	curl "localhost:8080/hello?name=Ana"
+//
+// The listen address can also be set through the LOCAL_ADDR environment variable,
+// and the API is configured from the same variables as the Lambda (see config.Load),
+// for example LOG_FORMAT=console for readable logs.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/config"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/routes"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/server"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
+)
+
+func main() {
+	defaultAddr := ":8080"
+	if addr := os.Getenv("LOCAL_ADDR"); addr != "" {
+		defaultAddr = addr
+	}
+
+	addr := flag.String("addr", defaultAddr, "address to listen on")
+	flag.Parse()
+
+	cfg, err := config.Load(os.Getenv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	loggerService := logger.New(cfg.Logger())
+
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           server.NewHTTPAdapter(routes.NewFromConfig(cfg, loggerService).HandleRequest, loggerService),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	loggerService.Log(ctx, services.LevelInfo, "Local server listening",
+		services.Field{Key: "addr", Value: *addr},
+	)
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		loggerService.Log(ctx, services.LevelError, "Local server failed",
+			services.Field{Key: "error", Value: err.Error()},
+		)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,52 @@
+// Command openapi writes the OpenAPI 3.1 document of the API, as served at
+// GET /openapi.json, for client generators and contract checks.
+//
+// Usage:
+//
+//	go run ./cmd/openapi -o openapi.json
+//	go run ./cmd/openapi > openapi.json
+//
+// The routes are built from the same environment variables as the Lambda (see
+// config.Load), so limits such as HELLO_MAX_NAMES and bearer authentication are
+// reflected in the document.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/config"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/routes"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
+)
+
+func main() {
+	output := flag.String("o", "", "file to write the document to (default stdout)")
+	flag.Parse()
+
+	if err := run(*output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(output string) error {
+	cfg, err := config.Load(os.Getenv)
+	if err != nil {
+		return err
+	}
+
+	document := routes.OpenAPI(routes.NewFromConfig(cfg, logger.New(cfg.Logger())))
+	if output != "" {
+		return document.WriteFile(output)
+	}
+
+	data, err := document.MarshalIndent()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Println(string(data))
+
+	return err
+}
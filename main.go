@@ -1,11 +1,91 @@
 package main
 
 import (
+	"os"
+
 	"github.com/aws/aws-lambda-go/lambda"
 
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/config"
 	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/router"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/routes"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
 )
 
+// envHandler selects the function this binary serves, so the API, its
+// authorizer and the single-endpoint transports can be deployed from the same
+// image:
+//
+//	LAMBDA_HANDLER=api                  (default) the routed API
+//	LAMBDA_HANDLER=token-authorizer     API Gateway TOKEN authorizer
+//	LAMBDA_HANDLER=request-authorizer   API Gateway REQUEST authorizer
+//	LAMBDA_HANDLER=alb                  hello behind an Application Load Balancer
+//	LAMBDA_HANDLER=http-api             hello behind an API Gateway HTTP API
+//	LAMBDA_HANDLER=function-url         hello behind a BUFFERED Function URL
+//	LAMBDA_HANDLER=function-url-stream  hello behind a RESPONSE_STREAM Function URL
+const envHandler = "LAMBDA_HANDLER"
+
 func main() {
-	lambda.Start(handlers.HelloHandleRequest)
+	switch handler := os.Getenv(envHandler); handler {
+	case "", "api":
+		lambda.Start(api().HandleRequest)
+	case "alb":
+		lambda.Start(handlers.NewHelloALBHandler(transport()).Handle)
+	case "http-api":
+		lambda.Start(handlers.NewHelloHTTPAPIHandler(transport()).Handle)
+	case "function-url":
+		lambda.Start(handlers.NewHelloFunctionURLHandler(transport()).Handle)
+	case "function-url-stream":
+		lambda.Start(handlers.NewHelloFunctionURLHandler(transport()).HandleStream)
+	case "token-authorizer":
+		lambda.Start(handlers.TokenAuthorizer(authorizerConfig()))
+	case "request-authorizer":
+		lambda.Start(handlers.RequestAuthorizer(authorizerConfig()))
+	default:
+		panic("main: unknown " + envHandler + " " + handler)
+	}
+}
+
+// api wires the routed API. Its dependencies are built once per container and
+// reused by every warm invocation.
+func api() *router.Router {
+	cfg := loadConfig()
+
+	return routes.NewFromConfig(cfg, logger.New(cfg.Logger()))
+}
+
+// transport returns the logger and use case of the single-endpoint transports,
+// configured like the routed API.
+func transport() (services.Logger, handlers.GreetUseCase) {
+	cfg := loadConfig()
+
+	return logger.New(cfg.Logger()), hello.Greeter{Rules: cfg.Rules()}.Greet
+}
+
+// authorizerConfig builds the authorizer from the JWT_* environment variables.
+// Unlike the API, an authorizer cannot run without keys.
+func authorizerConfig() handlers.AuthorizerConfig {
+	cfg := loadConfig()
+	verifier, err := cfg.Verifier()
+	if err != nil {
+		panic("main: " + err.Error())
+	}
+	if verifier == nil {
+		panic("main: authorizers need " + config.EnvJWTJWKSFile + ", " + config.EnvJWTJWKSURL + " or " + config.EnvJWTHS256Secret)
+	}
+
+	return handlers.AuthorizerConfig{Verifier: verifier, Logger: logger.New(cfg.Logger())}
+}
+
+// loadConfig reads the configuration from the environment (see config.Load),
+// failing the cold start when it is invalid.
+func loadConfig() config.Config {
+	cfg, err := config.Load(os.Getenv)
+	if err != nil {
+		panic("main: " + err.Error())
+	}
+
+	return cfg
 }
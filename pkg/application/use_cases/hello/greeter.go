@@ -0,0 +1,54 @@
+package hello
+
+import (
+	"context"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+)
+
+// SalutationFlag is the variant flag choosing the salutation of a greeting.
+// Its variants are the salutations themselves, e.g. "Hello" and "Hi".
+const SalutationFlag = "greeting-salutation"
+
+// Greeter greets names with Rules, letting feature flags change the greeting
+// per caller so changes can be rolled out gradually. Without Flags it behaves
+// exactly like Rules.
+//
+// Example:
+//
+//	greeter := hello.Greeter{Rules: rules, Flags: flags}
+//	message, err := greeter.SayHelloToAll(ctx, []string{"Ana"}) // "Hi Ana!" for callers served "Hi"
+type Greeter struct {
+	Rules Rules
+	Flags services.FeatureFlags
+}
+
+// SayHelloToAll greets names like Rules.SayHelloToAll, with the salutation
+// served by SalutationFlag to the entities.FlagTarget carried by ctx.
+func (g Greeter) SayHelloToAll(ctx context.Context, names []string) (string, error) {
+	return g.rules(ctx).SayHelloToAll(names)
+}
+
+// Greet greets names like Rules.Greet, with the salutation served by
+// SalutationFlag to the entities.FlagTarget carried by ctx.
+func (g Greeter) Greet(ctx context.Context, names []string) (Greeting, error) {
+	return g.rules(ctx).Greet(names)
+}
+
+// rules returns the Rules in force for the caller of ctx.
+func (g Greeter) rules(ctx context.Context) Rules {
+	rules := g.Rules
+	if g.Flags == nil {
+		return rules
+	}
+
+	// Salutations come from remote configuration, so they must pass the same
+	// checks as names before they are rendered.
+	salutation := g.Flags.Variant(ctx, SalutationFlag, entities.FlagTargetFromContext(ctx), rules.salutation())
+	if len(salutation) <= rules.NameLengthLimit() && validNamePattern.MatchString(salutation) {
+		rules.Salutation = salutation
+	}
+
+	return rules
+}
@@ -0,0 +1,140 @@
+package hello
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rules are the greeting rules applied by the use cases, so deployments can
+// tune them without a rebuild. Zero fields fall back to DefaultName and
+// MaxNameLength and DefaultSalutation; the zero Rules behave like
+// SayHelloUseCase.
+//
+// Example:
+//
+//	rules := hello.Rules{DefaultName: "friend", MaxNameLength: 20}
+//	rules.SayHello("")                          // returns "Hello friend!", nil
+//	rules.SayHelloToAll([]string{"Ana", "Eva"}) // returns "Hello Ana and Eva!", nil
+type Rules struct {
+	// DefaultName is greeted when no name is given.
+	DefaultName string
+	// MaxNameLength is the maximum length of a name, in bytes.
+	MaxNameLength int
+	// Salutation opens the greeting. Defaults to DefaultSalutation.
+	Salutation string
+}
+
+// NameTooLongError reports a name longer than the limit in force. It matches
+// ErrNameTooLong with errors.Is.
+type NameTooLongError struct {
+	MaxLength int
+}
+
+// Error implements error.
+func (e *NameTooLongError) Error() string {
+	return ErrNameTooLong.Error()
+}
+
+// Is reports whether target is ErrNameTooLong.
+func (e *NameTooLongError) Is(target error) bool {
+	return target == ErrNameTooLong
+}
+
+// SayHello applies SayHelloUseCase with these rules.
+func (r Rules) SayHello(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return fmt.Sprintf("%s %s!", r.salutation(), r.defaultName()), nil
+	}
+
+	if maxLength := r.NameLengthLimit(); len(name) > maxLength {
+		return "", &NameTooLongError{MaxLength: maxLength}
+	}
+
+	if !validNamePattern.MatchString(name) {
+		return "", ErrInvalidCharacters
+	}
+
+	return fmt.Sprintf("%s %s!", r.salutation(), name), nil
+}
+
+// SayHelloToAll applies SayHelloToAllUseCase with these rules.
+func (r Rules) SayHelloToAll(names []string) (string, error) {
+	names = NormalizeNames(names)
+	if len(names) <= 1 {
+		return r.SayHello(strings.Join(names, ""))
+	}
+
+	for _, name := range names {
+		if _, err := r.SayHello(name); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%s %s!", r.salutation(), JoinNames(names)), nil
+}
+
+// Greeting is a greeting message and who it greets.
+type Greeting struct {
+	Message string
+	// Names lists everyone greeted: the normalized names, or the default name
+	// when none is given.
+	Names []string
+}
+
+// Greet greets names like SayHelloToAll and reports who was greeted, so
+// callers never have to repeat the default name.
+//
+// Example:
+//
+//	hello.Rules{DefaultName: "friend"}.Greet(nil) // returns Greeting{Message: "Hello friend!", Names: []string{"friend"}}, nil
+func (r Rules) Greet(names []string) (Greeting, error) {
+	message, err := r.SayHelloToAll(names)
+	if err != nil {
+		return Greeting{}, err
+	}
+
+	greeted := NormalizeNames(names)
+	if len(greeted) == 0 {
+		greeted = []string{r.defaultName()}
+	}
+
+	return Greeting{Message: message, Names: greeted}, nil
+}
+
+// Name returns who SayHello greets for name: the trimmed name, or the default
+// name when it is blank.
+func (r Rules) Name(name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+
+	return r.defaultName()
+}
+
+func (r Rules) defaultName() string {
+	if name := strings.TrimSpace(r.DefaultName); name != "" {
+		return name
+	}
+
+	return DefaultName
+}
+
+// NameLengthLimit returns the maximum length of a name enforced by r, in
+// bytes: MaxNameLength when set, otherwise the package MaxNameLength.
+func (r Rules) NameLengthLimit() int {
+	if r.MaxNameLength > 0 {
+		return r.MaxNameLength
+	}
+
+	return MaxNameLength
+}
+
+func (r Rules) salutation() string {
+	if salutation := strings.TrimSpace(r.Salutation); salutation != "" {
+		return salutation
+	}
+
+	return DefaultSalutation
+}
@@ -2,21 +2,24 @@ package hello
 
 import (
 	"errors"
-	"fmt"
 	"regexp"
-	"strings"
 )
 
 const (
-	// MaxNameLength defines the maximum allowed length for a name
+	// MaxNameLength defines the maximum allowed length for a name, unless
+	// Rules.MaxNameLength overrides it
 	MaxNameLength = 100
-	// DefaultName is used when no name is provided
+	// DefaultName is used when no name is provided, unless Rules.DefaultName
+	// overrides it
 	DefaultName = "world"
+	// DefaultSalutation opens the greeting, unless Rules.Salutation overrides it
+	DefaultSalutation = "Hello"
 )
 
 // Validation errors
 var (
-	// ErrNameTooLong indicates that the name exceeds the maximum allowed length
+	// ErrNameTooLong indicates that the name exceeds the maximum allowed length.
+	// Use errors.As with *NameTooLongError to read the limit.
 	ErrNameTooLong = errors.New("name exceeds maximum length")
 
 	// ErrInvalidCharacters indicates that the name contains invalid characters
@@ -42,19 +45,5 @@ var validNamePattern = regexp.MustCompile(`^[a-zA-Z0-9\s\-'áéíóúÁÉÍÓÚ
 //	SayHelloUseCase("Very long...")   // returns "", ErrNameTooLong
 //	SayHelloUseCase("<script>")       // returns "", ErrInvalidCharacters
 func SayHelloUseCase(name string) (string, error) {
-	name = strings.TrimSpace(name)
-
-	if name == "" {
-		return fmt.Sprintf("Hello %s!", DefaultName), nil
-	}
-
-	if len(name) > MaxNameLength {
-		return "", ErrNameTooLong
-	}
-
-	if !validNamePattern.MatchString(name) {
-		return "", ErrInvalidCharacters
-	}
-
-	return fmt.Sprintf("Hello %s!", name), nil
+	return Rules{}.SayHello(name)
 }
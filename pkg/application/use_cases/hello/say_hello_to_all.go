@@ -0,0 +1,40 @@
+package hello
+
+import "strings"
+
+// SayHelloToAllUseCase generates a single greeting for several names.
+// Each name follows the SayHelloUseCase rules; blank names are skipped, and
+// zero or one remaining name produces the same greeting as SayHelloUseCase.
+//
+// Returns the greeting message and the validation error of the first invalid name.
+//
+// Example:
+//
+//	SayHelloToAllUseCase([]string{"Ana", "Luis"})        // returns "Hello Ana and Luis!", nil
+//	SayHelloToAllUseCase([]string{"Ana", "Luis", "Eva"}) // returns "Hello Ana, Luis and Eva!", nil
+//	SayHelloToAllUseCase([]string{"Ana", "<script>"})    // returns "", ErrInvalidCharacters
+func SayHelloToAllUseCase(names []string) (string, error) {
+	return Rules{}.SayHelloToAll(names)
+}
+
+// NormalizeNames trims every name and drops the blank ones.
+func NormalizeNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			normalized = append(normalized, name)
+		}
+	}
+
+	return normalized
+}
+
+// JoinNames lists names as in a sentence: "Ana", "Ana and Luis",
+// "Ana, Luis and Eva".
+func JoinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
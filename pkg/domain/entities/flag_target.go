@@ -0,0 +1,38 @@
+package entities
+
+import "context"
+
+// FlagTarget is who a feature flag is evaluated for.
+type FlagTarget struct {
+	// Key keeps percentage rollouts sticky: the same key always lands in the
+	// same bucket. It is usually the user ID, or the request ID for anonymous
+	// callers.
+	Key string
+	// Attributes are matched by targeting rules, e.g. "user_id" or "path".
+	Attributes map[string]string
+}
+
+// flagTargetContextKey is the typed context key for FlagTarget.
+type flagTargetContextKey struct{}
+
+// ContextWithFlagTarget returns a copy of ctx carrying target.
+func ContextWithFlagTarget(ctx context.Context, target FlagTarget) context.Context {
+	return context.WithValue(ctx, flagTargetContextKey{}, target)
+}
+
+// FlagTargetFromContext returns the FlagTarget stored in ctx, or the zero
+// FlagTarget when there is none.
+//
+// Example:
+//
+//	target := entities.FlagTargetFromContext(ctx)
+//	if flags.Bool(ctx, "new-greeting", target, false) { ... }
+func FlagTargetFromContext(ctx context.Context) FlagTarget {
+	if ctx == nil {
+		return FlagTarget{}
+	}
+
+	target, _ := ctx.Value(flagTargetContextKey{}).(FlagTarget)
+
+	return target
+}
@@ -0,0 +1,37 @@
+package entities
+
+import "context"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller, e.g. the "sub" claim of a JWT.
+	Subject string
+	// Claims holds every verified claim, keyed by claim name.
+	Claims map[string]interface{}
+}
+
+// principalContextKey is the typed context key for Principal. Being unexported,
+// it cannot collide with keys set by other packages.
+type principalContextKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying principal.
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the Principal stored in ctx, if any.
+//
+// Example:
+//
+//	if principal, ok := entities.PrincipalFromContext(ctx); ok {
+//	    fmt.Println("called by", principal.Subject)
+//	}
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	if ctx == nil {
+		return Principal{}, false
+	}
+
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+
+	return principal, ok
+}
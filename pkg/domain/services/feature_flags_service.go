@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+)
+
+// FeatureFlags evaluates feature flags, so behaviour can be rolled out
+// gradually or per audience without a deployment.
+//
+// Evaluation never fails: unknown flags, and flags whose provider cannot be
+// reached, return the caller's default, so code keeps its current behaviour
+// until a flag is defined. A flag switched off evaluates to false, or to the
+// caller's default for variant flags.
+//
+// Example usage:
+//
+//	target := entities.FlagTargetFromContext(ctx)
+//	if flags.Bool(ctx, "greeting-emoji", target, false) {
+//	    message += " 👋"
+//	}
+//	salutation := flags.Variant(ctx, "greeting-salutation", target, "Hello")
+type FeatureFlags interface {
+	// Bool reports whether the boolean flag is on for target, or def when the
+	// flag is unknown.
+	Bool(ctx context.Context, flag string, target entities.FlagTarget, def bool) bool
+	// Variant returns the variant of flag served to target, or def when the
+	// flag is unknown or switched off.
+	Variant(ctx context.Context, flag string, target entities.FlagTarget, def string) string
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// HealthChecker reports whether a dependency the service relies on, such as
+// a database table or a configuration endpoint, is usable. The /health
+// endpoint runs every registered checker and answers 503 when one fails.
+//
+// Check must honour ctx: it is cancelled when Timeout, or the endpoint's
+// default timeout when Timeout returns zero, elapses. A checker that does
+// not return in time is reported as failed.
+//
+// Example usage:
+//
+//	checker := services.NewHealthChecker("orders-table", time.Second, func(ctx context.Context) error {
+//	    _, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("orders")})
+//	    return err
+//	})
+type HealthChecker interface {
+	// Name identifies the dependency in health reports. It must be unique.
+	Name() string
+	// Timeout bounds Check. Zero means the endpoint's default timeout.
+	Timeout() time.Duration
+	// Check returns nil when the dependency is healthy.
+	Check(ctx context.Context) error
+}
+
+// NewHealthChecker returns a HealthChecker named name that runs check with
+// timeout.
+func NewHealthChecker(name string, timeout time.Duration, check func(ctx context.Context) error) HealthChecker {
+	return healthCheckFunc{name: name, timeout: timeout, check: check}
+}
+
+type healthCheckFunc struct {
+	name    string
+	timeout time.Duration
+	check   func(ctx context.Context) error
+}
+
+func (h healthCheckFunc) Name() string {
+	return h.name
+}
+
+func (h healthCheckFunc) Timeout() time.Duration {
+	return h.timeout
+}
+
+func (h healthCheckFunc) Check(ctx context.Context) error {
+	return h.check(ctx)
+}
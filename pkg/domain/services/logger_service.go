@@ -1,6 +1,10 @@
 package services
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 // Level represents the severity level of a log message.
 type Level int
@@ -44,6 +48,29 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel returns the Level named s, as written by Level.String. It is
+// case-insensitive and also accepts "warning".
+//
+// Example:
+//
+//	level, err := ParseLevel("DEBUG") // LevelDebug, nil
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
 // Field represents a structured logging field with a key-value pair.
 type Field struct {
 	Key   string
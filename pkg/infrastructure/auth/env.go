@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"errors"
+)
+
+// Environment variables read by VerifierFromEnv.
+const (
+	EnvJWKSFile     = "JWT_JWKS_FILE"
+	EnvJWKSURL      = "JWT_JWKS_URL"
+	EnvHS256Secret  = "JWT_HS256_SECRET"
+	EnvIssuer       = "JWT_ISSUER"
+	EnvAudience     = "JWT_AUDIENCE"
+	envKeySourceSet = EnvJWKSFile + ", " + EnvJWKSURL + " or " + EnvHS256Secret
+)
+
+// Settings selects the key source and the required claims of a Verifier.
+// At most one of JWKSFile, JWKSURL and HS256Secret may be set; with none,
+// authentication is disabled.
+type Settings struct {
+	// JWKSFile is a JWKS document bundled with the function. JWT_JWKS_FILE.
+	JWKSFile string
+	// JWKSURL is a JWKS endpoint. JWT_JWKS_URL.
+	JWKSURL string
+	// HS256Secret is a shared HS256 secret. JWT_HS256_SECRET.
+	HS256Secret string
+	// Issuer is the required iss claim, if any. JWT_ISSUER.
+	Issuer string
+	// Audience is the required aud value, if any. JWT_AUDIENCE.
+	Audience string
+}
+
+// KeySources returns the environment variables of the key sources set in s.
+func (s Settings) KeySources() []string {
+	var sources []string
+	if s.JWKSFile != "" {
+		sources = append(sources, EnvJWKSFile)
+	}
+	if s.JWKSURL != "" {
+		sources = append(sources, EnvJWKSURL)
+	}
+	if s.HS256Secret != "" {
+		sources = append(sources, EnvHS256Secret)
+	}
+
+	return sources
+}
+
+// Verifier builds the Verifier described by s, loading JWKSFile when set. It
+// returns nil without error when no key source is set.
+func (s Settings) Verifier() (*Verifier, error) {
+	var keys KeySet
+	switch sources := s.KeySources(); {
+	case len(sources) == 0:
+		return nil, nil
+	case len(sources) > 1:
+		return nil, errors.New("auth: set only one of " + envKeySourceSet)
+	case s.JWKSFile != "":
+		jwks, err := LoadJWKSFile(s.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = jwks
+	case s.JWKSURL != "":
+		keys = NewRemoteJWKS(RemoteJWKSConfig{URL: s.JWKSURL})
+	default:
+		keys = HMACKeySet([]byte(s.HS256Secret))
+	}
+
+	return NewVerifier(VerifierConfig{Keys: keys, Issuer: s.Issuer, Audience: s.Audience}), nil
+}
+
+// VerifierFromEnv builds a Verifier from environment variables read with
+// getenv (usually os.Getenv). It returns nil without error when no key source
+// is configured, meaning authentication is disabled.
+//
+// Keys come from exactly one of JWT_JWKS_FILE, JWT_JWKS_URL or
+// JWT_HS256_SECRET; JWT_ISSUER and JWT_AUDIENCE are optional.
+func VerifierFromEnv(getenv func(string) string) (*Verifier, error) {
+	return Settings{
+		JWKSFile:    getenv(EnvJWKSFile),
+		JWKSURL:     getenv(EnvJWKSURL),
+		HS256Secret: getenv(EnvHS256Secret),
+		Issuer:      getenv(EnvIssuer),
+		Audience:    getenv(EnvAudience),
+	}.Verifier()
+}
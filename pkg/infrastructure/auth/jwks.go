@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxJWKSBytes caps the size of a fetched JWKS document.
+const maxJWKSBytes = 1 << 20
+
+// DefaultJWKSRefreshInterval is the minimum time between two fetches of a
+// RemoteJWKS when RemoteJWKSConfig.MinRefreshInterval is not set.
+const DefaultJWKSRefreshInterval = 5 * time.Minute
+
+// jwk is a JSON Web Key as found in a JWKS document (RFC 7517).
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+	// Symmetric
+	K string `json:"k"`
+
+	key interface{}
+}
+
+// algorithm returns the signing algorithm the key is usable with.
+func (k jwk) algorithm() string {
+	switch k.KeyType {
+	case "RSA":
+		return AlgorithmRS256
+	case "EC":
+		return AlgorithmES256
+	default:
+		return AlgorithmHS256
+	}
+}
+
+// JWKS is a static set of verification keys.
+type JWKS struct {
+	keys []jwk
+}
+
+// ParseJWKS parses a JWKS document. Keys of unsupported types, curves or uses
+// are skipped so a shared document can also carry keys for other services.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("parse JWKS: %w", err)
+	}
+
+	set := &JWKS{}
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		parsed, err := parseKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWKS key %q: %w", key.KeyID, err)
+		}
+		if parsed == nil {
+			continue
+		}
+
+		key.key = parsed
+		set.keys = append(set.keys, key)
+	}
+
+	return set, nil
+}
+
+// LoadJWKSFile reads and parses the JWKS document at path.
+func LoadJWKSFile(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JWKS: %w", err)
+	}
+
+	return ParseJWKS(data)
+}
+
+// HMACKeySet returns a JWKS holding a single HS256 secret without a key id.
+func HMACKeySet(secret []byte) *JWKS {
+	return &JWKS{keys: []jwk{{KeyType: "oct", Algorithm: AlgorithmHS256, key: secret}}}
+}
+
+// VerificationKey implements KeySet. Without a kid, the key is only resolved
+// when exactly one key is usable with alg.
+func (s *JWKS) VerificationKey(_ context.Context, kid, alg string) (interface{}, error) {
+	var match *jwk
+	for i, key := range s.keys {
+		if key.algorithm() != alg || (key.Algorithm != "" && key.Algorithm != alg) {
+			continue
+		}
+		if kid != "" && key.KeyID == kid {
+			return key.key, nil
+		}
+		if kid == "" {
+			if match != nil {
+				return nil, fmt.Errorf("%w: token has no kid and several %s keys exist", ErrUnknownKey, alg)
+			}
+			match = &s.keys[i]
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("%w: kid %q, alg %s", ErrUnknownKey, kid, alg)
+	}
+
+	return match.key, nil
+}
+
+// parseKey builds the verification key of k, or returns nil for unsupported keys.
+func parseKey(k jwk) (interface{}, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 2 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA parameters")
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y coordinate: %w", err)
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return nil, fmt.Errorf("invalid P-256 coordinates")
+		}
+		publicKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return nil, fmt.Errorf("invalid P-256 point: %w", err)
+		}
+
+		return publicKey, nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, fmt.Errorf("invalid symmetric key")
+		}
+
+		return secret, nil
+	default:
+		return nil, nil
+	}
+}
+
+// RemoteJWKSConfig configures a RemoteJWKS.
+type RemoteJWKSConfig struct {
+	// URL is the JWKS endpoint.
+	URL string
+	// Client performs the requests. Defaults to a client with a 5 second timeout.
+	Client *http.Client
+	// MinRefreshInterval limits how often an unknown kid triggers a refetch.
+	// Zero means DefaultJWKSRefreshInterval.
+	MinRefreshInterval time.Duration
+}
+
+// RemoteJWKS fetches keys from a JWKS endpoint on first use and refetches them
+// when a token references an unknown key, so key rotation is picked up without
+// a redeploy. The fetched set is cached for the lifetime of the container.
+type RemoteJWKS struct {
+	config    RemoteJWKSConfig
+	mu        sync.Mutex
+	keys      *JWKS
+	fetchedAt time.Time
+}
+
+// NewRemoteJWKS returns a RemoteJWKS for config.
+func NewRemoteJWKS(config RemoteJWKSConfig) *RemoteJWKS {
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	if config.MinRefreshInterval <= 0 {
+		config.MinRefreshInterval = DefaultJWKSRefreshInterval
+	}
+
+	return &RemoteJWKS{config: config}
+}
+
+// VerificationKey implements KeySet.
+func (r *RemoteJWKS) VerificationKey(ctx context.Context, kid, alg string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.keys != nil {
+		key, err := r.keys.VerificationKey(ctx, kid, alg)
+		if err == nil || time.Since(r.fetchedAt) < r.config.MinRefreshInterval {
+			return key, err
+		}
+	}
+
+	keys, err := r.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.keys = keys
+	r.fetchedAt = time.Now()
+
+	return r.keys.VerificationKey(ctx, kid, alg)
+}
+
+func (r *RemoteJWKS) fetch(ctx context.Context) (*JWKS, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, r.config.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := r.config.Client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: unexpected status %d", response.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxJWKSBytes))
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+
+	return ParseJWKS(data)
+}
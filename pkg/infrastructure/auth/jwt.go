@@ -0,0 +1,288 @@
+// Package auth verifies JSON Web Tokens signed with HS256, RS256 or ES256
+// against keys from a JWKS document, a JWKS endpoint or a shared secret.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Signing algorithms accepted by Verifier.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// Token verification errors. Verify wraps them with details, so use errors.Is.
+var (
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnknownKey           = errors.New("no key matches the token")
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrTokenExpired         = errors.New("token is expired")
+	ErrTokenNotYetValid     = errors.New("token is not valid yet")
+	ErrInvalidIssuer        = errors.New("invalid token issuer")
+	ErrInvalidAudience      = errors.New("invalid token audience")
+	ErrMissingClaim         = errors.New("missing required claim")
+)
+
+// Claims are the verified claims of a token.
+type Claims map[string]interface{}
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string {
+	subject, _ := c["sub"].(string)
+	return subject
+}
+
+// Issuer returns the "iss" claim.
+func (c Claims) Issuer() string {
+	issuer, _ := c["iss"].(string)
+	return issuer
+}
+
+// Audience returns the "aud" claim, which may be a string or a list of strings.
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		audience := make([]string, 0, len(aud))
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				audience = append(audience, s)
+			}
+		}
+		return audience
+	default:
+		return nil
+	}
+}
+
+// time returns a NumericDate claim.
+func (c Claims) time(name string) (time.Time, bool, error) {
+	value, ok := c[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("%w: %q is not a number", ErrMalformedToken, name)
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %q is not a number", ErrMalformedToken, name)
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), true, nil
+}
+
+// KeySet resolves the key that verifies a token.
+type KeySet interface {
+	// VerificationKey returns the key identified by kid that is usable with
+	// alg: []byte for HS256, *rsa.PublicKey for RS256 or *ecdsa.PublicKey for ES256.
+	VerificationKey(ctx context.Context, kid, alg string) (interface{}, error)
+}
+
+// VerifierConfig configures a Verifier.
+type VerifierConfig struct {
+	// Keys resolves verification keys.
+	Keys KeySet
+	// Issuer, when set, must equal the "iss" claim.
+	Issuer string
+	// Audience, when set, must be one of the "aud" claim values.
+	Audience string
+	// Leeway tolerates clock skew when checking "exp" and "nbf".
+	Leeway time.Duration
+	// Now returns the current time. Defaults to time.Now; tests can override it.
+	Now func() time.Time
+}
+
+// Verifier checks token signatures and registered claims. Tokens must carry an
+// "exp" claim; "nbf" is honoured when present.
+type Verifier struct {
+	config VerifierConfig
+}
+
+// NewVerifier returns a Verifier for config.
+func NewVerifier(config VerifierConfig) *Verifier {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &Verifier{config: config}
+}
+
+// tokenHeader is the JOSE header of a token.
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Verify checks token and returns its claims.
+//
+// Example:
+//
+//	claims, err := verifier.Verify(ctx, "eyJhbGciOiJIUzI1NiIs...")
+//	if errors.Is(err, auth.ErrTokenExpired) { ... }
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 segments", ErrMalformedToken)
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %s", ErrMalformedToken, err.Error())
+	}
+
+	switch header.Algorithm {
+	case AlgorithmHS256, AlgorithmRS256, AlgorithmES256:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature encoding", ErrMalformedToken)
+	}
+
+	key, err := v.config.Keys.VerificationKey(ctx, header.KeyID, header.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Algorithm, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %s", ErrMalformedToken, err.Error())
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) validateClaims(claims Claims) error {
+	now := v.config.Now()
+
+	expiresAt, ok, err := claims.time("exp")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: exp", ErrMissingClaim)
+	}
+	if !now.Before(expiresAt.Add(v.config.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	notBefore, ok, err := claims.time("nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(v.config.Leeway).Before(notBefore) {
+		return ErrTokenNotYetValid
+	}
+
+	if v.config.Issuer != "" && claims.Issuer() != v.config.Issuer {
+		return fmt.Errorf("%w: %q", ErrInvalidIssuer, claims.Issuer())
+	}
+
+	if v.config.Audience != "" && !contains(claims.Audience(), v.config.Audience) {
+		return ErrInvalidAudience
+	}
+
+	if claims.Subject() == "" {
+		return fmt.Errorf("%w: sub", ErrMissingClaim)
+	}
+
+	return nil
+}
+
+// verifySignature checks signature over signingInput. The key type must match
+// alg, which prevents algorithm confusion such as an RSA public key being used
+// as an HMAC secret.
+func verifySignature(alg string, key interface{}, signingInput, signature []byte) error {
+	digest := sha256.Sum256(signingInput)
+
+	switch alg {
+	case AlgorithmHS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("%w: key is not an HMAC secret", ErrUnknownKey)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signingInput)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrInvalidSignature
+		}
+	case AlgorithmRS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key is not an RSA key", ErrUnknownKey)
+		}
+		if rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+	case AlgorithmES256:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key is not an EC key", ErrUnknownKey)
+		}
+		if len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+
+	return nil
+}
+
+// decodeSegment decodes a base64url JSON segment, keeping numbers as json.Number.
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
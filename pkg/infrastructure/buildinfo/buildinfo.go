@@ -0,0 +1,53 @@
+// Package buildinfo describes the running binary: its version and when the
+// execution environment started it.
+package buildinfo
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// version is set at link time:
+//
+//	go build -ldflags "-X github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/buildinfo.version=v1.2.3"
+var version string
+
+// ColdStart is when this execution environment loaded the binary. Warm
+// invocations share it, so time.Since(ColdStart) is the uptime of the
+// environment.
+var ColdStart = time.Now()
+
+// Version returns the version set at link time. Without one, it falls back to
+// the VCS revision recorded by the Go toolchain ("1a2b3c4d5e6f", with a
+// "-dirty" suffix for modified trees), then to "dev".
+func Version() string {
+	if version != "" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+	if revision == "" {
+		return "dev"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified == "true" {
+		revision += "-dirty"
+	}
+
+	return revision
+}
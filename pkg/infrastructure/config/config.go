@@ -0,0 +1,288 @@
+package config
+
+import (
+	"time"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/featureflags"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/ratelimit"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
+)
+
+// Environment variables read by Load.
+const (
+	EnvHelloDefaultName    = "HELLO_DEFAULT_NAME"
+	EnvHelloMaxNameLength  = "HELLO_MAX_NAME_LENGTH"
+	EnvHelloMaxNames       = "HELLO_MAX_NAMES"
+	EnvHelloMaxBatchSize   = "HELLO_MAX_BATCH_SIZE"
+	EnvLogLevel            = "LOG_LEVEL"
+	EnvLogFormat           = "LOG_FORMAT"
+	EnvRequestTimeout      = "REQUEST_TIMEOUT"
+	EnvTimeoutSafetyMargin = "TIMEOUT_SAFETY_MARGIN"
+	EnvCORSAllowedOrigins  = "CORS_ALLOWED_ORIGINS"
+	EnvHealthCheckTimeout  = "HEALTH_CHECK_TIMEOUT"
+	EnvRateLimitRequests   = "RATE_LIMIT_REQUESTS"
+	EnvRateLimitPer        = "RATE_LIMIT_PER"
+	EnvRateLimitBurst      = "RATE_LIMIT_BURST"
+
+	EnvFeatureFlagsFile            = "FEATURE_FLAGS_FILE"
+	EnvFeatureFlagsRefreshInterval = "FEATURE_FLAGS_REFRESH_INTERVAL"
+	EnvAppConfigURL                = "APPCONFIG_URL"
+	EnvAppConfigApplication        = "APPCONFIG_APPLICATION"
+	EnvAppConfigEnvironment        = "APPCONFIG_ENVIRONMENT"
+	EnvAppConfigProfile            = "APPCONFIG_PROFILE"
+
+	EnvJWTJWKSFile    = auth.EnvJWKSFile
+	EnvJWTJWKSURL     = auth.EnvJWKSURL
+	EnvJWTHS256Secret = auth.EnvHS256Secret
+	EnvJWTIssuer      = auth.EnvIssuer
+	EnvJWTAudience    = auth.EnvAudience
+)
+
+// DefaultRequestTimeout is the API Gateway REST API integration timeout.
+const DefaultRequestTimeout = 29 * time.Second
+
+// Config holds the settings loaded at cold start.
+type Config struct {
+	Hello HelloConfig
+	Log   LogConfig
+	HTTP  HTTPConfig
+	Flags FlagsConfig
+	// Auth configures bearer authentication of the API. It is disabled unless
+	// one of JWT_JWKS_FILE, JWT_JWKS_URL or JWT_HS256_SECRET is set;
+	// JWT_ISSUER and JWT_AUDIENCE are optional.
+	Auth auth.Settings
+}
+
+// HelloConfig configures the greeting use case and handlers.
+type HelloConfig struct {
+	// DefaultName is greeted when no name is given. HELLO_DEFAULT_NAME, default "world".
+	DefaultName string
+	// MaxNameLength caps the length of a name. HELLO_MAX_NAME_LENGTH, default 100.
+	MaxNameLength int
+	// MaxNames caps the names of one GET or POST /hello. HELLO_MAX_NAMES,
+	// default 0 (no limit).
+	MaxNames int
+	// MaxBatchSize caps the names of POST /hello/batch. HELLO_MAX_BATCH_SIZE, default 500.
+	MaxBatchSize int
+}
+
+// LogConfig configures the logger.
+type LogConfig struct {
+	// Level is the minimum level written. LOG_LEVEL (trace, debug, info, warn
+	// or error), default info.
+	Level services.Level
+	// Format is LOG_FORMAT (json or console), default json.
+	Format logger.Format
+}
+
+// HTTPConfig configures the API middleware.
+type HTTPConfig struct {
+	// RequestTimeout caps the time a request may take. REQUEST_TIMEOUT, default 29s.
+	RequestTimeout time.Duration
+	// TimeoutSafetyMargin is reserved before the invocation deadline.
+	// TIMEOUT_SAFETY_MARGIN, default 500ms.
+	TimeoutSafetyMargin time.Duration
+	// AllowedOrigins are the CORS origins. CORS_ALLOWED_ORIGINS, a comma-separated
+	// list, default "*".
+	AllowedOrigins []string
+	// HealthCheckTimeout bounds each /health dependency check.
+	// HEALTH_CHECK_TIMEOUT, default 2s.
+	HealthCheckTimeout time.Duration
+	// RateLimit is the per-client token bucket. RATE_LIMIT_REQUESTS per
+	// RATE_LIMIT_PER, in bursts of up to RATE_LIMIT_BURST (0 means
+	// RATE_LIMIT_REQUESTS); default handlers.DefaultRateLimit.
+	RateLimit ratelimit.Limit
+}
+
+// FlagsConfig selects the feature flag provider. At most one of File and
+// AppConfig may be set; with neither, every flag evaluates to its default.
+type FlagsConfig struct {
+	// File is a local JSON or YAML flag document. FEATURE_FLAGS_FILE.
+	File string
+	// AppConfig reads the flags through the AWS AppConfig Lambda extension. It
+	// is enabled by APPCONFIG_APPLICATION, APPCONFIG_ENVIRONMENT and
+	// APPCONFIG_PROFILE, which must be set together; APPCONFIG_URL (default
+	// http://localhost:2772) and FEATURE_FLAGS_REFRESH_INTERVAL (default 45s)
+	// are optional.
+	AppConfig featureflags.AppConfigConfig
+}
+
+// Load reads the configuration from environment variables through getenv
+// (usually os.Getenv) and validates it. The returned error is a
+// *ValidationError listing every invalid variable; callers should fail the
+// cold start with it.
+//
+// Example:
+//
+//	cfg, err := config.Load(os.Getenv)
+//	if err != nil {
+//	    panic(err) // invalid configuration: HELLO_MAX_NAMES must not be negative, got -1; ...
+//	}
+func Load(getenv func(string) string) (Config, error) {
+	env := NewEnv(getenv)
+
+	config := Config{
+		Hello: HelloConfig{
+			DefaultName:   env.String(EnvHelloDefaultName, hello.DefaultName),
+			MaxNameLength: env.Int(EnvHelloMaxNameLength, hello.MaxNameLength),
+			MaxNames:      env.Int(EnvHelloMaxNames, 0),
+			MaxBatchSize:  env.Int(EnvHelloMaxBatchSize, handlers.DefaultMaxBatchSize),
+		},
+		Log: LogConfig{
+			Level:  services.LevelInfo,
+			Format: logger.FormatJSON,
+		},
+		HTTP: HTTPConfig{
+			RequestTimeout:      env.Duration(EnvRequestTimeout, DefaultRequestTimeout),
+			TimeoutSafetyMargin: env.Duration(EnvTimeoutSafetyMargin, handlers.DefaultTimeoutSafetyMargin),
+			AllowedOrigins:      env.List(EnvCORSAllowedOrigins, []string{"*"}),
+			HealthCheckTimeout:  env.Duration(EnvHealthCheckTimeout, handlers.DefaultHealthCheckTimeout),
+			RateLimit: ratelimit.Limit{
+				Requests: env.Int(EnvRateLimitRequests, handlers.DefaultRateLimit.Requests),
+				Per:      env.Duration(EnvRateLimitPer, handlers.DefaultRateLimit.Per),
+				Burst:    env.Int(EnvRateLimitBurst, handlers.DefaultRateLimit.Burst),
+			},
+		},
+		Flags: FlagsConfig{
+			File: env.String(EnvFeatureFlagsFile, ""),
+			AppConfig: featureflags.AppConfigConfig{
+				URL:             env.String(EnvAppConfigURL, featureflags.DefaultAppConfigURL),
+				Application:     env.String(EnvAppConfigApplication, ""),
+				Environment:     env.String(EnvAppConfigEnvironment, ""),
+				Profile:         env.String(EnvAppConfigProfile, ""),
+				RefreshInterval: env.Duration(EnvFeatureFlagsRefreshInterval, featureflags.DefaultAppConfigRefreshInterval),
+			},
+		},
+		Auth: auth.Settings{
+			JWKSFile:    env.String(EnvJWTJWKSFile, ""),
+			JWKSURL:     env.String(EnvJWTJWKSURL, ""),
+			HS256Secret: env.String(EnvJWTHS256Secret, ""),
+			Issuer:      env.String(EnvJWTIssuer, ""),
+			Audience:    env.String(EnvJWTAudience, ""),
+		},
+	}
+
+	appConfig := config.Flags.AppConfig
+	if appConfig.Application != "" || appConfig.Environment != "" || appConfig.Profile != "" {
+		env.Require(EnvAppConfigApplication, EnvAppConfigEnvironment, EnvAppConfigProfile)
+		if config.Flags.File != "" {
+			env.Invalid(EnvFeatureFlagsFile, "must not be set together with %s", EnvAppConfigApplication)
+		}
+	}
+
+	if value := env.String(EnvLogLevel, ""); value != "" {
+		level, err := services.ParseLevel(value)
+		if err != nil {
+			env.Invalid(EnvLogLevel, "must be trace, debug, info, warn or error, got %q", value)
+		}
+		config.Log.Level = level
+	}
+	if value := env.String(EnvLogFormat, ""); value != "" {
+		format, err := logger.ParseFormat(value)
+		if err != nil {
+			env.Invalid(EnvLogFormat, "must be json or console, got %q", value)
+		}
+		config.Log.Format = format
+	}
+
+	config.validate(env)
+
+	return config, env.Err()
+}
+
+// validate records the problems parsing cannot catch.
+func (c Config) validate(env *Env) {
+	if c.Hello.MaxNameLength <= 0 {
+		env.Invalid(EnvHelloMaxNameLength, "must be positive, got %d", c.Hello.MaxNameLength)
+	} else if _, err := c.Rules().SayHello(c.Hello.DefaultName); err != nil {
+		env.Invalid(EnvHelloDefaultName, "must be a valid name: %v", err)
+	}
+	if c.Hello.MaxNames < 0 {
+		env.Invalid(EnvHelloMaxNames, "must not be negative, got %d", c.Hello.MaxNames)
+	}
+	if c.Hello.MaxBatchSize <= 0 {
+		env.Invalid(EnvHelloMaxBatchSize, "must be positive, got %d", c.Hello.MaxBatchSize)
+	}
+	if c.Flags.AppConfig.RefreshInterval <= 0 {
+		env.Invalid(EnvFeatureFlagsRefreshInterval, "must be positive, got %s", c.Flags.AppConfig.RefreshInterval)
+	}
+	if c.HTTP.RequestTimeout <= 0 {
+		env.Invalid(EnvRequestTimeout, "must be positive, got %s", c.HTTP.RequestTimeout)
+	}
+	if c.HTTP.TimeoutSafetyMargin <= 0 {
+		env.Invalid(EnvTimeoutSafetyMargin, "must be positive, got %s", c.HTTP.TimeoutSafetyMargin)
+	} else if c.HTTP.RequestTimeout > 0 && c.HTTP.TimeoutSafetyMargin >= c.HTTP.RequestTimeout {
+		env.Invalid(EnvTimeoutSafetyMargin, "must be shorter than %s (%s), got %s",
+			EnvRequestTimeout, c.HTTP.RequestTimeout, c.HTTP.TimeoutSafetyMargin)
+	}
+	if c.HTTP.HealthCheckTimeout <= 0 {
+		env.Invalid(EnvHealthCheckTimeout, "must be positive, got %s", c.HTTP.HealthCheckTimeout)
+	}
+	if c.HTTP.RateLimit.Requests <= 0 {
+		env.Invalid(EnvRateLimitRequests, "must be positive, got %d", c.HTTP.RateLimit.Requests)
+	}
+	if c.HTTP.RateLimit.Per <= 0 {
+		env.Invalid(EnvRateLimitPer, "must be positive, got %s", c.HTTP.RateLimit.Per)
+	}
+	if c.HTTP.RateLimit.Burst < 0 {
+		env.Invalid(EnvRateLimitBurst, "must not be negative, got %d", c.HTTP.RateLimit.Burst)
+	}
+	if sources := c.Auth.KeySources(); len(sources) > 1 {
+		env.Invalid(sources[1], "must not be set together with %s", sources[0])
+	}
+}
+
+// Rules returns the greeting rules for the use case.
+func (c Config) Rules() hello.Rules {
+	return hello.Rules{DefaultName: c.Hello.DefaultName, MaxNameLength: c.Hello.MaxNameLength}
+}
+
+// Logger returns the logger configuration.
+func (c Config) Logger() logger.Config {
+	return logger.Config{Level: c.Log.Level, Format: c.Log.Format}
+}
+
+// HelloHandler returns the configuration of the hello handler.
+func (c Config) HelloHandler() handlers.HelloHandlerConfig {
+	return handlers.HelloHandlerConfig{MaxNames: c.Hello.MaxNames}
+}
+
+// Batch returns the configuration of the batch handler, without its use
+// case: the caller greets with the same hello.Greeter as the hello handler.
+func (c Config) Batch() handlers.BatchConfig {
+	return handlers.BatchConfig{MaxBatchSize: c.Hello.MaxBatchSize}
+}
+
+// Verifier returns the bearer token verifier of the API, or nil when
+// authentication is disabled. It fails when JWT_JWKS_FILE cannot be loaded.
+func (c Config) Verifier() (*auth.Verifier, error) {
+	return c.Auth.Verifier()
+}
+
+// RateLimit returns the per-client limit of the API.
+func (c Config) RateLimit() ratelimit.Limit {
+	return c.HTTP.RateLimit
+}
+
+// Timeout returns the configuration of the Timeout middleware.
+func (c Config) Timeout() handlers.TimeoutConfig {
+	return handlers.TimeoutConfig{SafetyMargin: c.HTTP.TimeoutSafetyMargin, Timeout: c.HTTP.RequestTimeout}
+}
+
+// Health returns the configuration of the health endpoint, without checkers.
+func (c Config) Health() handlers.HealthConfig {
+	return handlers.HealthConfig{Timeout: c.HTTP.HealthCheckTimeout}
+}
+
+// CORS returns the configuration of the CORS middleware: the defaults with
+// the configured origins.
+func (c Config) CORS() handlers.CORSConfig {
+	cors := handlers.DefaultCORSConfig()
+	cors.AllowedOrigins = c.HTTP.AllowedOrigins
+
+	return cors
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every problem found while loading the configuration,
+// so a single cold start reports all of them.
+type ValidationError struct {
+	Problems []error
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.Error()
+	}
+
+	return "invalid configuration: " + strings.Join(problems, "; ")
+}
+
+// Unwrap returns the individual problems, for errors.Is and errors.As.
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// ErrMissing is wrapped by the problem reported for a required variable that
+// is unset or blank.
+var ErrMissing = errors.New("is required")
+
+// Env reads typed settings from environment variables through getenv
+// (usually os.Getenv). Unset or blank variables take the given default;
+// malformed ones take it too and record a problem. Err reports every problem
+// at once.
+//
+// Example:
+//
+//	env := config.NewEnv(os.Getenv)
+//	env.Require("TABLE_NAME")
+//	table := env.String("TABLE_NAME", "")
+//	timeout := env.Duration("REQUEST_TIMEOUT", 29*time.Second)
+//	origins := env.List("CORS_ALLOWED_ORIGINS", []string{"*"})
+//	if err := env.Err(); err != nil {
+//	    panic(err)
+//	}
+type Env struct {
+	getenv   func(string) string
+	problems []error
+}
+
+// NewEnv returns an Env reading variables with getenv.
+func NewEnv(getenv func(string) string) *Env {
+	return &Env{getenv: getenv}
+}
+
+// Require records a problem for every key that is unset or blank.
+func (e *Env) Require(keys ...string) {
+	for _, key := range keys {
+		if _, ok := e.lookup(key); !ok {
+			e.problems = append(e.problems, fmt.Errorf("%s %w", key, ErrMissing))
+		}
+	}
+}
+
+// String returns the trimmed value of key, or def.
+func (e *Env) String(key, def string) string {
+	if value, ok := e.lookup(key); ok {
+		return value
+	}
+
+	return def
+}
+
+// Int returns the value of key as an int, or def.
+func (e *Env) Int(key string, def int) int {
+	value, ok := e.lookup(key)
+	if !ok {
+		return def
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		e.Invalid(key, "must be an integer, got %q", value)
+		return def
+	}
+
+	return parsed
+}
+
+// Bool returns the value of key as a bool, or def. It accepts the values
+// strconv.ParseBool does: 1, t, true, 0, f, false and their upper case forms.
+func (e *Env) Bool(key string, def bool) bool {
+	value, ok := e.lookup(key)
+	if !ok {
+		return def
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		e.Invalid(key, "must be a boolean, got %q", value)
+		return def
+	}
+
+	return parsed
+}
+
+// Duration returns the value of key as a time.Duration such as "500ms" or
+// "1m30s", or def.
+func (e *Env) Duration(key string, def time.Duration) time.Duration {
+	value, ok := e.lookup(key)
+	if !ok {
+		return def
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		e.Invalid(key, "must be a duration such as 500ms or 30s, got %q", value)
+		return def
+	}
+
+	return parsed
+}
+
+// List returns the comma-separated values of key, trimmed and without blanks,
+// or def.
+func (e *Env) List(key string, def []string) []string {
+	value, ok := e.lookup(key)
+	if !ok {
+		return def
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return def
+	}
+
+	return list
+}
+
+// Invalid records a problem with key, for checks beyond parsing.
+func (e *Env) Invalid(key, format string, args ...interface{}) {
+	e.problems = append(e.problems, fmt.Errorf("%s %s", key, fmt.Sprintf(format, args...)))
+}
+
+// Err returns a *ValidationError listing every problem recorded so far, or nil.
+func (e *Env) Err() error {
+	if len(e.problems) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Problems: e.problems}
+}
+
+func (e *Env) lookup(key string) (string, bool) {
+	value := strings.TrimSpace(e.getenv(key))
+
+	return value, value != ""
+}
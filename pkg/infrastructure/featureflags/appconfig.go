@@ -0,0 +1,195 @@
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+)
+
+// Defaults of AppConfigConfig.
+const (
+	// DefaultAppConfigURL is the address of the AWS AppConfig Lambda extension.
+	DefaultAppConfigURL = "http://localhost:2772"
+	// DefaultAppConfigRefreshInterval matches the default poll interval of the
+	// extension, so each refresh can see a new deployment.
+	DefaultAppConfigRefreshInterval = 45 * time.Second
+	// DefaultAppConfigTimeout bounds a single fetch.
+	DefaultAppConfigTimeout = 2 * time.Second
+)
+
+// AppConfigConfig configures an AppConfigProvider.
+type AppConfigConfig struct {
+	// URL is the base URL of the AppConfig extension. Defaults to DefaultAppConfigURL.
+	URL string
+	// Application, Environment and Profile identify the configuration profile,
+	// by name or ID.
+	Application string
+	Environment string
+	Profile     string
+	// RefreshInterval is how long a fetched Document is used before it is
+	// fetched again. Defaults to DefaultAppConfigRefreshInterval.
+	RefreshInterval time.Duration
+	// Client performs the requests. Defaults to a client with a
+	// DefaultAppConfigTimeout timeout.
+	Client *http.Client
+	// Logger reports failed fetches. Optional.
+	Logger services.Logger
+	// Now returns the current time. Defaults to time.Now; tests can override it.
+	Now func() time.Time
+}
+
+// AppConfigProvider reads the flag Document from AWS AppConfig through the
+// AppConfig Lambda extension, which polls AppConfig and serves the latest
+// deployed configuration over local HTTP:
+//
+//	GET {URL}/applications/{Application}/environments/{Environment}/configurations/{Profile}
+//
+// The profile must be a freeform configuration holding a Document in JSON
+// (application/json) or YAML (application/x-yaml). The Document is cached
+// for RefreshInterval. When a fetch fails, the last Document keeps being used
+// and the failure is logged; before the first successful fetch every flag
+// evaluates to its default. Safe for concurrent use.
+//
+// Example:
+//
+//	flags := featureflags.NewAppConfigProvider(featureflags.AppConfigConfig{
+//	    Application: "hello-api",
+//	    Environment: "prod",
+//	    Profile:     "feature-flags",
+//	})
+type AppConfigProvider struct {
+	config   AppConfigConfig
+	mu       sync.Mutex
+	document *Document
+	expires  time.Time
+}
+
+// NewAppConfigProvider returns an AppConfigProvider. Nothing is fetched until
+// the first evaluation.
+func NewAppConfigProvider(config AppConfigConfig) *AppConfigProvider {
+	if config.URL == "" {
+		config.URL = DefaultAppConfigURL
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultAppConfigRefreshInterval
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: DefaultAppConfigTimeout}
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return &AppConfigProvider{config: config}
+}
+
+// Bool implements services.FeatureFlags.
+func (p *AppConfigProvider) Bool(ctx context.Context, flag string, target entities.FlagTarget, def bool) bool {
+	return p.current(ctx).Bool(ctx, flag, target, def)
+}
+
+// Variant implements services.FeatureFlags.
+func (p *AppConfigProvider) Variant(ctx context.Context, flag string, target entities.FlagTarget, def string) string {
+	return p.current(ctx).Variant(ctx, flag, target, def)
+}
+
+// Refresh fetches the Document now.
+func (p *AppConfigProvider) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.refresh(ctx)
+}
+
+// Name implements services.HealthChecker.
+func (p *AppConfigProvider) Name() string {
+	return "feature_flags"
+}
+
+// Timeout implements services.HealthChecker, using the default timeout.
+func (p *AppConfigProvider) Timeout() time.Duration {
+	return 0
+}
+
+// Check implements services.HealthChecker: it fails when the AppConfig
+// extension cannot serve a valid Document. The cached Document is left
+// untouched.
+func (p *AppConfigProvider) Check(ctx context.Context) error {
+	_, err := p.fetch(ctx)
+
+	return err
+}
+
+// current returns the cached Document, refreshing it when it expired.
+func (p *AppConfigProvider) current(ctx context.Context) *Document {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.config.Now().Before(p.expires) {
+		if err := p.refresh(ctx); err != nil && p.config.Logger != nil {
+			p.config.Logger.Log(ctx, services.LevelWarn, "Feature flags refresh failed",
+				services.Field{Key: "error", Value: err.Error()},
+			)
+		}
+	}
+
+	return p.document
+}
+
+// refresh fetches the Document. Failed attempts also wait for the refresh
+// interval, so an unavailable extension is not called on every evaluation.
+// The caller holds p.mu.
+func (p *AppConfigProvider) refresh(ctx context.Context) error {
+	p.expires = p.config.Now().Add(p.config.RefreshInterval)
+
+	document, err := p.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	p.document = document
+
+	return nil
+}
+
+func (p *AppConfigProvider) fetch(ctx context.Context) (*Document, error) {
+	endpoint := strings.TrimRight(p.config.URL, "/") +
+		"/applications/" + url.PathEscape(p.config.Application) +
+		"/environments/" + url.PathEscape(p.config.Environment) +
+		"/configurations/" + url.PathEscape(p.config.Profile)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("feature flags: %w", err)
+	}
+
+	response, err := p.config.Client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("feature flags: fetch AppConfig: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feature flags: fetch AppConfig: unexpected status %d", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("feature flags: fetch AppConfig: %w", err)
+	}
+
+	format := FormatJSON
+	if mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type")); strings.Contains(mediaType, "yaml") {
+		format = FormatYAML
+	}
+
+	return Parse(body, format)
+}
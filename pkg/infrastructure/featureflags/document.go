@@ -0,0 +1,339 @@
+package featureflags
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+)
+
+// Variants served by boolean flags.
+const (
+	VariantOn  = "on"
+	VariantOff = "off"
+)
+
+// Rule operators.
+const (
+	OperatorIn         = "in"
+	OperatorNotIn      = "not_in"
+	OperatorStartsWith = "starts_with"
+	OperatorEndsWith   = "ends_with"
+)
+
+// Format is the encoding of a Document.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// buckets is the rollout resolution: percentages are honoured to 0.01%.
+const buckets = 10000
+
+// Document is a set of flag definitions, as read from a file or from AWS
+// AppConfig. It implements services.FeatureFlags; the nil Document has no
+// flags, so every evaluation returns the default.
+//
+// Example (YAML):
+//
+//	flags:
+//	  greeting-emoji:          # boolean flag on for 25% of targets,
+//	    enabled: true          # and always on for user "ana"
+//	    rollout: 25
+//	    rules:
+//	      - attribute: user_id
+//	        operator: in
+//	        values: [ana]
+//	        serve: "on"
+//	  greeting-salutation:     # variant flag split 80/20
+//	    enabled: true
+//	    variants:
+//	      - {name: Hello, weight: 80}
+//	      - {name: Hi, weight: 20}
+type Document struct {
+	Flags map[string]Flag `json:"flags" yaml:"flags"`
+}
+
+// Flag defines one flag. A flag with Variants is a variant flag; otherwise it
+// is a boolean flag serving VariantOn or VariantOff.
+type Flag struct {
+	// Enabled switches the flag on. A disabled flag serves VariantOff, or the
+	// caller's default for variant flags.
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// Rollout is the percentage of targets a boolean flag is on for, from 0 to
+	// 100. Omitted means 100.
+	Rollout *float64 `json:"rollout,omitempty" yaml:"rollout,omitempty"`
+	// Variants splits targets between the variants of a variant flag, in
+	// proportion to their weights.
+	Variants []Variant `json:"variants,omitempty" yaml:"variants,omitempty"`
+	// Rules are checked in order before the rollout; the first match decides.
+	Rules []Rule `json:"rules,omitempty" yaml:"rules,omitempty"`
+}
+
+// Variant is one value of a variant flag.
+type Variant struct {
+	Name   string  `json:"name" yaml:"name"`
+	Weight float64 `json:"weight" yaml:"weight"`
+}
+
+// Rule serves a fixed variant to the targets whose Attribute matches Values
+// under Operator. Targets without the attribute never match.
+type Rule struct {
+	Attribute string   `json:"attribute" yaml:"attribute"`
+	Operator  string   `json:"operator" yaml:"operator"`
+	Values    []string `json:"values" yaml:"values"`
+	// Serve is the variant served on a match: "on" or "off" for boolean flags.
+	Serve string `json:"serve" yaml:"serve"`
+}
+
+// Parse decodes and validates a Document. Unknown fields are rejected so
+// typos fail loudly instead of silently disabling a rule.
+func Parse(data []byte, format Format) (*Document, error) {
+	document := &Document{}
+
+	var err error
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(document)
+	case FormatYAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(document)
+	default:
+		return nil, fmt.Errorf("feature flags: unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("feature flags: decode %s: %w", format, err)
+	}
+
+	if err := document.validate(); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// LoadFile reads a Document from path, in JSON or YAML according to its
+// extension (.json, .yaml or .yml).
+func LoadFile(path string) (*Document, error) {
+	var format Format
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = FormatJSON
+	case ".yaml", ".yml":
+		format = FormatYAML
+	default:
+		return nil, fmt.Errorf("feature flags: %s: extension must be .json, .yaml or .yml", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("feature flags: %w", err)
+	}
+
+	return Parse(data, format)
+}
+
+// Bool implements services.FeatureFlags.
+func (d *Document) Bool(_ context.Context, flag string, target entities.FlagTarget, def bool) bool {
+	switch variant, _ := d.evaluate(flag, target); variant {
+	case VariantOn:
+		return true
+	case VariantOff:
+		return false
+	default:
+		return def
+	}
+}
+
+// Variant implements services.FeatureFlags.
+func (d *Document) Variant(_ context.Context, flag string, target entities.FlagTarget, def string) string {
+	if variant, ok := d.evaluate(flag, target); ok && variant != "" {
+		return variant
+	}
+
+	return def
+}
+
+// evaluate returns the variant of flag served to target, and false when the
+// flag is unknown. Disabled variant flags serve "".
+func (d *Document) evaluate(name string, target entities.FlagTarget) (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	flag, ok := d.Flags[name]
+	if !ok {
+		return "", false
+	}
+
+	boolean := len(flag.Variants) == 0
+	if !flag.Enabled {
+		if boolean {
+			return VariantOff, true
+		}
+		return "", true
+	}
+
+	for _, rule := range flag.Rules {
+		if rule.matches(target) {
+			return rule.Serve, true
+		}
+	}
+
+	position := float64(bucket(name, target.Key)) / buckets
+	if boolean {
+		if flag.Rollout == nil || position*100 < *flag.Rollout {
+			return VariantOn, true
+		}
+		return VariantOff, true
+	}
+
+	total := 0.0
+	for _, variant := range flag.Variants {
+		total += variant.Weight
+	}
+	cumulative := 0.0
+	for _, variant := range flag.Variants {
+		cumulative += variant.Weight
+		if position*total < cumulative {
+			return variant.Name, true
+		}
+	}
+
+	return flag.Variants[len(flag.Variants)-1].Name, true
+}
+
+func (r Rule) matches(target entities.FlagTarget) bool {
+	value, ok := target.Attributes[r.Attribute]
+	if !ok {
+		return false
+	}
+
+	switch r.Operator {
+	case OperatorIn, OperatorNotIn:
+		found := false
+		for _, candidate := range r.Values {
+			if value == candidate {
+				found = true
+				break
+			}
+		}
+		return found == (r.Operator == OperatorIn)
+	case OperatorStartsWith:
+		for _, candidate := range r.Values {
+			if strings.HasPrefix(value, candidate) {
+				return true
+			}
+		}
+	case OperatorEndsWith:
+		for _, candidate := range r.Values {
+			if strings.HasSuffix(value, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// bucket places key in one of the rollout buckets. Hashing the flag name with
+// the key keeps a target in the same bucket across invocations while giving
+// each flag an independent split.
+func bucket(flag, key string) uint64 {
+	sum := sha256.Sum256([]byte(flag + "\x00" + key))
+
+	return binary.BigEndian.Uint64(sum[:8]) % buckets
+}
+
+// validate checks every flag and normalises boolean rule variants, reporting
+// all problems at once.
+func (d *Document) validate() error {
+	names := make([]string, 0, len(d.Flags))
+	for name := range d.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []error
+	for _, name := range names {
+		flag := d.Flags[name]
+		invalid := func(format string, args ...interface{}) {
+			problems = append(problems, fmt.Errorf("feature flag %q: %s", name, fmt.Sprintf(format, args...)))
+		}
+
+		if strings.TrimSpace(name) == "" {
+			invalid("name must not be blank")
+		}
+
+		variants := map[string]bool{VariantOn: true, VariantOff: true}
+		if len(flag.Variants) > 0 {
+			if flag.Rollout != nil {
+				invalid("rollout applies to boolean flags; weight the variants instead")
+			}
+
+			variants = map[string]bool{}
+			total := 0.0
+			for _, variant := range flag.Variants {
+				switch {
+				case variant.Name == "":
+					invalid("variant name must not be blank")
+				case variants[variant.Name]:
+					invalid("duplicate variant %q", variant.Name)
+				case variant.Weight < 0:
+					invalid("variant %q weight must not be negative", variant.Name)
+				}
+				variants[variant.Name] = true
+				total += variant.Weight
+			}
+			if total <= 0 {
+				invalid("variant weights must add up to more than 0")
+			}
+		} else if flag.Rollout != nil && (*flag.Rollout < 0 || *flag.Rollout > 100) {
+			invalid("rollout must be between 0 and 100, got %v", *flag.Rollout)
+		}
+
+		for i, rule := range flag.Rules {
+			if len(flag.Variants) == 0 {
+				switch strings.ToLower(rule.Serve) {
+				case VariantOn, "true":
+					rule.Serve = VariantOn
+				case VariantOff, "false":
+					rule.Serve = VariantOff
+				}
+			}
+
+			switch {
+			case rule.Attribute == "":
+				invalid("rule %d: attribute must not be blank", i)
+			case len(rule.Values) == 0:
+				invalid("rule %d: values must not be empty", i)
+			case !variants[rule.Serve]:
+				invalid("rule %d: unknown variant %q", i, rule.Serve)
+			}
+			switch rule.Operator {
+			case OperatorIn, OperatorNotIn, OperatorStartsWith, OperatorEndsWith:
+			default:
+				invalid("rule %d: unknown operator %q", i, rule.Operator)
+			}
+
+			flag.Rules[i] = rule
+		}
+	}
+
+	return errors.Join(problems...)
+}
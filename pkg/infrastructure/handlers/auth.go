@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+)
+
+// JWTAuthConfig configures the JWTAuth middleware.
+type JWTAuthConfig struct {
+	// Verifier checks bearer tokens.
+	Verifier *auth.Verifier
+	// Logger reports rejected tokens. Optional.
+	Logger services.Logger
+	// Realm is advertised in WWW-Authenticate challenges. Defaults to "api".
+	Realm string
+	// SkipPaths are served without a token, such as a health check polled
+	// by monitors. Trailing and repeated slashes are ignored, as by the router.
+	SkipPaths []string
+}
+
+// JWTAuth requires a valid "Authorization: Bearer <token>" header. Requests
+// without a token or with a token that fails verification get a 401 with a
+// WWW-Authenticate challenge; verified requests continue with an
+// entities.Principal in the context, so handlers, use cases and the logger can
+// read the subject and claims:
+//
+//	principal, _ := entities.PrincipalFromContext(ctx)
+//	principal.Subject          // "user-42"
+//	principal.Claims["scope"]  // "greetings:read"
+//
+// CORS preflight requests and requests to SkipPaths are passed through
+// unauthenticated, since browsers never send credentials with preflights.
+func JWTAuth(config JWTAuthConfig) Middleware {
+	if config.Realm == "" {
+		config.Realm = "api"
+	}
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[cleanPath(path)] = true
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			if request.HTTPMethod == http.MethodOptions || skip[cleanPath(request.Path)] {
+				return next(ctx, request)
+			}
+
+			token, ok := bearerToken(HeaderValue(request, "Authorization"))
+			if !ok {
+				response, err := CodedErrorResponse(http.StatusUnauthorized, CodeMissingToken, "Missing bearer token")
+				setHeader(&response, "WWW-Authenticate", `Bearer realm="`+config.Realm+`"`)
+
+				return response, err
+			}
+
+			claims, err := config.Verifier.Verify(ctx, token)
+			if err != nil {
+				if config.Logger != nil {
+					config.Logger.Log(ctx, services.LevelWarn, "Authentication failed",
+						services.Field{Key: "error", Value: err.Error()},
+						services.Field{Key: "path", Value: request.Path},
+					)
+				}
+
+				response, respErr := CodedErrorResponse(http.StatusUnauthorized, CodeInvalidToken, "Invalid bearer token")
+				setHeader(&response, "WWW-Authenticate", `Bearer realm="`+config.Realm+`", error="invalid_token"`)
+
+				return response, respErr
+			}
+
+			ctx = entities.ContextWithPrincipal(ctx, entities.Principal{Subject: claims.Subject(), Claims: claims})
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// cleanPath drops empty segments from path, so "/health", "/health/" and
+// "//health" compare equal.
+func cleanPath(path string) string {
+	return "/" + strings.Join(strings.FieldsFunc(path, func(r rune) bool { return r == '/' }), "/")
+}
+
+// bearerToken extracts the token of a "Bearer" Authorization header.
+func bearerToken(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
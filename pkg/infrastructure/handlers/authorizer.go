@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+)
+
+// ErrUnauthorized is returned by the authorizers for a missing or invalid
+// token. API Gateway only answers 401 when an authorizer fails with exactly
+// this message; any other error becomes a 500.
+var ErrUnauthorized = errors.New("Unauthorized")
+
+// Policy effects of an authorizer response.
+const (
+	PolicyEffectAllow = "Allow"
+	PolicyEffectDeny  = "Deny"
+)
+
+// AuthorizerConfig configures TokenAuthorizer and RequestAuthorizer.
+type AuthorizerConfig struct {
+	// Verifier checks bearer tokens.
+	Verifier *auth.Verifier
+	// Authorize decides whether a verified principal may call the API. It must
+	// only depend on the principal, not on the method being called, because
+	// API Gateway caches the policy per token and reuses it for every method.
+	// When nil, every verified principal is allowed.
+	Authorize func(ctx context.Context, principal entities.Principal) bool
+	// Logger reports decisions. Optional.
+	Logger services.Logger
+}
+
+// TokenAuthorizer returns an API Gateway TOKEN authorizer. The identity source
+// (usually the Authorization header) may hold "Bearer <token>" or the bare token.
+//
+// Example:
+//
+//	lambda.Start(handlers.TokenAuthorizer(handlers.AuthorizerConfig{Verifier: verifier}))
+func TokenAuthorizer(config AuthorizerConfig) func(context.Context, events.APIGatewayCustomAuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayCustomAuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+		token := strings.TrimSpace(request.AuthorizationToken)
+		if bearer, ok := bearerToken(token); ok {
+			token = bearer
+		}
+
+		return config.authorize(ctx, token, token != "" && !strings.Contains(token, " "), request.MethodArn)
+	}
+}
+
+// RequestAuthorizer returns an API Gateway REQUEST authorizer reading the bearer
+// token from the Authorization header.
+//
+// Example:
+//
+//	lambda.Start(handlers.RequestAuthorizer(handlers.AuthorizerConfig{Verifier: verifier}))
+func RequestAuthorizer(config AuthorizerConfig) func(context.Context, events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+	return func(ctx context.Context, request events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+		token, ok := bearerToken(lookupHeader(request.Headers, request.MultiValueHeaders, "Authorization"))
+
+		return config.authorize(ctx, token, ok, request.MethodArn)
+	}
+}
+
+// authorize verifies token and builds the policy. Allowed and denied
+// principals get a policy over the whole stage so the cached result is valid
+// for every method; the claims are passed to the integration as the context
+// map, available as requestContext.authorizer.<claim>.
+func (config AuthorizerConfig) authorize(ctx context.Context, token string, ok bool, methodArn string) (events.APIGatewayCustomAuthorizerResponse, error) {
+	if !ok {
+		config.log(ctx, services.LevelWarn, "Authorization failed",
+			services.Field{Key: "error", Value: "missing bearer token"},
+			services.Field{Key: "method_arn", Value: methodArn},
+		)
+
+		return events.APIGatewayCustomAuthorizerResponse{}, ErrUnauthorized
+	}
+
+	claims, err := config.Verifier.Verify(ctx, token)
+	if err != nil {
+		config.log(ctx, services.LevelWarn, "Authorization failed",
+			services.Field{Key: "error", Value: err.Error()},
+			services.Field{Key: "method_arn", Value: methodArn},
+		)
+
+		return events.APIGatewayCustomAuthorizerResponse{}, ErrUnauthorized
+	}
+
+	principal := entities.Principal{Subject: claims.Subject(), Claims: claims}
+	ctx = entities.ContextWithPrincipal(ctx, principal)
+	resource := policyResource(methodArn)
+
+	effect := PolicyEffectAllow
+	if config.Authorize != nil && !config.Authorize(ctx, principal) {
+		effect = PolicyEffectDeny
+	}
+
+	level, msg := services.LevelInfo, "Authorization granted"
+	if effect == PolicyEffectDeny {
+		level, msg = services.LevelWarn, "Authorization denied"
+	}
+	config.log(ctx, level, msg, services.Field{Key: "resource", Value: resource})
+
+	return events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID:    principal.Subject,
+		PolicyDocument: PolicyDocument(effect, resource),
+		Context:        AuthorizerContext(claims),
+	}, nil
+}
+
+func (config AuthorizerConfig) log(ctx context.Context, level services.Level, msg string, fields ...services.Field) {
+	if config.Logger != nil {
+		config.Logger.Log(ctx, level, msg, fields...)
+	}
+}
+
+// PolicyDocument returns an IAM policy granting or denying execute-api:Invoke
+// on resources.
+func PolicyDocument(effect string, resources ...string) events.APIGatewayCustomAuthorizerPolicy {
+	return events.APIGatewayCustomAuthorizerPolicy{
+		Version: "2012-10-17",
+		Statement: []events.IAMPolicyStatement{
+			{
+				Action:   []string{"execute-api:Invoke"},
+				Effect:   effect,
+				Resource: resources,
+			},
+		},
+	}
+}
+
+// policyResource widens a method ARN to every method and path of its stage:
+//
+//	arn:aws:execute-api:us-east-1:123456789012:abc123/prod/GET/hello
+//	-> arn:aws:execute-api:us-east-1:123456789012:abc123/prod/*/*
+func policyResource(methodArn string) string {
+	parts := strings.SplitN(methodArn, "/", 3)
+	if len(parts) < 2 {
+		return methodArn
+	}
+
+	return parts[0] + "/" + parts[1] + "/*/*"
+}
+
+// AuthorizerContext flattens claims into an authorizer context map, which API
+// Gateway only accepts with string, number and boolean values: lists of
+// strings are joined with spaces and other values are JSON encoded.
+func AuthorizerContext(claims auth.Claims) map[string]interface{} {
+	values := make(map[string]interface{}, len(claims))
+	for key, value := range claims {
+		switch v := value.(type) {
+		case string, bool:
+			values[key] = v
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				values[key] = n
+			} else if f, err := v.Float64(); err == nil {
+				values[key] = f
+			}
+		default:
+			if list, ok := v.([]interface{}); ok {
+				if joined, ok := joinStrings(list); ok {
+					values[key] = joined
+					continue
+				}
+			}
+			if data, err := json.Marshal(v); err == nil {
+				values[key] = string(data)
+			}
+		}
+	}
+
+	return values
+}
+
+func joinStrings(values []interface{}) (string, bool) {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return "", false
+		}
+		parts[i] = s
+	}
+
+	return strings.Join(parts, " "), true
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// MediaTypeForm is the media type of HTML form submissions.
+const MediaTypeForm = "application/x-www-form-urlencoded"
+
+// Request body errors, mapped to 415 and 400 responses by bodyErrorResponse.
+var (
+	// ErrUnsupportedMediaType indicates a request body in a media type the endpoint cannot read.
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+	// ErrMalformedBody indicates a request body that cannot be decoded in its declared media type.
+	ErrMalformedBody = errors.New("malformed request body")
+)
+
+// helloRequest is the body accepted by POST /hello. Names go through the same
+// rules as the query parameters of GET /hello; a form may repeat "name" to
+// greet several people at once.
+//
+// Example bodies:
+//
+//	application/json                  -> {"name":"John"}
+//	application/x-www-form-urlencoded -> name=John or name=Ana&name=Luis
+type helloRequest struct {
+	Name  string   `json:"name"`
+	Names []string `json:"-"`
+}
+
+// decodeHelloRequest reads a helloRequest from the request body according to
+// its Content-Type, filling Names with every name provided. An empty body
+// decodes to the zero value, which greets the default name.
+func decodeHelloRequest(request events.APIGatewayProxyRequest) (helloRequest, error) {
+	var dto helloRequest
+
+	mediaType, body, err := readBody(request)
+	if err != nil || len(body) == 0 {
+		return dto, err
+	}
+
+	switch mediaType {
+	case MediaTypeJSON:
+		err = decodeJSON(body, &dto)
+		dto.Names = []string{dto.Name}
+	case MediaTypeForm:
+		var values url.Values
+		values, err = parseForm(body)
+		dto.Name = values.Get("name")
+		dto.Names = values["name"]
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnsupportedMediaType, mediaType)
+	}
+
+	return dto, err
+}
+
+// readBody returns the media type and the decoded body of request. The media
+// type is empty when the body is blank, so callers can treat a missing body as
+// their zero value without requiring a Content-Type.
+func readBody(request events.APIGatewayProxyRequest) (string, []byte, error) {
+	body, err := requestBody(request)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return "", nil, nil
+	}
+
+	contentType := HeaderValue(request, "Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, contentType)
+	}
+
+	return mediaType, body, nil
+}
+
+// decodeJSON decodes a single JSON value from body into v.
+func decodeJSON(body []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %s", ErrMalformedBody, jsonErrorMessage(err))
+	}
+	if decoder.More() {
+		return fmt.Errorf("%w: unexpected data after JSON value", ErrMalformedBody)
+	}
+
+	return nil
+}
+
+// parseForm parses an application/x-www-form-urlencoded body.
+func parseForm(body []byte) (url.Values, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedBody, err.Error())
+	}
+
+	return values, nil
+}
+
+// requestBody returns the raw request body, decoding it when API Gateway
+// delivered it base64-encoded.
+func requestBody(request events.APIGatewayProxyRequest) ([]byte, error) {
+	if !request.IsBase64Encoded {
+		return []byte(request.Body), nil
+	}
+
+	body, err := base64.StdEncoding.DecodeString(request.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64 encoding", ErrMalformedBody)
+	}
+
+	return body, nil
+}
+
+// jsonErrorMessage turns encoding/json errors into messages that point at the
+// offending position or field instead of Go type names.
+func jsonErrorMessage(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("invalid JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Sprintf("field %q must be a %s", typeErr.Field, jsonTypeName(typeErr.Type.Kind().String()))
+	case errors.As(err, &typeErr):
+		return "body must be a JSON object"
+	default:
+		return "invalid JSON"
+	}
+}
+
+func jsonTypeName(kind string) string {
+	if kind == "struct" || kind == "map" {
+		return "object"
+	}
+
+	return kind
+}
+
+// bodyErrorResponse maps request body errors to 415 and 400 responses.
+func bodyErrorResponse(err error) (events.APIGatewayProxyResponse, error) {
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		return CodedErrorResponse(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType,
+			err.Error()+". Supported media types: "+strings.Join([]string{MediaTypeJSON, MediaTypeForm}, ", "))
+	}
+
+	return CodedErrorResponse(http.StatusBadRequest, CodeMalformedBody, err.Error())
+}
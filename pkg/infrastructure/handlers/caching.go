@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// CacheConfig configures the ConditionalGET middleware for a route.
+type CacheConfig struct {
+	// MaxAge is how long clients may reuse a response without revalidating.
+	MaxAge time.Duration
+	// SharedMaxAge overrides MaxAge for shared caches such as CDNs (s-maxage).
+	// Zero omits the directive.
+	SharedMaxAge time.Duration
+	// Private restricts caching to the client, e.g. for per-user responses.
+	Private bool
+	// Vary lists the request headers the response depends on, besides those
+	// the handler already declares, e.g. "Authorization" for per-user responses.
+	Vary []string
+}
+
+// cacheControl renders the Cache-Control header value for config.
+func (config CacheConfig) cacheControl() string {
+	directives := []string{"public"}
+	if config.Private {
+		directives[0] = "private"
+	}
+
+	directives = append(directives, "max-age="+strconv.Itoa(int(config.MaxAge.Seconds())))
+	if config.SharedMaxAge > 0 && !config.Private {
+		directives = append(directives, "s-maxage="+strconv.Itoa(int(config.SharedMaxAge.Seconds())))
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// ConditionalGET adds a strong ETag and a Cache-Control header to successful
+// GET and HEAD responses, and answers requests whose If-None-Match matches the
+// ETag with 304 Not Modified and no body. It is applied per route:
+//
+//	r.Handle(http.MethodGet, "/hello", handlers.Chain(helloHandler.Handle,
+//	    handlers.ConditionalGET(handlers.CacheConfig{MaxAge: 5 * time.Minute})))
+//
+// The ETag is a hash of the Content-Type and body, so each negotiated
+// representation gets its own tag. A Cache-Control header set by the handler is
+// kept as is.
+func ConditionalGET(config CacheConfig) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			response, err := next(ctx, request)
+			if err != nil || response.StatusCode != http.StatusOK ||
+				(request.HTTPMethod != http.MethodGet && request.HTTPMethod != http.MethodHead) {
+				return response, err
+			}
+
+			etag := lookupHeader(response.Headers, response.MultiValueHeaders, "ETag")
+			if etag == "" {
+				etag = computeETag(response)
+				setHeader(&response, "ETag", etag)
+			}
+			if lookupHeader(response.Headers, response.MultiValueHeaders, "Cache-Control") == "" {
+				setHeader(&response, "Cache-Control", config.cacheControl())
+			}
+			if len(config.Vary) > 0 {
+				addVary(&response, config.Vary...)
+			}
+
+			if !etagMatches(HeaderValue(request, "If-None-Match"), etag) {
+				return response, nil
+			}
+
+			notModified := events.APIGatewayProxyResponse{StatusCode: http.StatusNotModified}
+			for _, name := range []string{"ETag", "Cache-Control", "Vary"} {
+				if value := response.Headers[name]; value != "" {
+					setHeader(&notModified, name, value)
+				}
+			}
+
+			return notModified, nil
+		}
+	}
+}
+
+// computeETag returns a strong ETag for the representation in response.
+func computeETag(response events.APIGatewayProxyResponse) string {
+	hash := sha256.New()
+	hash.Write([]byte(lookupHeader(response.Headers, response.MultiValueHeaders, "Content-Type")))
+	hash.Write([]byte{0})
+	hash.Write([]byte(response.Body))
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches applies the weak comparison RFC 9110 requires for If-None-Match.
+// Tags suffixed with a content coding by Compress match their unencoded form,
+// so a client revalidating a compressed response still gets a 304.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag || stripEncodingSuffix(candidate) == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encodedETag derives the ETag of a representation compressed with encoding,
+// since a strong validator must differ between content codings.
+func encodedETag(etag, encoding string) string {
+	if !strings.HasSuffix(etag, `"`) || len(etag) < 2 {
+		return etag
+	}
+
+	return etag[:len(etag)-1] + "-" + encoding + `"`
+}
+
+func stripEncodingSuffix(etag string) string {
+	for _, encoding := range []string{EncodingBrotli, EncodingGzip, EncodingDeflate} {
+		if suffix := "-" + encoding + `"`; strings.HasSuffix(etag, suffix) {
+			return strings.TrimSuffix(etag, suffix) + `"`
+		}
+	}
+
+	return etag
+}
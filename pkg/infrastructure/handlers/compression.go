@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Content codings supported by the Compress middleware, in order of preference.
+const (
+	EncodingBrotli  = "br"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// errUnknownEncoding is returned by encodeBody for codings it cannot produce.
+var errUnknownEncoding = errors.New("unknown content coding")
+
+// DefaultCompressionMinSize is the smallest body, in bytes, that is compressed
+// when CompressionConfig.MinSize is not set. Below it the encoding overhead
+// outweighs the savings.
+const DefaultCompressionMinSize = 1024
+
+// CompressionConfig configures the Compress middleware.
+type CompressionConfig struct {
+	// MinSize is the smallest body, in bytes, worth compressing. Zero means
+	// DefaultCompressionMinSize.
+	MinSize int
+	// Encodings lists the content codings offered to clients, in order of
+	// preference. When empty, br, gzip and deflate are offered.
+	Encodings []string
+}
+
+// DefaultCompressionConfig returns a configuration offering brotli, gzip and
+// deflate for bodies of at least DefaultCompressionMinSize bytes.
+func DefaultCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		MinSize:   DefaultCompressionMinSize,
+		Encodings: []string{EncodingBrotli, EncodingGzip, EncodingDeflate},
+	}
+}
+
+// Compress encodes textual response bodies with the content coding negotiated
+// from the Accept-Encoding header. Compressed bodies are returned base64-encoded
+// with IsBase64Encoded set, as API Gateway requires for binary payloads, along
+// with the matching Content-Encoding header. A strong ETag is suffixed with the
+// coding ("abc" -> "abc-gzip") so each encoded variant has its own validator,
+// and a 304 revalidating an encoded variant carries the same suffixed tag.
+//
+// Responses that are already encoded, smaller than MinSize or of a non-textual
+// media type are passed through unchanged. Every textual response gets
+// "Vary: Accept-Encoding" so caches keep the encoded variants apart.
+//
+// Example:
+//
+//	r.Use(handlers.Compress(handlers.CompressionConfig{MinSize: 512}))
+func Compress(config CompressionConfig) Middleware {
+	if config.MinSize <= 0 {
+		config.MinSize = DefaultCompressionMinSize
+	}
+	if len(config.Encodings) == 0 {
+		config.Encodings = DefaultCompressionConfig().Encodings
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			response, err := next(ctx, request)
+			if err == nil && response.StatusCode == http.StatusNotModified {
+				// A 304 must carry the Vary header and ETag the full response would
+				// have had, including the suffix of the negotiated coding.
+				addVary(&response, "Accept-Encoding")
+				if etag := response.Headers["ETag"]; etag != "" {
+					response.Headers["ETag"] = notModifiedETag(request, etag, config.Encodings)
+				}
+				return response, nil
+			}
+			if err != nil || !compressible(response) {
+				return response, err
+			}
+
+			addVary(&response, "Accept-Encoding")
+
+			body := []byte(response.Body)
+			if response.IsBase64Encoded {
+				if body, err = base64.StdEncoding.DecodeString(response.Body); err != nil {
+					return response, nil
+				}
+			}
+
+			if len(body) < config.MinSize {
+				return response, nil
+			}
+
+			encoding, ok := negotiateEncoding(HeaderValue(request, "Accept-Encoding"), config.Encodings)
+			if !ok {
+				return response, nil
+			}
+
+			encoded, err := encodeBody(encoding, body)
+			if err != nil {
+				return response, nil
+			}
+
+			response.Body = base64.StdEncoding.EncodeToString(encoded)
+			response.IsBase64Encoded = true
+			setHeader(&response, "Content-Encoding", encoding)
+			delete(response.Headers, "Content-Length")
+			if etag := response.Headers["ETag"]; etag != "" {
+				response.Headers["ETag"] = encodedETag(etag, encoding)
+			}
+
+			return response, nil
+		}
+	}
+}
+
+// notModifiedETag returns the ETag a 304 for request carries. The body of a
+// 304 is gone, so whether the full response would have been compressed is
+// taken from the validator the client revalidated: when it is the tag of the
+// negotiated coding, that tag is returned instead of the unencoded one.
+func notModifiedETag(request events.APIGatewayProxyRequest, etag string, encodings []string) string {
+	encoding, ok := negotiateEncoding(HeaderValue(request, "Accept-Encoding"), encodings)
+	if !ok {
+		return etag
+	}
+
+	encoded := encodedETag(etag, encoding)
+	for _, candidate := range strings.Split(HeaderValue(request, "If-None-Match"), ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(encoded, "W/") {
+			return encoded
+		}
+	}
+
+	return etag
+}
+
+// compressible reports whether response carries an unencoded textual body.
+func compressible(response events.APIGatewayProxyResponse) bool {
+	if response.Body == "" || response.StatusCode == http.StatusNoContent || response.StatusCode == http.StatusNotModified {
+		return false
+	}
+
+	if lookupHeader(response.Headers, response.MultiValueHeaders, "Content-Encoding") != "" {
+		return false
+	}
+
+	mediaType, _, _ := strings.Cut(lookupHeader(response.Headers, response.MultiValueHeaders, "Content-Type"), ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "/json") || strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml") ||
+		mediaType == "application/javascript" || mediaType == "application/x-ndjson"
+}
+
+// negotiateEncoding picks the offered coding with the highest quality in
+// acceptEncoding. Ties are broken by the order of offered; "*" matches any
+// coding not listed explicitly.
+func negotiateEncoding(acceptEncoding string, offered []string) (string, bool) {
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		qualities[coding] = quality
+	}
+
+	candidates := make([]string, 0, len(offered))
+	for _, coding := range offered {
+		quality, ok := qualities[coding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > 0 {
+			candidates = append(candidates, coding)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	quality := func(coding string) float64 {
+		if q, ok := qualities[coding]; ok {
+			return q
+		}
+		return qualities["*"]
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return quality(candidates[i]) > quality(candidates[j])
+	})
+
+	return candidates[0], true
+}
+
+// encodeBody compresses body with the given content coding. "deflate" is the
+// zlib format, as defined by RFC 9110.
+func encodeBody(encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var writer io.WriteCloser
+	switch encoding {
+	case EncodingBrotli:
+		writer = brotli.NewWriterLevel(&buf, brotli.DefaultCompression)
+	case EncodingGzip:
+		writer = gzip.NewWriter(&buf)
+	case EncodingDeflate:
+		writer = zlib.NewWriter(&buf)
+	default:
+		return nil, errUnknownEncoding
+	}
+
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to call the API. Entries can be an
+	// exact origin ("https://app.example.com"), a wildcard subdomain
+	// ("https://*.example.com") or "*" to allow any origin.
+	AllowedOrigins []string
+	// AllowedMethods lists the methods advertised in preflight responses.
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers advertised in preflight responses.
+	// When empty, the headers requested by the browser are echoed back.
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers browsers may read.
+	ExposedHeaders []string
+	// AllowCredentials allows cookies and authorization headers. With "*" in
+	// AllowedOrigins the request origin is echoed, since browsers reject "*"
+	// for credentialed requests.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response. Zero omits the header.
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig returns a permissive configuration suitable for public,
+// non-credentialed APIs.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+			http.MethodOptions,
+		},
+		AllowedHeaders: []string{"Content-Type", "Authorization", "X-Api-Key", "X-Amz-Date", "X-Amz-Security-Token", "Idempotency-Key"},
+		ExposedHeaders: []string{"ETag", "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Idempotent-Replayed"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// CORS adds Cross-Origin Resource Sharing headers to every response, including
+// ErrorResponse outputs, and answers preflight OPTIONS requests directly with a
+// 204 so they never reach the wrapped handler.
+//
+// Requests without an Origin header pass through without CORS headers. Actual
+// requests from an origin that is not allowed are served without CORS headers,
+// so the browser blocks them; preflights from such origins get a 403. Unless
+// any origin gets "*", every response carries "Vary: Origin" so shared caches
+// never serve one origin's response to another.
+//
+// Example:
+//
+//	r.Use(handlers.CORS(handlers.CORSConfig{
+//	    AllowedOrigins:   []string{"https://*.example.com"},
+//	    AllowedMethods:   []string{http.MethodGet},
+//	    AllowCredentials: true,
+//	}))
+func CORS(config CORSConfig) Middleware {
+	allowAnyOrigin := false
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			allowAnyOrigin = true
+		}
+	}
+	// The response depends on Origin unless every origin is answered with "*".
+	varyOrigin := !allowAnyOrigin || config.AllowCredentials
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			origin := HeaderValue(request, "Origin")
+			preflight := request.HTTPMethod == http.MethodOptions && HeaderValue(request, "Access-Control-Request-Method") != ""
+
+			if origin == "" {
+				response, err := next(ctx, request)
+				if err == nil && varyOrigin {
+					addVary(&response, "Origin")
+				}
+
+				return response, err
+			}
+
+			allowed := allowAnyOrigin || originAllowed(config.AllowedOrigins, origin)
+
+			if preflight {
+				if !allowed {
+					response, err := CodedErrorResponse(http.StatusForbidden, CodeCORSOriginNotAllowed, "CORS origin not allowed")
+					addVary(&response, "Origin")
+
+					return response, err
+				}
+
+				response := events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent}
+				config.writeOriginHeaders(&response, origin, allowAnyOrigin)
+				addVary(&response, "Access-Control-Request-Method", "Access-Control-Request-Headers")
+				setHeader(&response, "Access-Control-Allow-Methods", strings.Join(config.AllowedMethods, ", "))
+
+				if len(config.AllowedHeaders) > 0 {
+					setHeader(&response, "Access-Control-Allow-Headers", strings.Join(config.AllowedHeaders, ", "))
+				} else if requested := HeaderValue(request, "Access-Control-Request-Headers"); requested != "" {
+					setHeader(&response, "Access-Control-Allow-Headers", requested)
+				}
+
+				if config.MaxAge > 0 {
+					setHeader(&response, "Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+				}
+
+				return response, nil
+			}
+
+			response, err := next(ctx, request)
+			if err != nil {
+				return response, err
+			}
+			if !allowed {
+				addVary(&response, "Origin")
+
+				return response, nil
+			}
+
+			config.writeOriginHeaders(&response, origin, allowAnyOrigin)
+			if len(config.ExposedHeaders) > 0 {
+				setHeader(&response, "Access-Control-Expose-Headers", strings.Join(config.ExposedHeaders, ", "))
+			}
+
+			return response, nil
+		}
+	}
+}
+
+func (config CORSConfig) writeOriginHeaders(response *events.APIGatewayProxyResponse, origin string, allowAnyOrigin bool) {
+	if allowAnyOrigin && !config.AllowCredentials {
+		setHeader(response, "Access-Control-Allow-Origin", "*")
+	} else {
+		setHeader(response, "Access-Control-Allow-Origin", origin)
+		addVary(response, "Origin")
+	}
+
+	if config.AllowCredentials {
+		setHeader(response, "Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// originAllowed matches origin against exact and "scheme://*.domain" entries.
+func originAllowed(allowedOrigins []string, origin string) bool {
+	for _, allowed := range allowedOrigins {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+
+		scheme, host, ok := strings.Cut(allowed, "://*.")
+		if !ok {
+			continue
+		}
+
+		prefix := scheme + "://"
+		suffix := "." + host
+		if len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+			return true
+		}
+	}
+
+	return false
+}
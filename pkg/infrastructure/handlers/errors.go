@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Stable machine-readable error codes returned in the "code" field of every
+// error response. Clients should branch on these instead of parsing messages.
+const (
+	CodeNameTooLong           = "NAME_TOO_LONG"
+	CodeInvalidCharacters     = "INVALID_CHARACTERS"
+	CodeMalformedBody         = "MALFORMED_BODY"
+	CodeEmptyBatch            = "EMPTY_BATCH"
+	CodeBatchTooLarge         = "BATCH_TOO_LARGE"
+	CodeTooManyNames          = "TOO_MANY_NAMES"
+	CodeUnsupportedMediaType  = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRouteNotFound         = "ROUTE_NOT_FOUND"
+	CodeMethodNotAllowed      = "METHOD_NOT_ALLOWED"
+	CodeNotAcceptable         = "NOT_ACCEPTABLE"
+	CodeCORSOriginNotAllowed  = "CORS_ORIGIN_NOT_ALLOWED"
+	CodeRateLimited           = "RATE_LIMITED"
+	CodeMissingToken          = "MISSING_TOKEN"
+	CodeInvalidToken          = "INVALID_TOKEN"
+	CodeIdempotencyInProgress = "IDEMPOTENCY_IN_PROGRESS"
+	CodeIdempotencyKeyReused  = "IDEMPOTENCY_KEY_REUSED"
+	CodeServiceUnavailable    = "SERVICE_UNAVAILABLE"
+	CodeGatewayTimeout        = "GATEWAY_TIMEOUT"
+	CodeInternalError         = "INTERNAL_ERROR"
+)
+
+// errorBody is the default JSON error shape. The "code" field was added after
+// "error" and "status", so existing clients keep working unchanged.
+type errorBody struct {
+	Error  string `json:"error"`
+	Status string `json:"status"`
+	Code   string `json:"code"`
+}
+
+// ErrorResponse builds the JSON error body shared by every API Gateway handler:
+//
+//	{"error": "<message>", "status": "<statusCode>", "code": "<CODE>"}
+//
+// The code is derived from the status text (404 -> "NOT_FOUND"); use
+// CodedErrorResponse when a more specific code exists. It is exported so routing
+// and other transport layers can report their own failures (404, 405, ...) in the
+// same shape as validation errors.
+func ErrorResponse(statusCode int, message string) (events.APIGatewayProxyResponse, error) {
+	code := strings.ToUpper(strings.ReplaceAll(http.StatusText(statusCode), " ", "_"))
+	if code == "" {
+		code = CodeInternalError
+	}
+
+	return CodedErrorResponse(statusCode, code, message)
+}
+
+// CodedErrorResponse is ErrorResponse with an explicit machine-readable code.
+func CodedErrorResponse(statusCode int, code, message string) (events.APIGatewayProxyResponse, error) {
+	body, _ := json.Marshal(errorBody{
+		Error:  message,
+		Status: fmt.Sprintf("%d", statusCode),
+		Code:   code,
+	})
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		Body: string(body),
+	}, nil
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+)
+
+// FlagTargeting stores the entities.FlagTarget of each request in its context,
+// so handlers and use cases can evaluate services.FeatureFlags for the caller.
+// Register it after JWTAuth so authenticated callers are recognised.
+//
+// The target key is the authenticated subject, so a user sees the same side
+// of a rollout on every request, or the request ID for anonymous callers. The
+// attributes available to targeting rules are:
+//
+//	user_id     authenticated subject, when authenticated
+//	request_id  API Gateway request ID
+//	method      HTTP method
+//	path        request path
+//	source_ip   client IP address
+//
+// Example:
+//
+//	r.Use(handlers.FlagTargeting())
+//	// later, in a handler or use case:
+//	flags.Bool(ctx, "greeting-emoji", entities.FlagTargetFromContext(ctx), false)
+func FlagTargeting() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			target := entities.FlagTarget{
+				Key: request.RequestContext.RequestID,
+				Attributes: map[string]string{
+					"request_id": request.RequestContext.RequestID,
+					"method":     request.HTTPMethod,
+					"path":       request.Path,
+					"source_ip":  request.RequestContext.Identity.SourceIP,
+				},
+			}
+			if principal, ok := entities.PrincipalFromContext(ctx); ok && principal.Subject != "" {
+				target.Key = principal.Subject
+				target.Attributes["user_id"] = principal.Subject
+			}
+
+			return next(entities.ContextWithFlagTarget(ctx, target), request)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+)
+
+// Media types the greeting can be rendered as, in order of preference.
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeText = "text/plain"
+	MediaTypeHTML = "text/html"
+	MediaTypeXML  = "application/xml"
+)
+
+// supportedMediaTypes lists the renderable media types; the first one is the default.
+var supportedMediaTypes = []string{MediaTypeJSON, MediaTypeText, MediaTypeHTML, MediaTypeXML}
+
+// greetingBody is the structured representation of a greeting. Names lists
+// every person greeted when there is more than one, in which case Name joins
+// them as in the message. XMLNames carries the same list for XML, where a
+// nil pointer is the only way to omit the <names> wrapper.
+type greetingBody struct {
+	XMLName  xml.Name       `json:"-" xml:"greeting"`
+	Message  string         `json:"message" xml:"message"`
+	Name     string         `json:"name" xml:"name"`
+	Names    []string       `json:"names,omitempty" xml:"-"`
+	XMLNames *greetingNames `json:"-" xml:"names,omitempty"`
+}
+
+// greetingNames renders as <names><name>Ana</name><name>Luis</name></names>.
+type greetingNames struct {
+	Name []string `xml:"name"`
+}
+
+// greetingResponse renders a successful greeting in the media type negotiated
+// from the Accept header. JSON is used when the header is absent or accepts
+// anything; a 406 ErrorResponse is returned when no supported type is acceptable.
+// With more than one name greeted the body lists them.
+//
+// Example bodies for name "John":
+//
+//	application/json -> {"message":"Hello John!","name":"John"}
+//	text/plain       -> Hello John!
+//	text/html        -> <!DOCTYPE html>...<p>Hello John!</p>...
+//	application/xml  -> <greeting><message>Hello John!</message><name>John</name></greeting>
+//
+// and for names "Ana" and "Luis":
+//
+//	{"message":"Hello Ana and Luis!","name":"Ana and Luis","names":["Ana","Luis"]}
+func greetingResponse(accept string, greeting hello.Greeting) (events.APIGatewayProxyResponse, error) {
+	body := greetingBody{Message: greeting.Message, Name: hello.JoinNames(greeting.Names)}
+	if len(greeting.Names) > 1 {
+		body.Names = greeting.Names
+		body.XMLNames = &greetingNames{Name: greeting.Names}
+	}
+
+	return renderGreeting(accept, body)
+}
+
+// renderGreeting writes greeting in the media type negotiated from accept.
+func renderGreeting(accept string, greeting greetingBody) (events.APIGatewayProxyResponse, error) {
+	mediaType, ok := negotiateMediaType(accept)
+	if !ok {
+		response, err := CodedErrorResponse(http.StatusNotAcceptable, CodeNotAcceptable,
+			"Not acceptable. Supported media types: "+strings.Join(supportedMediaTypes, ", "))
+		addVary(&response, "Accept")
+		return response, err
+	}
+
+	var body string
+	contentType := mediaType
+	switch mediaType {
+	case MediaTypeText:
+		body = greeting.Message
+		contentType += "; charset=utf-8"
+	case MediaTypeHTML:
+		body = "<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title>Greeting</title></head>" +
+			"<body><p>" + html.EscapeString(greeting.Message) + "</p></body></html>"
+		contentType += "; charset=utf-8"
+	case MediaTypeXML:
+		encoded, _ := xml.Marshal(greeting)
+		body = xml.Header + string(encoded)
+	default:
+		encoded, _ := json.Marshal(greeting)
+		body = string(encoded)
+	}
+
+	response := events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Content-Type": contentType,
+		},
+		Body: body,
+	}
+	addVary(&response, "Accept")
+
+	return response, nil
+}
+
+// mediaRange is one entry of an Accept header.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiateMediaType picks the supported media type with the highest quality in
+// accept. Entries with equal quality keep their header order. Ranges with q=0
+// exclude the types they match from any less specific range, so
+// "text/html;q=0, */*" never picks HTML.
+func negotiateMediaType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return supportedMediaTypes[0], true
+	}
+
+	var ranges, excluded []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		current := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if quality, err := strconv.ParseFloat(value, 64); err == nil {
+					current.quality = quality
+				}
+			}
+		}
+		switch {
+		case current.mediaType == "":
+		case current.quality > 0:
+			ranges = append(ranges, current)
+		default:
+			excluded = append(excluded, current)
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	acceptable := func(r mediaRange, mediaType string) bool {
+		for _, exclusion := range excluded {
+			if mediaTypeMatches(exclusion.mediaType, mediaType) &&
+				mediaRangeSpecificity(exclusion.mediaType) >= mediaRangeSpecificity(r.mediaType) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, r := range ranges {
+		for _, supported := range supportedMediaTypes {
+			if mediaTypeMatches(r.mediaType, supported) && acceptable(r, supported) {
+				return supported, true
+			}
+		}
+		// Aliases: text/xml is served as XML, and clients asking for
+		// problem+json errors get JSON greetings.
+		if alias, ok := mediaTypeAliases[r.mediaType]; ok && acceptable(r, alias) {
+			return alias, true
+		}
+	}
+
+	return "", false
+}
+
+// mediaTypeAliases maps accepted media types that are not rendered as such to
+// the supported type served instead.
+var mediaTypeAliases = map[string]string{
+	"text/xml":           MediaTypeXML,
+	MediaTypeProblemJSON: MediaTypeJSON,
+}
+
+// mediaRangeSpecificity ranks "*/*" below "type/*" below "type/subtype".
+func mediaRangeSpecificity(mediaRange string) int {
+	switch {
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func mediaTypeMatches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	rangeType, rangeSubtype, _ := strings.Cut(mediaRange, "/")
+	typ, _, _ := strings.Cut(mediaType, "/")
+
+	return rangeSubtype == "*" && rangeType == typ
+}
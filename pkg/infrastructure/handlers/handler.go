@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Handler is the signature shared by every API Gateway (REST API, payload v1)
+// handler in this package. HelloHandler.Handle satisfies it, and the router and
+// other transport layers compose values of this type.
+type Handler func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
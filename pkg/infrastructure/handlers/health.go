@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/buildinfo"
+)
+
+// DefaultHealthCheckTimeout bounds each health check when neither the checker
+// nor HealthConfig.Timeout sets a timeout.
+const DefaultHealthCheckTimeout = 2 * time.Second
+
+// Health statuses reported by HealthHandler.
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+	HealthStatusFail        = "fail"
+)
+
+// HealthConfig configures the health endpoint.
+type HealthConfig struct {
+	// Checkers are run on every request. Their names must be unique.
+	Checkers []services.HealthChecker
+	// Timeout bounds checkers whose Timeout is zero. Zero means
+	// DefaultHealthCheckTimeout.
+	Timeout time.Duration
+	// Version is the reported build version. Defaults to buildinfo.Version().
+	Version string
+	// ColdStart is when the execution environment started. Defaults to
+	// buildinfo.ColdStart.
+	ColdStart time.Time
+	// Logger reports failed checks with their errors, which the response
+	// leaves out since /health is public. Optional.
+	Logger services.Logger
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// healthBody is the body of a health response.
+type healthBody struct {
+	Status        string                       `json:"status"`
+	Version       string                       `json:"version"`
+	ColdStart     time.Time                    `json:"cold_start"`
+	UptimeSeconds float64                      `json:"uptime_seconds"`
+	Checks        map[string]healthCheckResult `json:"checks"`
+}
+
+// healthCheckResult is the outcome of one HealthChecker.
+type healthCheckResult struct {
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// HealthHandler returns a Handler for synthetic monitors that reports the
+// build version, the cold start time and uptime of the execution environment,
+// and the outcome of every checker, run concurrently with its timeout. Why a
+// check failed is logged rather than served, so dependency errors do not leak
+// to callers.
+//
+// It panics when two checkers share a name, since the report could not tell
+// them apart.
+//
+// Returns:
+//   - APIGatewayProxyResponse with status 200 when every check passed
+//   - APIGatewayProxyResponse with status 503 when at least one check failed or timed out
+//
+// Example:
+//
+//	GET /health
+//	-> 503: {"status":"unavailable","version":"v1.2.3","cold_start":"2026-01-15T10:00:00Z",
+//	         "uptime_seconds":42.5,"checks":{
+//	           "feature_flags":{"status":"ok","duration_ms":3},
+//	           "orders-table":{"status":"fail","duration_ms":2000}}}
+func HealthHandler(config HealthConfig) Handler {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultHealthCheckTimeout
+	}
+	if config.Version == "" {
+		config.Version = buildinfo.Version()
+	}
+	if config.ColdStart.IsZero() {
+		config.ColdStart = buildinfo.ColdStart
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	seen := make(map[string]bool, len(config.Checkers))
+	for _, checker := range config.Checkers {
+		if seen[checker.Name()] {
+			panic(fmt.Sprintf("handlers: duplicate health checker %q", checker.Name()))
+		}
+		seen[checker.Name()] = true
+	}
+
+	return func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		body := healthBody{
+			Status:        HealthStatusOK,
+			Version:       config.Version,
+			ColdStart:     config.ColdStart.UTC(),
+			UptimeSeconds: config.Now().Sub(config.ColdStart).Seconds(),
+			Checks:        make(map[string]healthCheckResult, len(config.Checkers)),
+		}
+
+		results := make([]healthCheckResult, len(config.Checkers))
+		errs := make([]error, len(config.Checkers))
+		var wg sync.WaitGroup
+		for i, checker := range config.Checkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = config.check(ctx, checker)
+			}()
+		}
+		wg.Wait()
+
+		for i, checker := range config.Checkers {
+			result := results[i]
+			body.Checks[checker.Name()] = result
+			if result.Status == HealthStatusOK {
+				continue
+			}
+
+			body.Status = HealthStatusUnavailable
+			if config.Logger != nil {
+				config.Logger.Log(ctx, services.LevelWarn, "Health check failed",
+					services.Field{Key: "check", Value: checker.Name()},
+					services.Field{Key: "duration_ms", Value: result.DurationMS},
+					services.Field{Key: "error", Value: errs[i].Error()},
+				)
+			}
+		}
+
+		statusCode := http.StatusOK
+		if body.Status != HealthStatusOK {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		encoded, _ := json.Marshal(body)
+
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers: map[string]string{
+				"Content-Type":  MediaTypeJSON,
+				"Cache-Control": "no-store",
+			},
+			Body: string(encoded),
+		}, nil
+	}
+}
+
+// check runs checker with its timeout. A checker that ignores its context is
+// abandoned when the timeout elapses, and a panic is reported as a failure.
+// The returned error tells why the check failed.
+func (config HealthConfig) check(ctx context.Context, checker services.HealthChecker) (healthCheckResult, error) {
+	timeout := checker.Timeout()
+	if timeout <= 0 {
+		timeout = config.Timeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := config.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+
+		done <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := healthCheckResult{
+		Status:     HealthStatusOK,
+		DurationMS: config.Now().Sub(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = HealthStatusFail
+	}
+
+	return result, err
+}
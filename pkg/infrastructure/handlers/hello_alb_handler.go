@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+)
+
+// HelloALBHandler serves Application Load Balancer target group requests for
+// the hello endpoint. It shares the use case and error mapping of HelloHandler
+// so the greeting and validation errors are identical behind an ALB. Build it
+// once at cold start with NewHelloALBHandler so warm invocations reuse its
+// logger and use case.
+//
+// The ALB sends either single-value or multi-value maps depending on whether the
+// target group has multi-value headers enabled. The handler detects the mode from
+// the request and answers in the same mode, as the ALB requires.
+//
+// Query Parameters:
+//   - name (optional): The name to include in the greeting. The ALB forwards query
+//     values exactly as sent by the client, so they are URL-decoded here.
+//
+// Returns:
+//   - ALBTargetGroupResponse with status 200 and the greeting rendered in the
+//     media type negotiated from the Accept header (see HelloHandler)
+//   - ALBTargetGroupResponse with status 400 if validation fails
+//   - ALBTargetGroupResponse with status 406 if no supported media type is acceptable
+//
+// Example requests:
+//
+//	GET /hello?name=John     -> 200 OK: {"message":"Hello John!","name":"John"}
+//	GET /hello               -> 200 OK: {"message":"Hello world!","name":"world"}
+//	GET /hello?name=<script> -> 400 Bad Request: Validation error
+type HelloALBHandler struct {
+	logger services.Logger
+	greet  GreetUseCase
+}
+
+// NewHelloALBHandler creates a HelloALBHandler logging to loggerService and
+// greeting through greet.
+//
+// Example:
+//
+//	lambda.Start(handlers.NewHelloALBHandler(loggerService, hello.Greeter{Rules: rules}.Greet).Handle)
+func NewHelloALBHandler(loggerService services.Logger, greet GreetUseCase) *HelloALBHandler {
+	return &HelloALBHandler{logger: loggerService, greet: greet}
+}
+
+// Handle processes an ALB target group request for the hello endpoint.
+func (h *HelloALBHandler) Handle(
+	ctx context.Context,
+	request events.ALBTargetGroupRequest,
+) (events.ALBTargetGroupResponse, error) {
+	multiValue := request.MultiValueQueryStringParameters != nil || request.MultiValueHeaders != nil
+
+	h.logger.Log(ctx, services.LevelDebug, "Request received",
+		services.Field{Key: "query_params", Value: request.QueryStringParameters},
+		services.Field{Key: "multi_value_query_params", Value: request.MultiValueQueryStringParameters},
+		services.Field{Key: "http_method", Value: request.HTTPMethod},
+		services.Field{Key: "path", Value: request.Path},
+		services.Field{Key: "target_group_arn", Value: request.RequestContext.ELB.TargetGroupArn},
+	)
+
+	name := albQueryParameter(request, "name")
+	greeting, err := h.greet(ctx, []string{name})
+	if err != nil {
+		h.logger.Log(ctx, services.LevelWarn, "Validation failed",
+			services.Field{Key: "name", Value: name},
+			services.Field{Key: "error", Value: err.Error()},
+		)
+
+		response, err := mapErrorToResponse(err)
+		return toALBResponse(response, multiValue), err
+	}
+
+	response, err := greetingResponse(lookupHeader(request.Headers, request.MultiValueHeaders, "Accept"), greeting)
+	return toALBResponse(response, multiValue), err
+}
+
+// albQueryParameter returns the first decoded value of key from whichever query
+// map the ALB populated.
+func albQueryParameter(request events.ALBTargetGroupRequest, key string) string {
+	value := request.QueryStringParameters[key]
+	if values := request.MultiValueQueryStringParameters[key]; len(values) > 0 {
+		value = values[0]
+	}
+
+	if decoded, err := url.QueryUnescape(value); err == nil {
+		return decoded
+	}
+
+	return value
+}
+
+// toALBResponse converts a proxy response into the ALB shape, adding the
+// mandatory status description and moving headers into the multi-value map
+// when the target group runs in multi-value mode.
+func toALBResponse(response events.APIGatewayProxyResponse, multiValue bool) events.ALBTargetGroupResponse {
+	albResponse := events.ALBTargetGroupResponse{
+		StatusCode:        response.StatusCode,
+		StatusDescription: fmt.Sprintf("%d %s", response.StatusCode, http.StatusText(response.StatusCode)),
+		Body:              response.Body,
+		IsBase64Encoded:   response.IsBase64Encoded,
+	}
+
+	if !multiValue {
+		albResponse.Headers = response.Headers
+		return albResponse
+	}
+
+	albResponse.MultiValueHeaders = make(map[string][]string, len(response.Headers)+len(response.MultiValueHeaders))
+	for key, values := range response.MultiValueHeaders {
+		albResponse.MultiValueHeaders[key] = append(albResponse.MultiValueHeaders[key], values...)
+	}
+	for key, value := range response.Headers {
+		albResponse.MultiValueHeaders[key] = append(albResponse.MultiValueHeaders[key], value)
+	}
+
+	return albResponse
+}
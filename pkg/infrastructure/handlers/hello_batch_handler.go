@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
+)
+
+// DefaultMaxBatchSize is the number of names a batch request may contain when
+// BatchConfig.MaxBatchSize is not set.
+const DefaultMaxBatchSize = 500
+
+// BatchConfig configures the batch greeting endpoint.
+type BatchConfig struct {
+	// MaxBatchSize caps the number of names per request. Zero means DefaultMaxBatchSize.
+	MaxBatchSize int
+	// Greet greets every name, so batches follow the same rules and feature
+	// flags as GET /hello. Defaults to hello.Greeter{}.Greet.
+	Greet GreetUseCase
+	// Logger is used by the handler and its middleware. Defaults to logger.NewLogger().
+	Logger services.Logger
+}
+
+// helloBatchRequest is the body accepted by POST /hello/batch. A bare JSON
+// array of names is accepted as well.
+//
+// Example bodies:
+//
+//	application/json                  -> {"names":["John","Jane"]} or ["John","Jane"]
+//	application/x-www-form-urlencoded -> name=John&name=Jane
+type helloBatchRequest struct {
+	Names []string `json:"names"`
+}
+
+// batchItemResult is the outcome of greeting a single name of a batch.
+type batchItemResult struct {
+	Index   int    `json:"index"`
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
+
+// batchResponseBody is the body of a batch response.
+type batchResponseBody struct {
+	Results   []batchItemResult `json:"results"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+}
+
+// HelloBatchHandler returns a Handler that greets every name of a batch,
+// running each one through config.Greet independently so one invalid name
+// does not fail the whole request. Request correlation, logging and panic
+// recovery are left to the router middleware.
+//
+// Returns:
+//   - APIGatewayProxyResponse with status 200 when every name was greeted
+//   - APIGatewayProxyResponse with status 207 when at least one name failed validation
+//   - APIGatewayProxyResponse with status 400 if the batch is empty, exceeds MaxBatchSize or is malformed
+//   - APIGatewayProxyResponse with status 415 if the body media type is not supported
+//
+// Example:
+//
+//	POST /hello/batch {"names":["John","<script>"]}
+//	-> 207: {"results":[
+//	     {"index":0,"name":"John","message":"Hello John!"},
+//	     {"index":1,"name":"<script>","error":"name contains invalid characters...","code":"INVALID_CHARACTERS"}
+//	   ],"succeeded":1,"failed":1}
+func HelloBatchHandler(config BatchConfig) Handler {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = DefaultMaxBatchSize
+	}
+	if config.Logger == nil {
+		config.Logger = logger.NewLogger()
+	}
+	if config.Greet == nil {
+		config.Greet = hello.Greeter{}.Greet
+	}
+
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		dto, err := decodeHelloBatchRequest(request)
+		if err != nil {
+			config.Logger.Log(ctx, services.LevelWarn, "Invalid request body",
+				services.Field{Key: "content_type", Value: HeaderValue(request, "Content-Type")},
+				services.Field{Key: "error", Value: err.Error()},
+			)
+
+			return bodyErrorResponse(err)
+		}
+
+		switch {
+		case len(dto.Names) == 0:
+			return CodedErrorResponse(http.StatusBadRequest, CodeEmptyBatch, "names must contain at least one name")
+		case len(dto.Names) > config.MaxBatchSize:
+			return CodedErrorResponse(http.StatusBadRequest, CodeBatchTooLarge,
+				fmt.Sprintf("batch contains %d names. Maximum %d names allowed.", len(dto.Names), config.MaxBatchSize))
+		}
+
+		body := batchResponseBody{Results: make([]batchItemResult, len(dto.Names))}
+		for i, name := range dto.Names {
+			result := batchItemResult{Index: i, Name: name}
+
+			greeting, err := config.Greet(ctx, []string{name})
+			if err != nil {
+				_, result.Code, result.Error = describeError(err)
+				body.Failed++
+			} else {
+				result.Name = hello.JoinNames(greeting.Names)
+				result.Message = greeting.Message
+				body.Succeeded++
+			}
+
+			body.Results[i] = result
+		}
+
+		config.Logger.Log(ctx, services.LevelInfo, "Batch processed",
+			services.Field{Key: "size", Value: len(dto.Names)},
+			services.Field{Key: "succeeded", Value: body.Succeeded},
+			services.Field{Key: "failed", Value: body.Failed},
+		)
+
+		statusCode := http.StatusOK
+		if body.Failed > 0 {
+			statusCode = http.StatusMultiStatus
+		}
+
+		encoded, _ := json.Marshal(body)
+
+		return events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers: map[string]string{
+				"Content-Type": MediaTypeJSON,
+			},
+			Body: string(encoded),
+		}, nil
+	}
+}
+
+// decodeHelloBatchRequest reads a helloBatchRequest from the request body
+// according to its Content-Type.
+func decodeHelloBatchRequest(request events.APIGatewayProxyRequest) (helloBatchRequest, error) {
+	var dto helloBatchRequest
+
+	mediaType, body, err := readBody(request)
+	if err != nil || len(body) == 0 {
+		return dto, err
+	}
+
+	switch mediaType {
+	case MediaTypeJSON:
+		if trimmed := bytes.TrimSpace(body); trimmed[0] == '[' {
+			err = decodeJSON(trimmed, &dto.Names)
+		} else {
+			err = decodeJSON(trimmed, &dto)
+		}
+	case MediaTypeForm:
+		var values url.Values
+		values, err = parseForm(body)
+		dto.Names = values["name"]
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnsupportedMediaType, mediaType)
+	}
+
+	return dto, err
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+)
+
+// streamedGreeting is a single NDJSON line written by the streaming handler.
+// Exactly one of Message or Error is set.
+type streamedGreeting struct {
+	Name    string `json:"name"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HelloFunctionURLHandler serves Lambda Function URL requests for the hello
+// endpoint, in either invoke mode. It shares the use case and error mapping of
+// HelloHandler. Build it once at cold start with NewHelloFunctionURLHandler so
+// warm invocations reuse its logger and use case.
+type HelloFunctionURLHandler struct {
+	logger services.Logger
+	greet  GreetUseCase
+}
+
+// NewHelloFunctionURLHandler creates a HelloFunctionURLHandler logging to
+// loggerService and greeting through greet.
+//
+// Example:
+//
+//	lambda.Start(handlers.NewHelloFunctionURLHandler(loggerService, hello.Greeter{Rules: rules}.Greet).Handle)
+func NewHelloFunctionURLHandler(loggerService services.Logger, greet GreetUseCase) *HelloFunctionURLHandler {
+	return &HelloFunctionURLHandler{logger: loggerService, greet: greet}
+}
+
+// Handle processes Lambda Function URL requests when the URL uses the default
+// BUFFERED invoke mode.
+//
+// Query Parameters:
+//   - name (optional): The name to include in the greeting.
+//
+// Returns:
+//   - LambdaFunctionURLResponse with status 200 and the greeting rendered in the
+//     media type negotiated from the Accept header (see HelloHandler)
+//   - LambdaFunctionURLResponse with status 400 if validation fails
+//   - LambdaFunctionURLResponse with status 406 if no supported media type is acceptable
+//
+// Example requests:
+//
+//	GET /?name=John     -> 200: {"message":"Hello John!","name":"John"}
+//	GET /               -> 200: {"message":"Hello world!","name":"world"}
+//	GET /?name=<script> -> 400: Validation error
+func (h *HelloFunctionURLHandler) Handle(
+	ctx context.Context,
+	request events.LambdaFunctionURLRequest,
+) (events.LambdaFunctionURLResponse, error) {
+	if request.RequestContext.RequestID != "" {
+		ctx = context.WithValue(ctx, "request_id", request.RequestContext.RequestID)
+	}
+
+	h.logger.Log(ctx, services.LevelDebug, "Request received",
+		services.Field{Key: "raw_query_string", Value: request.RawQueryString},
+		services.Field{Key: "cookies", Value: cookieNames(request.Cookies)},
+		services.Field{Key: "http_method", Value: request.RequestContext.HTTP.Method},
+		services.Field{Key: "path", Value: request.RawPath},
+	)
+
+	name := rawQueryParameter(request.RawQueryString, request.QueryStringParameters, "name")
+	greeting, err := h.greet(ctx, []string{name})
+	if err != nil {
+		h.logger.Log(ctx, services.LevelWarn, "Validation failed",
+			services.Field{Key: "name", Value: name},
+			services.Field{Key: "error", Value: err.Error()},
+		)
+
+		response, err := mapErrorToResponse(err)
+		return toFunctionURLResponse(response), err
+	}
+
+	response, err := greetingResponse(lookupHeader(request.Headers, nil, "Accept"), greeting)
+	return toFunctionURLResponse(response), err
+}
+
+func toFunctionURLResponse(response events.APIGatewayProxyResponse) events.LambdaFunctionURLResponse {
+	return events.LambdaFunctionURLResponse{
+		StatusCode:      response.StatusCode,
+		Headers:         response.Headers,
+		Body:            response.Body,
+		IsBase64Encoded: response.IsBase64Encoded,
+	}
+}
+
+// HandleStream processes Lambda Function URL requests when the URL uses the
+// RESPONSE_STREAM invoke mode. Every "name" query parameter is greeted in order
+// and written as one NDJSON line as soon as it is produced, so large lists are
+// flushed incrementally instead of being buffered in one body.
+//
+// The status code is sent before the first greeting, so it is always 200; per-name
+// validation failures are reported inline in the "error" field of their line.
+// Streaming stops early when ctx is cancelled.
+//
+// Query Parameters:
+//   - name (optional, repeatable): The names to greet. Defaults to a single greeting
+//     for the default name when absent.
+//
+// Example request:
+//
+//	GET /?name=Ana&name=<x> -> 200:
+//	  {"name":"Ana","message":"Hello Ana!"}
+//	  {"name":"<x>","error":"name contains invalid characters"}
+//
+// Streaming needs a custom runtime (provided.al2 or provided.al2023), whose
+// runtime API lambda.Start uses to stream a *LambdaFunctionURLStreamingResponse,
+// and a Function URL configured with InvokeMode RESPONSE_STREAM.
+func (h *HelloFunctionURLHandler) HandleStream(
+	ctx context.Context,
+	request events.LambdaFunctionURLRequest,
+) (*events.LambdaFunctionURLStreamingResponse, error) {
+	if request.RequestContext.RequestID != "" {
+		ctx = context.WithValue(ctx, "request_id", request.RequestContext.RequestID)
+	}
+
+	names := streamNames(request)
+
+	h.logger.Log(ctx, services.LevelDebug, "Streaming request received",
+		services.Field{Key: "names_count", Value: len(names)},
+		services.Field{Key: "http_method", Value: request.RequestContext.HTTP.Method},
+		services.Field{Key: "path", Value: request.RawPath},
+	)
+
+	reader, writer := io.Pipe()
+	go h.streamGreetings(ctx, writer, names)
+
+	return &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: 200,
+		Headers: map[string]string{
+			"Content-Type": "application/x-ndjson",
+		},
+		Body: reader,
+	}, nil
+}
+
+// streamNames collects every "name" value from the request, defaulting to a
+// single empty name so the use case produces the default greeting.
+func streamNames(request events.LambdaFunctionURLRequest) []string {
+	if request.RawQueryString != "" {
+		if values, err := url.ParseQuery(request.RawQueryString); err == nil && len(values["name"]) > 0 {
+			return values["name"]
+		}
+	}
+
+	if name, ok := request.QueryStringParameters["name"]; ok {
+		return []string{name}
+	}
+
+	return []string{""}
+}
+
+func (h *HelloFunctionURLHandler) streamGreetings(ctx context.Context, writer *io.PipeWriter, names []string) {
+	encoder := json.NewEncoder(writer)
+
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			h.logger.Log(ctx, services.LevelWarn, "Streaming cancelled",
+				services.Field{Key: "error", Value: err.Error()},
+			)
+			writer.CloseWithError(err)
+			return
+		}
+
+		line := streamedGreeting{Name: name}
+		greeting, err := h.greet(ctx, []string{name})
+		if err != nil {
+			line.Error = err.Error()
+		} else {
+			line.Message = greeting.Message
+		}
+
+		if err := encoder.Encode(line); err != nil {
+			writer.CloseWithError(err)
+			return
+		}
+	}
+
+	writer.Close()
+}
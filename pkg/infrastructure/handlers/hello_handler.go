@@ -2,9 +2,10 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"sync"
 
 	"github.com/aws/aws-lambda-go/events"
 
@@ -13,85 +14,159 @@ import (
 	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
 )
 
-// HelloHandleRequest processes AWS API Gateway requests for the hello endpoint.
-// It delegates input validation and business logic to the use case layer.
+// GreetUseCase greets one or more names and reports who was greeted.
+// hello.Greeter.Greet satisfies it.
+type GreetUseCase func(ctx context.Context, names []string) (hello.Greeting, error)
+
+// HelloHandlerConfig configures a HelloHandler.
+type HelloHandlerConfig struct {
+	// MaxNames caps the names greeted in one request. Zero means no limit.
+	MaxNames int
+}
+
+// HelloHandler serves the hello endpoint. Build it once at cold start with
+// NewHelloHandler so warm invocations reuse its logger, use case and middleware
+// chain.
+//
+// It delegates input validation and business logic to the use case, and
+// request correlation, panic recovery and logging to the RequestID, Recover and
+// RequestLogger middlewares.
+//
+// Query Parameters (GET):
+//   - name (optional, repeatable): The name to include in the greeting. When
+//     several names are given, all of them are greeted in one message.
 //
-// Query Parameters:
-//   - name (optional): The name to include in the greeting.
+// Body (POST):
+//   - {"name": "..."} as application/json, or name=... as application/x-www-form-urlencoded
+//     (repeatable like the query parameter). Base64-encoded bodies are decoded
+//     first; an empty body greets the default name.
+//
+// Headers:
+//   - Accept (optional): application/json (default), text/plain, text/html or application/xml.
+//   - Content-Type (POST): application/json or application/x-www-form-urlencoded.
 //
 // Returns:
-//   - APIGatewayProxyResponse with status 200 and greeting message in the body
-//   - APIGatewayProxyResponse with status 400 if validation fails
+//   - APIGatewayProxyResponse with status 200 and the greeting rendered in the negotiated media type
+//   - APIGatewayProxyResponse with status 400 if validation fails, the body is malformed
+//     or more than MaxNames names are given
+//   - APIGatewayProxyResponse with status 415 if the body media type is not supported
+//   - APIGatewayProxyResponse with status 406 if no supported media type is acceptable
 //
 // Example requests:
 //
-//	GET /hello?name=John     -> 200: "Hello John!"
-//	GET /hello               -> 200: "Hello world!"
-//	GET /hello?name=<script> -> 400: Validation error
+//	GET /hello?name=John                     -> 200: {"message":"Hello John!","name":"John"}
+//	GET /hello                               -> 200: {"message":"Hello world!","name":"world"}
+//	GET /hello?name=John  Accept: text/plain -> 200: Hello John!
+//	GET /hello?name=Ana&name=Luis            -> 200: {"message":"Hello Ana and Luis!","name":"Ana and Luis","names":["Ana","Luis"]}
+//	GET /hello?name=<script>                 -> 400: Validation error
+//	POST /hello {"name":"John"}              -> 200: {"message":"Hello John!","name":"John"}
+//	POST /hello {"name":                     -> 400: Malformed body
+type HelloHandler struct {
+	logger services.Logger
+	greet  GreetUseCase
+	config HelloHandlerConfig
+}
+
+// NewHelloHandler creates a HelloHandler logging to loggerService and greeting
+// through greet.
+//
+// Example:
+//
+//	helloHandler := handlers.NewHelloHandler(logger.NewLogger(), hello.Greeter{}.Greet, handlers.HelloHandlerConfig{})
+//	r.Handle(http.MethodGet, "/hello", helloHandler.Handle)
+func NewHelloHandler(loggerService services.Logger, greet GreetUseCase, config HelloHandlerConfig) *HelloHandler {
+	return &HelloHandler{
+		logger: loggerService,
+		greet:  greet,
+		config: config,
+	}
+}
+
+// defaultHelloHandler backs HelloHandleRequest. Outside a router it sets the
+// request id and logs the request itself.
+var defaultHelloHandler = sync.OnceValue(func() Handler {
+	loggerService := logger.NewLogger()
+
+	return Chain(NewHelloHandler(loggerService, hello.Greeter{}.Greet, HelloHandlerConfig{}).Handle,
+		RequestID(),
+		RequestLogger(loggerService),
+	)
+})
+
+// HelloHandleRequest serves the hello endpoint with a HelloHandler built on
+// first use with the default logger, use case and configuration.
+//
+// Deprecated: build a HelloHandler with NewHelloHandler and use its Handle
+// method, so dependencies can be injected.
 func HelloHandleRequest(
 	ctx context.Context,
 	request events.APIGatewayProxyRequest,
 ) (events.APIGatewayProxyResponse, error) {
-	if request.RequestContext.RequestID != "" {
-		ctx = context.WithValue(ctx, "request_id", request.RequestContext.RequestID)
+	return defaultHelloHandler()(ctx, request)
+}
+
+// Handle processes an API Gateway request for the hello endpoint. It satisfies
+// Handler; request correlation, logging and panic recovery are left to the
+// router middleware.
+func (h *HelloHandler) Handle(
+	ctx context.Context,
+	request events.APIGatewayProxyRequest,
+) (events.APIGatewayProxyResponse, error) {
+	names := QueryValues(request, "name")
+	if request.HTTPMethod == http.MethodPost {
+		dto, err := decodeHelloRequest(request)
+		if err != nil {
+			h.logger.Log(ctx, services.LevelWarn, "Invalid request body",
+				services.Field{Key: "content_type", Value: HeaderValue(request, "Content-Type")},
+				services.Field{Key: "error", Value: err.Error()},
+			)
+
+			return bodyErrorResponse(err)
+		}
+		names = dto.Names
 	}
 
-	loggerService := logger.NewLogger()
-	loggerService.Log(ctx, services.LevelDebug, "Request received",
-		services.Field{Key: "query_params", Value: request.QueryStringParameters},
-		services.Field{Key: "http_method", Value: request.HTTPMethod},
-		services.Field{Key: "path", Value: request.Path},
-	)
+	if h.config.MaxNames > 0 && len(names) > h.config.MaxNames {
+		return CodedErrorResponse(http.StatusBadRequest, CodeTooManyNames,
+			fmt.Sprintf("request contains %d names. Maximum %d names allowed.", len(names), h.config.MaxNames))
+	}
 
-	name := request.QueryStringParameters["name"]
-	message, err := hello.SayHelloUseCase(name)
+	greeting, err := h.greet(ctx, names)
 	if err != nil {
-		loggerService.Log(ctx, services.LevelWarn, "Validation failed",
-			services.Field{Key: "name", Value: name},
+		h.logger.Log(ctx, services.LevelWarn, "Validation failed",
+			services.Field{Key: "names", Value: names},
 			services.Field{Key: "error", Value: err.Error()},
 		)
 
 		return mapErrorToResponse(err)
 	}
 
-	return events.APIGatewayProxyResponse{
-		StatusCode: 200,
-		Body:       message,
-	}, nil
+	return greetingResponse(HeaderValue(request, "Accept"), greeting)
 }
 
 func mapErrorToResponse(err error) (events.APIGatewayProxyResponse, error) {
-	var statusCode int
-	var message string
+	statusCode, code, message := describeError(err)
 
+	return CodedErrorResponse(statusCode, code, message)
+}
+
+// describeError maps a use case error to its status code, stable code and
+// client-facing message. Unknown errors are reported as internal errors.
+func describeError(err error) (int, string, string) {
 	switch {
 	case errors.Is(err, hello.ErrNameTooLong):
-		statusCode = 400
-		message = fmt.Sprintf("%s. Maximum %d characters allowed.", err.Error(), hello.MaxNameLength)
+		maxLength := hello.MaxNameLength
+		var tooLong *hello.NameTooLongError
+		if errors.As(err, &tooLong) {
+			maxLength = tooLong.MaxLength
+		}
+
+		return http.StatusBadRequest, CodeNameTooLong,
+			fmt.Sprintf("%s. Maximum %d characters allowed.", err.Error(), maxLength)
 	case errors.Is(err, hello.ErrInvalidCharacters):
-		statusCode = 400
-		message = err.Error() + ". Only letters, numbers, spaces, hyphens, and apostrophes are allowed."
+		return http.StatusBadRequest, CodeInvalidCharacters,
+			err.Error() + ". Only letters, numbers, spaces, hyphens, and apostrophes are allowed."
 	default:
-		statusCode = 500
-		message = "Internal server error"
+		return http.StatusInternalServerError, CodeInternalError, "Internal server error"
 	}
-
-	return errorResponse(statusCode, message)
-}
-
-func errorResponse(statusCode int, message string) (events.APIGatewayProxyResponse, error) {
-	errorBody := map[string]string{
-		"error":  message,
-		"status": fmt.Sprintf("%d", statusCode),
-	}
-
-	body, _ := json.Marshal(errorBody)
-
-	return events.APIGatewayProxyResponse{
-		StatusCode: statusCode,
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		Body: string(body),
-	}, nil
 }
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+)
+
+// HelloHTTPAPIHandler serves AWS API Gateway HTTP API (payload format 2.0)
+// requests for the hello endpoint. It shares the use case and error mapping of
+// HelloHandler so both transports return identical bodies and status codes.
+// Build it once at cold start with NewHelloHTTPAPIHandler so warm invocations
+// reuse its logger and use case.
+//
+// Query Parameters:
+//   - name (optional): The name to include in the greeting. When the raw query
+//     string is present it takes precedence, so repeated keys resolve to the first
+//     value instead of the comma-joined form API Gateway puts in QueryStringParameters.
+//
+// Returns:
+//   - APIGatewayV2HTTPResponse with status 200 and the greeting rendered in the
+//     media type negotiated from the Accept header (see HelloHandler)
+//   - APIGatewayV2HTTPResponse with status 400 if validation fails
+//   - APIGatewayV2HTTPResponse with status 406 if no supported media type is acceptable
+//
+// Example requests:
+//
+//	GET /hello?name=John     -> 200: {"message":"Hello John!","name":"John"}
+//	GET /hello               -> 200: {"message":"Hello world!","name":"world"}
+//	GET /hello?name=<script> -> 400: Validation error
+type HelloHTTPAPIHandler struct {
+	logger services.Logger
+	greet  GreetUseCase
+}
+
+// NewHelloHTTPAPIHandler creates a HelloHTTPAPIHandler logging to
+// loggerService and greeting through greet.
+//
+// Example:
+//
+//	lambda.Start(handlers.NewHelloHTTPAPIHandler(loggerService, hello.Greeter{Rules: rules}.Greet).Handle)
+func NewHelloHTTPAPIHandler(loggerService services.Logger, greet GreetUseCase) *HelloHTTPAPIHandler {
+	return &HelloHTTPAPIHandler{logger: loggerService, greet: greet}
+}
+
+// Handle processes an HTTP API request for the hello endpoint.
+func (h *HelloHTTPAPIHandler) Handle(
+	ctx context.Context,
+	request events.APIGatewayV2HTTPRequest,
+) (events.APIGatewayV2HTTPResponse, error) {
+	if request.RequestContext.RequestID != "" {
+		ctx = context.WithValue(ctx, "request_id", request.RequestContext.RequestID)
+	}
+
+	h.logger.Log(ctx, services.LevelDebug, "Request received",
+		services.Field{Key: "query_params", Value: request.QueryStringParameters},
+		services.Field{Key: "raw_query_string", Value: request.RawQueryString},
+		services.Field{Key: "cookies", Value: cookieNames(request.Cookies)},
+		services.Field{Key: "http_method", Value: request.RequestContext.HTTP.Method},
+		services.Field{Key: "path", Value: request.RawPath},
+	)
+
+	name := rawQueryParameter(request.RawQueryString, request.QueryStringParameters, "name")
+	greeting, err := h.greet(ctx, []string{name})
+	if err != nil {
+		h.logger.Log(ctx, services.LevelWarn, "Validation failed",
+			services.Field{Key: "name", Value: name},
+			services.Field{Key: "error", Value: err.Error()},
+		)
+
+		response, err := mapErrorToResponse(err)
+		return toHTTPAPIResponse(response), err
+	}
+
+	response, err := greetingResponse(lookupHeader(request.Headers, nil, "Accept"), greeting)
+	return toHTTPAPIResponse(response), err
+}
+
+// rawQueryParameter returns the first value of key, preferring the raw query
+// string over the pre-parsed map so repeated parameters are not comma-joined.
+// It serves every payload format 2.0 event (HTTP API and Function URLs).
+func rawQueryParameter(rawQueryString string, queryParameters map[string]string, key string) string {
+	if rawQueryString != "" {
+		if values, err := url.ParseQuery(rawQueryString); err == nil && values.Has(key) {
+			return values.Get(key)
+		}
+	}
+
+	return queryParameters[key]
+}
+
+// cookieNames extracts cookie names from the HTTP API "name=value" cookie list.
+// Values are dropped so session tokens never reach the logs.
+func cookieNames(cookies []string) []string {
+	names := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		name, _, _ := strings.Cut(cookie, "=")
+		names = append(names, strings.TrimSpace(name))
+	}
+
+	return names
+}
+
+func toHTTPAPIResponse(response events.APIGatewayProxyResponse) events.APIGatewayV2HTTPResponse {
+	return events.APIGatewayV2HTTPResponse{
+		StatusCode:        response.StatusCode,
+		Headers:           response.Headers,
+		MultiValueHeaders: response.MultiValueHeaders,
+		Body:              response.Body,
+		IsBase64Encoded:   response.IsBase64Encoded,
+	}
+}
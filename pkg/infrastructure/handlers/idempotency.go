@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/idempotency"
+)
+
+// Defaults of IdempotencyConfig.
+const (
+	DefaultIdempotencyHeader      = "Idempotency-Key"
+	DefaultIdempotencyTTL         = 24 * time.Hour
+	DefaultIdempotencyLockTimeout = 30 * time.Second
+)
+
+// IdempotencyConfig configures the Idempotency middleware.
+type IdempotencyConfig struct {
+	// Store keeps the records.
+	Store idempotency.Store
+	// Header carries the client-chosen key. Defaults to DefaultIdempotencyHeader.
+	Header string
+	// Selector derives the key from the JSON payload when the header is absent,
+	// for clients that cannot send headers but whose payloads carry their own
+	// identifier. Optional.
+	Selector *idempotency.Selector
+	// Methods the middleware applies to. Defaults to POST and PATCH.
+	Methods []string
+	// TTL is how long a completed response is replayed. Defaults to
+	// DefaultIdempotencyTTL.
+	TTL time.Duration
+	// LockTimeout is how long a request stays in progress before a duplicate may
+	// take over, for invocations that crashed or timed out. It should exceed the
+	// function timeout. Defaults to DefaultIdempotencyLockTimeout.
+	LockTimeout time.Duration
+	// Logger reports replays, conflicts and store failures. Optional.
+	Logger services.Logger
+	// Now returns the current time. Defaults to time.Now; tests can override it.
+	Now func() time.Time
+}
+
+// Idempotency makes mutating requests safe to retry. The first request with a
+// given key runs and its response is stored; duplicates within the TTL get the
+// stored response back with "Idempotent-Replayed: true" instead of running
+// again. Keys are scoped to the method, path and authenticated principal.
+//
+// Requests without a key are served normally. A duplicate arriving while the
+// first request still runs gets a 409 IDEMPOTENCY_IN_PROGRESS with
+// Retry-After, and a header key reused with a different payload gets a 422
+// IDEMPOTENCY_KEY_REUSED. Server errors (5xx) are not stored, so the client
+// can retry them.
+//
+// Unlike RateLimit, the middleware fails closed: when the store errors the
+// request gets a 503 SERVICE_UNAVAILABLE rather than risking side effects
+// running twice.
+//
+// Example:
+//
+//	r.Use(handlers.Idempotency(handlers.IdempotencyConfig{
+//	    Store:    idempotency.NewMemoryStore(),
+//	    Selector: idempotency.MustParseSelector("order.id"),
+//	}))
+func Idempotency(config IdempotencyConfig) Middleware {
+	if config.Header == "" {
+		config.Header = DefaultIdempotencyHeader
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultIdempotencyTTL
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = DefaultIdempotencyLockTimeout
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			if !containsFold(config.Methods, request.HTTPMethod) {
+				return next(ctx, request)
+			}
+
+			body, err := requestBody(request)
+			if err != nil {
+				return next(ctx, request)
+			}
+
+			record, ok := config.record(ctx, request, body)
+			if !ok {
+				return next(ctx, request)
+			}
+
+			now := config.Now()
+			record.ExpiresAt = now.Add(config.LockTimeout)
+
+			existing, reserved, err := config.Store.Reserve(ctx, record, now)
+			if err != nil {
+				config.log(ctx, services.LevelError, "Idempotency store failed",
+					services.Field{Key: "error", Value: err.Error()},
+				)
+
+				return CodedErrorResponse(http.StatusServiceUnavailable, CodeServiceUnavailable,
+					"The request could not be processed safely. Retry later.")
+			}
+			if !reserved {
+				return config.duplicate(ctx, existing, record)
+			}
+
+			response, err := next(ctx, request)
+			if err != nil || response.StatusCode >= http.StatusInternalServerError {
+				if releaseErr := config.Store.Release(ctx, record.Key); releaseErr != nil {
+					config.log(ctx, services.LevelError, "Idempotency store failed",
+						services.Field{Key: "error", Value: releaseErr.Error()},
+					)
+				}
+
+				return response, err
+			}
+
+			// The middleware in front of this one edit the headers of the live
+			// response, such as Content-Encoding or CORS, which must not be
+			// stored and replayed to other callers.
+			record.Status = idempotency.StatusCompleted
+			record.Response = &idempotency.Response{
+				StatusCode:      response.StatusCode,
+				Body:            response.Body,
+				IsBase64Encoded: response.IsBase64Encoded,
+			}
+			record.Response.Headers, record.Response.MultiValueHeaders = copyHeaders(response.Headers, response.MultiValueHeaders)
+			record.ExpiresAt = config.Now().Add(config.TTL)
+
+			if err := config.Store.Complete(ctx, record); err != nil {
+				config.log(ctx, services.LevelError, "Idempotency store failed",
+					services.Field{Key: "error", Value: err.Error()},
+				)
+			}
+
+			return response, nil
+		}
+	}
+}
+
+// record builds the in-progress record for request, or returns false when the
+// request carries no key. Header keys are fingerprinted with the payload so
+// reuse with another payload is detected; selector keys come from the payload
+// itself, so other fields may legitimately differ between retries.
+func (config IdempotencyConfig) record(ctx context.Context, request events.APIGatewayProxyRequest, body []byte) (idempotency.Record, bool) {
+	key := strings.TrimSpace(HeaderValue(request, config.Header))
+	fingerprint := ""
+	if key != "" {
+		sum := sha256.Sum256(body)
+		fingerprint = hex.EncodeToString(sum[:])
+		key = "header:" + key
+	} else if config.Selector != nil {
+		selected, ok := config.Selector.Key(body)
+		if !ok {
+			return idempotency.Record{}, false
+		}
+		key = "payload:" + selected
+	} else {
+		return idempotency.Record{}, false
+	}
+
+	subject := ""
+	if principal, ok := entities.PrincipalFromContext(ctx); ok {
+		subject = principal.Subject
+	}
+
+	scope := sha256.Sum256([]byte(strings.Join([]string{request.HTTPMethod, request.Path, subject, key}, "\x00")))
+
+	return idempotency.Record{
+		Key:         hex.EncodeToString(scope[:]),
+		Status:      idempotency.StatusInProgress,
+		Fingerprint: fingerprint,
+	}, true
+}
+
+// duplicate answers a request whose key is already recorded.
+func (config IdempotencyConfig) duplicate(ctx context.Context, existing, record idempotency.Record) (events.APIGatewayProxyResponse, error) {
+	if existing.Fingerprint != record.Fingerprint {
+		config.log(ctx, services.LevelWarn, "Idempotency key reused with a different payload")
+
+		return CodedErrorResponse(http.StatusUnprocessableEntity, CodeIdempotencyKeyReused,
+			"The idempotency key was already used with a different payload.")
+	}
+
+	if existing.Status != idempotency.StatusCompleted || existing.Response == nil {
+		config.log(ctx, services.LevelWarn, "Idempotent request already in progress")
+
+		response, err := CodedErrorResponse(http.StatusConflict, CodeIdempotencyInProgress,
+			"A request with this idempotency key is still in progress.")
+		setHeader(&response, "Retry-After", "1")
+
+		return response, err
+	}
+
+	config.log(ctx, services.LevelInfo, "Idempotent request replayed",
+		services.Field{Key: "status_code", Value: existing.Response.StatusCode},
+	)
+
+	response := events.APIGatewayProxyResponse{
+		StatusCode:      existing.Response.StatusCode,
+		Body:            existing.Response.Body,
+		IsBase64Encoded: existing.Response.IsBase64Encoded,
+	}
+	response.Headers, response.MultiValueHeaders = copyHeaders(existing.Response.Headers, existing.Response.MultiValueHeaders)
+	setHeader(&response, "Idempotent-Replayed", "true")
+
+	return response, nil
+}
+
+func (config IdempotencyConfig) log(ctx context.Context, level services.Level, msg string, fields ...services.Field) {
+	if config.Logger != nil {
+		config.Logger.Log(ctx, level, msg, fields...)
+	}
+}
+
+// copyHeaders returns copies of headers and multiValueHeaders that can be
+// edited without changing the originals.
+func copyHeaders(headers map[string]string, multiValueHeaders map[string][]string) (map[string]string, map[string][]string) {
+	headers = maps.Clone(headers)
+	multiValueHeaders = maps.Clone(multiValueHeaders)
+	for name, values := range multiValueHeaders {
+		multiValueHeaders[name] = slices.Clone(values)
+	}
+
+	return headers, multiValueHeaders
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
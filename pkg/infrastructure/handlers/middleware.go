@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+)
+
+// Middleware wraps a Handler with cross-cutting behaviour such as request
+// correlation, logging or error handling.
+type Middleware func(next Handler) Handler
+
+// Chain wraps handler with middlewares. The first middleware is the outermost
+// one, so it sees the request first and the response last.
+//
+// Example:
+//
+//	handler := handlers.Chain(getUser,
+//	    handlers.RequestID(),
+//	    handlers.RequestLogger(loggerService),
+//	)
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// RequestID stores the API Gateway request id in the context under "request_id",
+// where services.Logger implementations pick it up for log correlation.
+func RequestID() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			if request.RequestContext.RequestID != "" {
+				ctx = context.WithValue(ctx, "request_id", request.RequestContext.RequestID)
+			}
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// RequestLogger logs every incoming request at LevelDebug with its query
+// parameters, HTTP method and path.
+func RequestLogger(loggerService services.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			loggerService.Log(ctx, services.LevelDebug, "Request received",
+				services.Field{Key: "query_params", Value: request.QueryStringParameters},
+				services.Field{Key: "http_method", Value: request.HTTPMethod},
+				services.Field{Key: "path", Value: request.Path},
+			)
+
+			return next(ctx, request)
+		}
+	}
+}
+
+// Recover turns a panic anywhere below it into a 500 response in the
+// ErrorResponse JSON shape, instead of letting the Lambda runtime report an
+// opaque error that API Gateway surfaces as a bodiless 502.
+//
+// The panic value and stack trace are logged at LevelError together with the
+// request id, which is taken from the context or, when RequestID has not run
+// yet, from the request itself.
+func Recover(loggerService services.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (response events.APIGatewayProxyResponse, err error) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+
+				if ctx.Value("request_id") == nil && request.RequestContext.RequestID != "" {
+					ctx = context.WithValue(ctx, "request_id", request.RequestContext.RequestID)
+				}
+
+				loggerService.Log(ctx, services.LevelError, "Panic recovered",
+					services.Field{Key: "panic", Value: fmt.Sprint(recovered)},
+					services.Field{Key: "stack", Value: string(debug.Stack())},
+					services.Field{Key: "http_method", Value: request.HTTPMethod},
+					services.Field{Key: "path", Value: request.Path},
+				)
+
+				response, err = CodedErrorResponse(http.StatusInternalServerError, CodeInternalError, "Internal server error")
+			}()
+
+			return next(ctx, request)
+		}
+	}
+}
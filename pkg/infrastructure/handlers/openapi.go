@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/openapi"
+)
+
+// Names of the component schemas returned by OpenAPIComponents.
+const (
+	SchemaError         = "Error"
+	SchemaProblem       = "Problem"
+	SchemaGreeting      = "Greeting"
+	SchemaBatchResponse = "BatchResponse"
+	SchemaBatchResult   = "BatchResult"
+	SchemaHealth        = "Health"
+)
+
+// SecuritySchemeBearer is the security scheme required by DocumentJWTAuth.
+const SecuritySchemeBearer = "bearerAuth"
+
+// errorCodes lists every stable error code, for the Error schema.
+var errorCodes = []string{
+	CodeNameTooLong, CodeInvalidCharacters, CodeMalformedBody, CodeEmptyBatch,
+	CodeBatchTooLarge, CodeTooManyNames, CodeUnsupportedMediaType, CodeRouteNotFound,
+	CodeMethodNotAllowed, CodeNotAcceptable, CodeCORSOriginNotAllowed, CodeRateLimited,
+	CodeMissingToken, CodeInvalidToken, CodeIdempotencyInProgress, CodeIdempotencyKeyReused,
+	CodeServiceUnavailable, CodeGatewayTimeout, CodeInternalError,
+}
+
+// OpenAPIComponents returns the schemas referenced by the operations of this
+// package and the bearer security scheme.
+func OpenAPIComponents() openapi.Components {
+	str := func(description string) *openapi.Schema {
+		return &openapi.Schema{Type: "string", Description: description}
+	}
+	return openapi.Components{
+		Schemas: map[string]*openapi.Schema{
+			SchemaError: {
+				Type:     "object",
+				Required: []string{"error", "status", "code"},
+				Properties: map[string]*openapi.Schema{
+					"error":  str("Human-readable message"),
+					"status": {Type: "string", Description: "HTTP status code", Examples: []interface{}{"400"}},
+					"code":   {Type: "string", Description: "Stable machine-readable code", Enum: errorCodes},
+				},
+			},
+			SchemaProblem: {
+				Type:        "object",
+				Description: "RFC 7807 problem details, served when the client accepts " + MediaTypeProblemJSON,
+				Required:    []string{"type", "title", "status", "code"},
+				Properties: map[string]*openapi.Schema{
+					"type":       {Type: "string", Format: "uri-reference"},
+					"title":      str("HTTP status text"),
+					"status":     {Type: "integer"},
+					"detail":     str("Human-readable message"),
+					"instance":   str("Request path"),
+					"code":       {Type: "string", Description: "Stable machine-readable code", Enum: errorCodes},
+					"request_id": str("API Gateway request ID"),
+				},
+			},
+			SchemaGreeting: {
+				Type:     "object",
+				Required: []string{"message", "name"},
+				Properties: map[string]*openapi.Schema{
+					"message": {Type: "string", Examples: []interface{}{"Hello Ana and Luis!"}},
+					"name":    str("Everyone greeted, joined as in the message"),
+					"names":   {Type: "array", Items: &openapi.Schema{Type: "string"}, Description: "Everyone greeted, when more than one"},
+				},
+			},
+			SchemaBatchResult: {
+				Type:     "object",
+				Required: []string{"index", "name"},
+				Properties: map[string]*openapi.Schema{
+					"index":   {Type: "integer"},
+					"name":    str("Name as greeted, or as given when it failed"),
+					"message": str("Greeting, when the name is valid"),
+					"error":   str("Validation message, when the name is invalid"),
+					"code":    {Type: "string", Enum: []string{CodeNameTooLong, CodeInvalidCharacters}},
+				},
+			},
+			SchemaBatchResponse: {
+				Type:     "object",
+				Required: []string{"results", "succeeded", "failed"},
+				Properties: map[string]*openapi.Schema{
+					"results":   {Type: "array", Items: openapi.Ref(SchemaBatchResult)},
+					"succeeded": {Type: "integer"},
+					"failed":    {Type: "integer"},
+				},
+			},
+			SchemaHealth: {
+				Type:     "object",
+				Required: []string{"status", "version", "cold_start", "uptime_seconds", "checks"},
+				Properties: map[string]*openapi.Schema{
+					"status":         {Type: "string", Enum: []string{HealthStatusOK, HealthStatusUnavailable}},
+					"version":        str("Build version"),
+					"cold_start":     {Type: "string", Format: "date-time", Description: "When the execution environment started"},
+					"uptime_seconds": {Type: "number", Description: "Seconds since the cold start"},
+					"checks": {
+						Type:        "object",
+						Description: "Outcome of each dependency check, by name",
+						AdditionalProperties: &openapi.Schema{
+							Type:     "object",
+							Required: []string{"status", "duration_ms"},
+							Properties: map[string]*openapi.Schema{
+								"status":      {Type: "string", Enum: []string{HealthStatusOK, HealthStatusFail}},
+								"duration_ms": {Type: "integer"},
+							},
+						},
+					},
+				},
+			},
+		},
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			SecuritySchemeBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	}
+}
+
+// Operation describes the hello endpoint for method (GET or POST), including
+// the validation errors reported by the use case. rules are the rules the
+// handler greets with, so the documented name limits match the served ones.
+//
+// Example:
+//
+//	r.Describe(http.MethodGet, "/hello", helloHandler.Operation(http.MethodGet, cfg.Rules()))
+func (h *HelloHandler) Operation(method string, rules hello.Rules) openapi.Operation {
+	badRequest := []events.APIGatewayProxyResponse{
+		exampleResponse(mapErrorToResponse(&hello.NameTooLongError{MaxLength: rules.NameLengthLimit()})),
+		exampleResponse(mapErrorToResponse(hello.ErrInvalidCharacters)),
+	}
+	if h.config.MaxNames > 0 {
+		badRequest = append(badRequest, exampleResponse(CodedErrorResponse(http.StatusBadRequest, CodeTooManyNames,
+			fmt.Sprintf("request contains %d names. Maximum %d names allowed.", h.config.MaxNames+1, h.config.MaxNames))))
+	}
+
+	operation := openapi.Operation{
+		Tags:        []string{"hello"},
+		Description: "Greets one or more names. Names may contain letters, numbers, spaces, hyphens and apostrophes.",
+		Responses: map[string]openapi.Response{
+			"200": {
+				Description: "Greeting, in the media type negotiated from Accept",
+				Content: map[string]openapi.MediaType{
+					MediaTypeJSON: {Schema: openapi.Ref(SchemaGreeting)},
+					MediaTypeText: {Schema: &openapi.Schema{Type: "string"}},
+					MediaTypeHTML: {Schema: &openapi.Schema{Type: "string"}},
+					MediaTypeXML:  {Schema: &openapi.Schema{Type: "string", Description: "<greeting> document"}},
+				},
+			},
+			"406": OpenAPIErrorResponse("No supported media type is acceptable",
+				exampleResponse(renderGreeting("image/png", greetingBody{}))),
+			"500": OpenAPIErrorResponse("Unexpected failure",
+				exampleResponse(mapErrorToResponse(nil))),
+		},
+	}
+
+	switch method {
+	case http.MethodPost:
+		operation.OperationID = "postHello"
+		operation.Summary = "Greet the names in the body"
+		operation.RequestBody = &openapi.RequestBody{
+			Description: "An empty body greets the default name",
+			Content: map[string]openapi.MediaType{
+				MediaTypeJSON: {Schema: &openapi.Schema{
+					Type:       "object",
+					Properties: map[string]*openapi.Schema{"name": nameSchema(rules, "Name to greet. Empty greets the default name")},
+				}},
+				MediaTypeForm: {Schema: formNamesSchema(rules)},
+			},
+		}
+		badRequest = append(badRequest, bodyErrorExample(MediaTypeJSON, `{"name":`))
+		operation.Responses["415"] = OpenAPIErrorResponse("Unsupported request body media type",
+			bodyErrorExample("text/csv", "John"))
+	default:
+		operation.OperationID = "getHello"
+		operation.Summary = "Greet the names in the query"
+		operation.Parameters = append(operation.Parameters, openapi.Parameter{
+			Name:        "name",
+			In:          openapi.InQuery,
+			Description: "Name to greet; repeat it to greet several names at once. Omitted greets the default name",
+			Schema:      &openapi.Schema{Type: "array", Items: nameSchema(rules, "")},
+			Explode:     openapi.Bool(true),
+		})
+	}
+
+	operation.Responses["400"] = OpenAPIErrorResponse("Invalid names or request body", badRequest...)
+
+	return operation
+}
+
+// HelloBatchOperation describes the batch greeting endpoint served by
+// HelloBatchHandler(config), whose Greet applies rules.
+func HelloBatchOperation(config BatchConfig, rules hello.Rules) openapi.Operation {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = DefaultMaxBatchSize
+	}
+	names := &openapi.Schema{Type: "array", Items: nameSchema(rules, "Name to greet")}
+
+	return openapi.Operation{
+		OperationID: "postHelloBatch",
+		Summary:     "Greet every name of a batch",
+		Description: fmt.Sprintf("Validates each of up to %d names independently, so one invalid name does not fail the batch.",
+			config.MaxBatchSize),
+		Tags: []string{"hello"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				MediaTypeJSON: {Schema: &openapi.Schema{
+					OneOf: []*openapi.Schema{
+						{
+							Type:       "object",
+							Required:   []string{"names"},
+							Properties: map[string]*openapi.Schema{"names": names},
+						},
+						names,
+					},
+				}},
+				MediaTypeForm: {Schema: formNamesSchema(rules)},
+			},
+		},
+		Responses: map[string]openapi.Response{
+			"200": {
+				Description: "Every name was greeted",
+				Content:     map[string]openapi.MediaType{MediaTypeJSON: {Schema: openapi.Ref(SchemaBatchResponse)}},
+			},
+			"207": {
+				Description: "At least one name failed validation",
+				Content:     map[string]openapi.MediaType{MediaTypeJSON: {Schema: openapi.Ref(SchemaBatchResponse)}},
+			},
+			"400": OpenAPIErrorResponse("Empty, oversized or malformed batch",
+				exampleResponse(CodedErrorResponse(http.StatusBadRequest, CodeEmptyBatch, "names must contain at least one name")),
+				exampleResponse(CodedErrorResponse(http.StatusBadRequest, CodeBatchTooLarge,
+					fmt.Sprintf("batch contains %d names. Maximum %d names allowed.", config.MaxBatchSize+1, config.MaxBatchSize))),
+				bodyErrorExample(MediaTypeJSON, `{"name":`),
+			),
+			"415": OpenAPIErrorResponse("Unsupported request body media type",
+				bodyErrorExample("text/csv", "John")),
+		},
+	}
+}
+
+// HealthOperation describes the endpoint served by HealthHandler.
+func HealthOperation() openapi.Operation {
+	health := map[string]openapi.MediaType{MediaTypeJSON: {Schema: openapi.Ref(SchemaHealth)}}
+
+	return openapi.Operation{
+		OperationID: "getHealth",
+		Summary:     "Service health and dependency checks",
+		Tags:        []string{"meta"},
+		Responses: map[string]openapi.Response{
+			"200": {Description: "Every dependency check passed", Content: health},
+			"503": {Description: "At least one dependency check failed or timed out", Content: health},
+		},
+	}
+}
+
+// OpenAPIOperation describes the endpoint served by OpenAPIHandler.
+func OpenAPIOperation() openapi.Operation {
+	return openapi.Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "OpenAPI document of this API",
+		Tags:        []string{"meta"},
+		Responses: map[string]openapi.Response{
+			"200": {
+				Description: "OpenAPI 3.1 document",
+				Content:     map[string]openapi.MediaType{MediaTypeJSON: {Schema: &openapi.Schema{Type: "object"}}},
+			},
+		},
+	}
+}
+
+// OpenAPIHandler serves the OpenAPI document returned by document as JSON.
+// document is called once, on the first request, so it can describe routes
+// registered after the handler itself.
+//
+// Example:
+//
+//	r.Handle(http.MethodGet, "/openapi.json", handlers.OpenAPIHandler(func() *openapi.Document {
+//	    return r.OpenAPI(base)
+//	}))
+func OpenAPIHandler(document func() *openapi.Document) Handler {
+	encode := sync.OnceValues(func() ([]byte, error) {
+		return document().MarshalIndent()
+	})
+
+	return func(_ context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		body, err := encode()
+		if err != nil {
+			return CodedErrorResponse(http.StatusInternalServerError, CodeInternalError, "Internal server error")
+		}
+
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers: map[string]string{
+				"Content-Type": MediaTypeJSON,
+			},
+			Body: string(body),
+		}, nil
+	}
+}
+
+// DocumentJWTAuth adds the bearer requirement and the 401 responses of
+// JWTAuth to operation.
+func DocumentJWTAuth(operation openapi.Operation) openapi.Operation {
+	operation.Security = []map[string][]string{{SecuritySchemeBearer: {}}}
+
+	return operation.WithResponse(http.StatusUnauthorized, OpenAPIErrorResponse("Missing or invalid bearer token",
+		exampleResponse(CodedErrorResponse(http.StatusUnauthorized, CodeMissingToken, "Missing bearer token")),
+		exampleResponse(CodedErrorResponse(http.StatusUnauthorized, CodeInvalidToken, "Invalid bearer token")),
+	))
+}
+
+// DocumentRateLimit adds the 429 response of RateLimit to operation.
+func DocumentRateLimit(operation openapi.Operation) openapi.Operation {
+	response := OpenAPIErrorResponse("Rate limit exceeded",
+		exampleResponse(CodedErrorResponse(http.StatusTooManyRequests, CodeRateLimited, "Too many requests. Retry in 1 seconds.")))
+	response.Headers = map[string]openapi.Header{
+		"Retry-After": {Description: "Seconds until a request is allowed", Schema: &openapi.Schema{Type: "integer"}},
+	}
+
+	return operation.WithResponse(http.StatusTooManyRequests, response)
+}
+
+// DocumentTimeout adds the 504 response of Timeout to operation.
+func DocumentTimeout(operation openapi.Operation) openapi.Operation {
+	return operation.WithResponse(http.StatusGatewayTimeout, OpenAPIErrorResponse("Request timed out",
+		exampleResponse(CodedErrorResponse(http.StatusGatewayTimeout, CodeGatewayTimeout,
+			"The request took too long to process. Retry later."))))
+}
+
+// DocumentIdempotency adds the Idempotency-Key header and the responses of
+// Idempotency to operation.
+func DocumentIdempotency(operation openapi.Operation) openapi.Operation {
+	operation = operation.WithParameter(openapi.Parameter{
+		Name:        "Idempotency-Key",
+		In:          openapi.InHeader,
+		Description: "Makes the request safe to retry: duplicates get the first response back",
+		Schema:      &openapi.Schema{Type: "string"},
+	})
+	operation = operation.WithResponse(http.StatusConflict, OpenAPIErrorResponse("A request with the same key is in progress",
+		exampleResponse(CodedErrorResponse(http.StatusConflict, CodeIdempotencyInProgress,
+			"A request with this idempotency key is still in progress."))))
+	operation = operation.WithResponse(http.StatusUnprocessableEntity, OpenAPIErrorResponse("The key was used with a different payload",
+		exampleResponse(CodedErrorResponse(http.StatusUnprocessableEntity, CodeIdempotencyKeyReused,
+			"The idempotency key was already used with a different payload."))))
+
+	return operation.WithResponse(http.StatusServiceUnavailable, OpenAPIErrorResponse("Idempotency store unavailable",
+		exampleResponse(CodedErrorResponse(http.StatusServiceUnavailable, CodeServiceUnavailable,
+			"The request could not be processed safely. Retry later."))))
+}
+
+// DocumentConditionalGET adds the ETag and Cache-Control headers and the 304
+// response of ConditionalGET to operation.
+func DocumentConditionalGET(operation openapi.Operation) openapi.Operation {
+	operation = operation.WithParameter(openapi.Parameter{
+		Name:        "If-None-Match",
+		In:          openapi.InHeader,
+		Description: "ETag of a cached representation",
+		Schema:      &openapi.Schema{Type: "string"},
+	})
+
+	cacheHeaders := map[string]openapi.Header{
+		"ETag":          {Description: "Strong validator of the representation", Schema: &openapi.Schema{Type: "string"}},
+		"Cache-Control": {Schema: &openapi.Schema{Type: "string"}},
+	}
+	responses := make(map[string]openapi.Response, len(operation.Responses)+1)
+	for status, response := range operation.Responses {
+		responses[status] = response
+	}
+	if ok, found := responses[strconv.Itoa(http.StatusOK)]; found {
+		headers := make(map[string]openapi.Header, len(ok.Headers)+len(cacheHeaders))
+		for name, header := range ok.Headers {
+			headers[name] = header
+		}
+		for name, header := range cacheHeaders {
+			headers[name] = header
+		}
+		ok.Headers = headers
+		responses[strconv.Itoa(http.StatusOK)] = ok
+	}
+	operation.Responses = responses
+
+	return operation.WithResponse(http.StatusNotModified, openapi.Response{
+		Description: "The cached representation is still current",
+		Headers:     cacheHeaders,
+	})
+}
+
+// OpenAPIErrorResponse documents an error response in the ErrorResponse JSON
+// shape, and in the Problem shape for clients that accept
+// application/problem+json. Each of examples, built with CodedErrorResponse,
+// becomes a JSON example named after its code.
+func OpenAPIErrorResponse(description string, examples ...events.APIGatewayProxyResponse) openapi.Response {
+	named := make(map[string]openapi.Example, len(examples))
+	for _, example := range examples {
+		var body errorBody
+		if json.Unmarshal([]byte(example.Body), &body) != nil {
+			continue
+		}
+		named[body.Code] = openapi.Example{Value: body}
+	}
+	if len(named) == 0 {
+		named = nil
+	}
+
+	return openapi.Response{
+		Description: description,
+		Content: map[string]openapi.MediaType{
+			MediaTypeJSON:        {Schema: openapi.Ref(SchemaError), Examples: named},
+			MediaTypeProblemJSON: {Schema: openapi.Ref(SchemaProblem)},
+		},
+	}
+}
+
+// nameSchema describes a name accepted under rules.
+func nameSchema(rules hello.Rules, description string) *openapi.Schema {
+	return &openapi.Schema{Type: "string", Description: description, MaxLength: openapi.Int(rules.NameLengthLimit())}
+}
+
+// formNamesSchema describes a form body of repeated name fields accepted
+// under rules.
+func formNamesSchema(rules hello.Rules) *openapi.Schema {
+	return &openapi.Schema{
+		Type:       "object",
+		Properties: map[string]*openapi.Schema{"name": {Type: "array", Items: nameSchema(rules, "")}},
+	}
+}
+
+// bodyErrorExample returns the error served for a request body of contentType.
+func bodyErrorExample(contentType, body string) events.APIGatewayProxyResponse {
+	_, err := decodeHelloRequest(events.APIGatewayProxyRequest{
+		Headers: map[string]string{"Content-Type": contentType},
+		Body:    body,
+	})
+
+	return exampleResponse(bodyErrorResponse(err))
+}
+
+// exampleResponse discards the always-nil error of the error response
+// builders, so their output can be passed to OpenAPIErrorResponse.
+func exampleResponse(response events.APIGatewayProxyResponse, _ error) events.APIGatewayProxyResponse {
+	return response
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// MediaTypeProblemJSON is the RFC 7807 media type for problem details.
+const MediaTypeProblemJSON = "application/problem+json"
+
+// ErrorFormat selects the body shape used for error responses.
+type ErrorFormat int
+
+const (
+	// ErrorFormatLegacy keeps the {"error", "status", "code"} shape of ErrorResponse.
+	ErrorFormatLegacy ErrorFormat = iota
+	// ErrorFormatProblem renders errors as RFC 7807 application/problem+json.
+	ErrorFormatProblem
+)
+
+// Problem is an RFC 7807 problem details document, extended with a stable
+// machine-readable code and the request id.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ProblemConfig configures the ProblemDetails middleware.
+type ProblemConfig struct {
+	// DefaultFormat is used when the client does not ask for a format.
+	DefaultFormat ErrorFormat
+	// TypeBaseURI prefixes the kebab-cased code to build the problem "type",
+	// e.g. "https://example.com/problems/" -> "https://example.com/problems/name-too-long".
+	// When empty, "about:blank" is used as RFC 7807 recommends.
+	TypeBaseURI string
+}
+
+// ProblemDetails converts error responses produced by ErrorResponse into RFC 7807
+// application/problem+json bodies. Conversion happens when DefaultFormat is
+// ErrorFormatProblem or when the client lists application/problem+json in its
+// Accept header, so both shapes can be served side by side during a migration.
+//
+// Example converted body:
+//
+//	{
+//	  "type": "https://example.com/problems/name-too-long",
+//	  "title": "Bad Request",
+//	  "status": 400,
+//	  "detail": "name exceeds maximum length. Maximum 100 characters allowed.",
+//	  "instance": "/hello",
+//	  "code": "NAME_TOO_LONG",
+//	  "request_id": "c6af9ac6-7b61-11e6-9a41-93e8deadbeef"
+//	}
+func ProblemDetails(config ProblemConfig) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			response, err := next(ctx, request)
+			if err != nil || response.StatusCode < http.StatusBadRequest {
+				return response, err
+			}
+
+			if config.DefaultFormat != ErrorFormatProblem && !acceptsProblemJSON(HeaderValue(request, "Accept")) {
+				return response, nil
+			}
+
+			var legacy errorBody
+			if !strings.HasPrefix(response.Headers["Content-Type"], "application/json") ||
+				json.Unmarshal([]byte(response.Body), &legacy) != nil || legacy.Error == "" {
+				return response, nil
+			}
+
+			problem := Problem{
+				Type:      config.problemType(legacy.Code),
+				Title:     http.StatusText(response.StatusCode),
+				Status:    response.StatusCode,
+				Detail:    legacy.Error,
+				Instance:  request.Path,
+				Code:      legacy.Code,
+				RequestID: request.RequestContext.RequestID,
+			}
+
+			body, _ := json.Marshal(problem)
+			response.Body = string(body)
+			setHeader(&response, "Content-Type", MediaTypeProblemJSON)
+			addVary(&response, "Accept")
+
+			return response, nil
+		}
+	}
+}
+
+func (config ProblemConfig) problemType(code string) string {
+	if config.TypeBaseURI == "" || code == "" {
+		return "about:blank"
+	}
+
+	return config.TypeBaseURI + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
+
+func acceptsProblemJSON(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(mediaType), MediaTypeProblemJSON) {
+			continue
+		}
+
+		key, value, _ := strings.Cut(strings.TrimSpace(params), "=")
+		if strings.EqualFold(strings.TrimSpace(key), "q") {
+			if quality, err := strconv.ParseFloat(value, 64); err == nil && quality == 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/ratelimit"
+)
+
+// RateLimitKeyFunc identifies the client a request is counted against. An
+// empty key means the function cannot identify the client.
+type RateLimitKeyFunc func(ctx context.Context, request events.APIGatewayProxyRequest) string
+
+// RateLimitBySourceIP keys requests by the caller IP reported by API Gateway.
+func RateLimitBySourceIP(_ context.Context, request events.APIGatewayProxyRequest) string {
+	if ip := request.RequestContext.Identity.SourceIP; ip != "" {
+		return "ip:" + ip
+	}
+
+	return ""
+}
+
+// RateLimitByAPIKey keys requests by the API key API Gateway verified for the
+// request. The X-Api-Key header is ignored, since any client can send a new
+// value on every request to get a fresh bucket.
+func RateLimitByAPIKey(_ context.Context, request events.APIGatewayProxyRequest) string {
+	if apiKey := request.RequestContext.Identity.APIKey; apiKey != "" {
+		return "apikey:" + apiKey
+	}
+
+	return ""
+}
+
+// RateLimitByAPIKeyHeader keys requests by the unverified X-Api-Key header. Use
+// it only when something in front of the function rejects unknown keys, e.g.
+// as the key of a custom KeyFunc behind an API key authorizer.
+func RateLimitByAPIKeyHeader(_ context.Context, request events.APIGatewayProxyRequest) string {
+	if apiKey := HeaderValue(request, "X-Api-Key"); apiKey != "" {
+		return "apikey:" + apiKey
+	}
+
+	return ""
+}
+
+// RateLimitByUser keys requests by the authenticated user: the entities.Principal
+// set in the context by JWTAuth, a "user_id" context value, or the principal id
+// of an API Gateway authorizer.
+func RateLimitByUser(ctx context.Context, request events.APIGatewayProxyRequest) string {
+	if principal, ok := entities.PrincipalFromContext(ctx); ok && principal.Subject != "" {
+		return "user:" + principal.Subject
+	}
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	if principalID, ok := request.RequestContext.Authorizer["principalId"].(string); ok && principalID != "" {
+		return "user:" + principalID
+	}
+
+	return ""
+}
+
+// RateLimitKeyChain returns a RateLimitKeyFunc that uses the first non-empty
+// key produced by keyFuncs.
+func RateLimitKeyChain(keyFuncs ...RateLimitKeyFunc) RateLimitKeyFunc {
+	return func(ctx context.Context, request events.APIGatewayProxyRequest) string {
+		for _, keyFunc := range keyFuncs {
+			if key := keyFunc(ctx, request); key != "" {
+				return key
+			}
+		}
+
+		return ""
+	}
+}
+
+// DefaultRateLimit is the limit applied when RateLimitConfig.Limit is not
+// set: 10 requests per second, in bursts of up to 20.
+var DefaultRateLimit = ratelimit.Limit{Requests: 10, Per: time.Second, Burst: 20}
+
+// RateLimitConfig configures the RateLimit middleware.
+type RateLimitConfig struct {
+	// Store keeps the token buckets.
+	Store ratelimit.Store
+	// Limit is the token bucket applied to every client. Defaults to
+	// DefaultRateLimit.
+	Limit ratelimit.Limit
+	// KeyFunc identifies the client. When nil, requests are keyed by user, then
+	// API key, then source IP. Requests without a key are not limited.
+	KeyFunc RateLimitKeyFunc
+	// Logger reports rejected requests and store failures. Optional.
+	Logger services.Logger
+	// Now returns the current time. Defaults to time.Now; tests can override it.
+	Now func() time.Time
+}
+
+// RateLimit limits each client to config.Limit using a token bucket kept in
+// config.Store. Every limited response carries X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (seconds until the bucket is
+// full); requests over the limit get a 429 with Retry-After.
+//
+// The middleware fails open: when the store errors the request is served and
+// the error logged, so a store outage does not take the API down. It panics
+// when config.Limit is invalid (see ratelimit.Limit.Validate), so a bad limit
+// fails the cold start instead of every request.
+//
+// Example:
+//
+//	r.Use(handlers.RateLimit(handlers.RateLimitConfig{
+//	    Store: ratelimit.NewMemoryStore(),
+//	    Limit: ratelimit.Limit{Requests: 10, Per: time.Second, Burst: 20},
+//	}))
+func RateLimit(config RateLimitConfig) Middleware {
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitKeyChain(RateLimitByUser, RateLimitByAPIKey, RateLimitBySourceIP)
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	if config.Limit == (ratelimit.Limit{}) {
+		config.Limit = DefaultRateLimit
+	}
+	if err := config.Limit.Validate(); err != nil {
+		panic("handlers: " + err.Error())
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			key := config.KeyFunc(ctx, request)
+			if key == "" {
+				return next(ctx, request)
+			}
+
+			result, err := config.Store.Take(ctx, key, config.Limit, config.Now())
+			if err != nil {
+				config.log(ctx, services.LevelError, "Rate limit store failed",
+					services.Field{Key: "key", Value: key},
+					services.Field{Key: "error", Value: err.Error()},
+				)
+
+				return next(ctx, request)
+			}
+
+			if !result.Allowed {
+				retryAfter := ceilSeconds(result.RetryAfter)
+				config.log(ctx, services.LevelWarn, "Rate limit exceeded",
+					services.Field{Key: "key", Value: key},
+					services.Field{Key: "retry_after", Value: retryAfter},
+				)
+
+				response, err := CodedErrorResponse(http.StatusTooManyRequests, CodeRateLimited,
+					fmt.Sprintf("Too many requests. Retry in %d seconds.", retryAfter))
+				setHeader(&response, "Retry-After", strconv.Itoa(retryAfter))
+				setRateLimitHeaders(&response, result)
+
+				return response, err
+			}
+
+			response, err := next(ctx, request)
+			if err == nil {
+				setRateLimitHeaders(&response, result)
+			}
+
+			return response, err
+		}
+	}
+}
+
+func (config RateLimitConfig) log(ctx context.Context, level services.Level, msg string, fields ...services.Field) {
+	if config.Logger != nil {
+		config.Logger.Log(ctx, level, msg, fields...)
+	}
+}
+
+func setRateLimitHeaders(response *events.APIGatewayProxyResponse, result ratelimit.Result) {
+	setHeader(response, "X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	setHeader(response, "X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	setHeader(response, "X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+// ceilSeconds rounds d up to whole seconds, as HTTP delay headers require.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// QueryValue returns the first value of the named query string parameter, or ""
+// when it is absent. See QueryValues.
+func QueryValue(request events.APIGatewayProxyRequest, name string) string {
+	return firstValue(QueryValues(request, name))
+}
+
+// QueryValues returns every value of the named query string parameter, in
+// request order. API Gateway puts only the last value of a repeated parameter
+// in QueryStringParameters and all of them in MultiValueQueryStringParameters,
+// so the multi-value map wins and the single-value map is the fallback for
+// events that only populate it:
+//
+//	?name=Ana&name=Luis -> QueryValues(request, "name") = ["Ana", "Luis"]
+//	                       QueryValue(request, "name")  = "Ana"
+func QueryValues(request events.APIGatewayProxyRequest, name string) []string {
+	if values := request.MultiValueQueryStringParameters[name]; len(values) > 0 {
+		return values
+	}
+	if value, ok := request.QueryStringParameters[name]; ok {
+		return []string{value}
+	}
+
+	return nil
+}
+
+// HeaderValue returns the first value of the named request header, or "" when
+// it is absent. See HeaderValues.
+func HeaderValue(request events.APIGatewayProxyRequest, name string) string {
+	return lookupHeader(request.Headers, request.MultiValueHeaders, name)
+}
+
+// HeaderValues returns every value of the named request header. Names are
+// matched case-insensitively, since API Gateway keeps the casing sent by the
+// client, and the maps are merged like QueryValues: the multi-value map wins
+// and the single-value map, which only holds the last value, is the fallback.
+func HeaderValues(request events.APIGatewayProxyRequest, name string) []string {
+	return lookupHeaderValues(request.Headers, request.MultiValueHeaders, name)
+}
+
+// lookupHeader is the transport-agnostic form of HeaderValue, shared by the
+// HTTP API, ALB and Function URL handlers.
+func lookupHeader(headers map[string]string, multiValueHeaders map[string][]string, name string) string {
+	return firstValue(lookupHeaderValues(headers, multiValueHeaders, name))
+}
+
+// lookupHeaderValues is the transport-agnostic form of HeaderValues.
+func lookupHeaderValues(headers map[string]string, multiValueHeaders map[string][]string, name string) []string {
+	for key, values := range multiValueHeaders {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values
+		}
+	}
+
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return []string{value}
+		}
+	}
+
+	return nil
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// setHeader sets a response header, allocating the header map when needed.
+func setHeader(response *events.APIGatewayProxyResponse, name, value string) {
+	if response.Headers == nil {
+		response.Headers = make(map[string]string)
+	}
+
+	response.Headers[name] = value
+}
+
+// addVary appends values to the response Vary header without duplicating entries.
+func addVary(response *events.APIGatewayProxyResponse, values ...string) {
+	var vary []string
+	if current := response.Headers["Vary"]; current != "" {
+		for _, value := range strings.Split(current, ",") {
+			vary = append(vary, strings.TrimSpace(value))
+		}
+	}
+
+	for _, value := range values {
+		found := false
+		for _, existing := range vary {
+			if strings.EqualFold(existing, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			vary = append(vary, http.CanonicalHeaderKey(value))
+		}
+	}
+
+	setHeader(response, "Vary", strings.Join(vary, ", "))
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+)
+
+// DefaultTimeoutSafetyMargin is the time reserved before the invocation
+// deadline when TimeoutConfig.SafetyMargin is not set. It leaves room to
+// write the 504, flush logs and return before the runtime kills the function.
+const DefaultTimeoutSafetyMargin = 500 * time.Millisecond
+
+// TimeoutConfig configures the Timeout middleware.
+type TimeoutConfig struct {
+	// SafetyMargin is reserved before ctx.Deadline(). Zero means
+	// DefaultTimeoutSafetyMargin.
+	SafetyMargin time.Duration
+	// Timeout optionally caps the time a request may take, for example at the
+	// 29 second API Gateway integration timeout when the function timeout is
+	// longer, or when the context has no deadline, as on the local server.
+	Timeout time.Duration
+	// Logger reports timeouts. Optional.
+	Logger services.Logger
+}
+
+// Timeout stops waiting for the wrapped handler shortly before the invocation
+// deadline and answers with a 504 GATEWAY_TIMEOUT in the ErrorResponse JSON
+// shape, instead of letting the runtime kill the function and API Gateway
+// report an opaque error.
+//
+// The handler runs with a context whose deadline is the invocation deadline
+// minus config.SafetyMargin (or config.Timeout, if sooner), so downstream
+// calls that honour ctx are cancelled when the 504 is sent. Requests without
+// any deadline run unbounded. A panic in the handler is re-raised on the
+// calling goroutine, so an outer Recover still catches it.
+//
+// Example:
+//
+//	r.Use(handlers.Timeout(handlers.TimeoutConfig{SafetyMargin: time.Second}))
+func Timeout(config TimeoutConfig) Middleware {
+	if config.SafetyMargin <= 0 {
+		config.SafetyMargin = DefaultTimeoutSafetyMargin
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			deadline, ok := config.deadline(ctx)
+			if !ok {
+				return next(ctx, request)
+			}
+
+			ctx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+
+			type result struct {
+				response  events.APIGatewayProxyResponse
+				err       error
+				recovered interface{}
+			}
+			done := make(chan result, 1)
+
+			go func() {
+				var r result
+				defer func() {
+					r.recovered = recover()
+					done <- r
+				}()
+
+				r.response, r.err = next(ctx, request)
+			}()
+
+			select {
+			case r := <-done:
+				if r.recovered != nil {
+					panic(r.recovered)
+				}
+				return r.response, r.err
+			case <-ctx.Done():
+				if config.Logger != nil {
+					config.Logger.Log(ctx, services.LevelError, "Request timed out",
+						services.Field{Key: "error", Value: ctx.Err().Error()},
+						services.Field{Key: "safety_margin", Value: config.SafetyMargin.String()},
+						services.Field{Key: "http_method", Value: request.HTTPMethod},
+						services.Field{Key: "path", Value: request.Path},
+					)
+				}
+
+				return CodedErrorResponse(http.StatusGatewayTimeout, CodeGatewayTimeout,
+					"The request took too long to process. Retry later.")
+			}
+		}
+	}
+}
+
+// deadline returns the time by which the handler must finish.
+func (config TimeoutConfig) deadline(ctx context.Context) (time.Time, bool) {
+	deadline, ok := ctx.Deadline()
+	if ok {
+		deadline = deadline.Add(-config.SafetyMargin)
+	}
+
+	if config.Timeout > 0 {
+		if limit := time.Now().Add(config.Timeout); !ok || limit.Before(deadline) {
+			return limit, true
+		}
+	}
+
+	return deadline, ok
+}
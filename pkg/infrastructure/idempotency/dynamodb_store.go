@@ -0,0 +1,217 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DefaultMaxAttempts is how many times DynamoDBStore retries a Reserve whose
+// conflicting record disappeared before it could be read, when
+// DynamoDBStoreConfig.MaxAttempts is not set.
+const DefaultMaxAttempts = 5
+
+// ErrContention is returned when a key kept changing between the conditional
+// write and the read of the conflicting record for every attempt.
+var ErrContention = errors.New("idempotency record contention")
+
+// DynamoDBAPI is the subset of the DynamoDB client used by DynamoDBStore.
+// *dynamodb.Client satisfies it.
+type DynamoDBAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// DynamoDBStoreConfig configures a DynamoDBStore.
+type DynamoDBStoreConfig struct {
+	// Table is the table name. Its partition key must be a string attribute named "pk".
+	Table string
+	// MaxAttempts bounds Reserve retries. Zero means DefaultMaxAttempts.
+	MaxAttempts int
+}
+
+// DynamoDBStore keeps records in a DynamoDB table so duplicates are detected
+// across Lambda containers. Reservations are conditional writes, so only one
+// of several concurrent requests with the same key runs. The "expires_at"
+// attribute can be enabled as the table TTL to clean up old records; expired
+// records are ignored whether or not DynamoDB has deleted them yet.
+//
+// Item layout:
+//
+//	pk (S)          idempotency key
+//	status (S)      IN_PROGRESS or COMPLETED
+//	fingerprint (S) payload fingerprint, omitted when unchecked
+//	response (S)    JSON encoded Response, once completed
+//	expires_at (N)  Unix seconds after which the record no longer counts
+//
+// Responses are stored inline, so they must fit in a 400 KB item.
+type DynamoDBStore struct {
+	client DynamoDBAPI
+	config DynamoDBStoreConfig
+}
+
+// NewDynamoDBStore returns a DynamoDBStore using client.
+func NewDynamoDBStore(client DynamoDBAPI, config DynamoDBStoreConfig) *DynamoDBStore {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return &DynamoDBStore{client: client, config: config}
+}
+
+// Reserve implements Store.
+func (s *DynamoDBStore) Reserve(ctx context.Context, record Record, now time.Time) (Record, bool, error) {
+	item, err := recordItem(record)
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	for attempt := 0; attempt < s.config.MaxAttempts; attempt++ {
+		_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName:           aws.String(s.config.Table),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(pk) OR expires_at <= :now"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+			},
+		})
+		if err == nil {
+			return record, true, nil
+		}
+
+		var conditionFailed *types.ConditionalCheckFailedException
+		if !errors.As(err, &conditionFailed) {
+			return Record{}, false, fmt.Errorf("put idempotency record: %w", err)
+		}
+
+		existing, found, err := s.get(ctx, record.Key)
+		if err != nil {
+			return Record{}, false, err
+		}
+		if found && !existing.Expired(now) {
+			return existing, false, nil
+		}
+	}
+
+	return Record{}, false, ErrContention
+}
+
+// Complete implements Store.
+func (s *DynamoDBStore) Complete(ctx context.Context, record Record) error {
+	item, err := recordItem(record)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.config.Table),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("put idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// Release implements Store.
+func (s *DynamoDBStore) Release(ctx context.Context, key string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                aws.String(s.config.Table),
+		Key:                      map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: key}},
+		ConditionExpression:      aws.String("#status = :in_progress"),
+		ExpressionAttributeNames: map[string]string{"#status": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":in_progress": &types.AttributeValueMemberS{Value: string(StatusInProgress)},
+		},
+	})
+
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) {
+		return fmt.Errorf("delete idempotency record: %w", err)
+	}
+
+	return nil
+}
+
+// get reads the record for key.
+func (s *DynamoDBStore) get(ctx context.Context, key string) (Record, bool, error) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.config.Table),
+		Key:            map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: key}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return Record{}, false, fmt.Errorf("get idempotency record: %w", err)
+	}
+	if len(output.Item) == 0 {
+		return Record{}, false, nil
+	}
+
+	record, err := itemRecord(output.Item)
+
+	return record, err == nil, err
+}
+
+func recordItem(record Record) (map[string]types.AttributeValue, error) {
+	item := map[string]types.AttributeValue{
+		"pk":         &types.AttributeValueMemberS{Value: record.Key},
+		"status":     &types.AttributeValueMemberS{Value: string(record.Status)},
+		"expires_at": &types.AttributeValueMemberN{Value: strconv.FormatInt(record.ExpiresAt.Unix(), 10)},
+	}
+	if record.Fingerprint != "" {
+		item["fingerprint"] = &types.AttributeValueMemberS{Value: record.Fingerprint}
+	}
+	if record.Response != nil {
+		response, err := json.Marshal(record.Response)
+		if err != nil {
+			return nil, fmt.Errorf("encode idempotency response: %w", err)
+		}
+		item["response"] = &types.AttributeValueMemberS{Value: string(response)}
+	}
+
+	return item, nil
+}
+
+func itemRecord(item map[string]types.AttributeValue) (Record, error) {
+	record := Record{
+		Key:         stringAttribute(item, "pk"),
+		Status:      Status(stringAttribute(item, "status")),
+		Fingerprint: stringAttribute(item, "fingerprint"),
+	}
+
+	expiresAt, ok := item["expires_at"].(*types.AttributeValueMemberN)
+	if !ok {
+		return Record{}, fmt.Errorf("idempotency record: attribute %q is not a number", "expires_at")
+	}
+	seconds, err := strconv.ParseInt(expiresAt.Value, 10, 64)
+	if err != nil {
+		return Record{}, fmt.Errorf("idempotency record: %w", err)
+	}
+	record.ExpiresAt = time.Unix(seconds, 0)
+
+	if response := stringAttribute(item, "response"); response != "" {
+		record.Response = &Response{}
+		if err := json.Unmarshal([]byte(response), record.Response); err != nil {
+			return Record{}, fmt.Errorf("decode idempotency response: %w", err)
+		}
+	}
+
+	return record, nil
+}
+
+func stringAttribute(item map[string]types.AttributeValue, name string) string {
+	if attribute, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return attribute.Value
+	}
+
+	return ""
+}
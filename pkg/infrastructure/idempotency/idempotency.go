@@ -0,0 +1,64 @@
+// Package idempotency records the outcome of mutating requests under an
+// idempotency key, so a retried request replays the stored response instead of
+// repeating its side effects. Records are kept per Lambda container
+// (MemoryStore) or shared across containers (DynamoDBStore).
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Status is the state of a Record.
+type Status string
+
+// Record states. A record is in progress while the first request runs and
+// completed once its response is stored.
+const (
+	StatusInProgress Status = "IN_PROGRESS"
+	StatusCompleted  Status = "COMPLETED"
+)
+
+// Response is a stored response, replayed for duplicate requests.
+type Response struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers,omitempty"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded,omitempty"`
+}
+
+// Record is the state kept for an idempotency key.
+type Record struct {
+	Key    string
+	Status Status
+	// Fingerprint identifies the request payload, so a key reused with a
+	// different payload can be told apart from a retry. Empty means unchecked.
+	Fingerprint string
+	// Response is set once the record is completed.
+	Response *Response
+	// ExpiresAt is when the record stops counting: for an in-progress record,
+	// when its request is presumed dead and another may take over; for a
+	// completed one, when a duplicate is processed again.
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the record no longer counts at now.
+func (r Record) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// Store keeps idempotency records.
+type Store interface {
+	// Reserve stores record, which should be in progress, unless an unexpired
+	// record exists for record.Key. It returns the stored record and true when
+	// the reservation succeeded, or the existing record and false otherwise.
+	// Concurrent reservations of the same key succeed for at most one caller.
+	Reserve(ctx context.Context, record Record, now time.Time) (Record, bool, error)
+	// Complete replaces the record for record.Key, usually with a completed
+	// record carrying the response.
+	Complete(ctx context.Context, record Record) error
+	// Release deletes the record for key if it is still in progress, so the
+	// request can be retried after a failure.
+	Release(ctx context.Context, key string) error
+}
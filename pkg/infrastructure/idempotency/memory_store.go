@@ -0,0 +1,75 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often MemoryStore drops expired records.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps records in memory. Duplicates are only detected when they
+// reach the same Lambda container; use DynamoDBStore to detect them across
+// containers.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]Record
+	lastSweep time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Reserve implements Store.
+func (s *MemoryStore) Reserve(_ context.Context, record Record, now time.Time) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if existing, ok := s.records[record.Key]; ok && !existing.Expired(now) {
+		return existing, false, nil
+	}
+	s.records[record.Key] = record
+
+	return record, true, nil
+}
+
+// Complete implements Store.
+func (s *MemoryStore) Complete(_ context.Context, record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[record.Key] = record
+
+	return nil
+}
+
+// Release implements Store.
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[key]; ok && existing.Status == StatusInProgress {
+		delete(s.records, key)
+	}
+
+	return nil
+}
+
+// sweep drops expired records. Callers must hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if record.Expired(now) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package idempotency
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Selector picks the values identifying a request out of its JSON payload,
+// using a subset of JMESPath:
+//
+//	order.id                  nested field
+//	"order-id"                quoted field, for names that are not identifiers
+//	items[0].sku, items[-1]   array index, negative from the end
+//	[customer.id, order.id]   multi-select list, combining several values
+//	order.[id, total]         multi-select list after a field
+//
+// Missing fields and out of range indexes select null, as in JMESPath.
+type Selector struct {
+	expression string
+	steps      []step
+}
+
+// step maps the current value to the next one.
+type step func(value interface{}) interface{}
+
+// ParseSelector compiles expression.
+func ParseSelector(expression string) (*Selector, error) {
+	p := &selectorParser{input: expression}
+
+	steps, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	return &Selector{expression: expression, steps: steps}, nil
+}
+
+// MustParseSelector is like ParseSelector but panics on an invalid expression.
+// It simplifies building selectors in package-level configuration.
+func MustParseSelector(expression string) *Selector {
+	selector, err := ParseSelector(expression)
+	if err != nil {
+		panic(err)
+	}
+
+	return selector
+}
+
+// String returns the source expression.
+func (s *Selector) String() string {
+	return s.expression
+}
+
+// Select evaluates the selector against a decoded JSON document.
+func (s *Selector) Select(document interface{}) interface{} {
+	value := document
+	for _, step := range s.steps {
+		value = step(value)
+	}
+
+	return value
+}
+
+// Key evaluates the selector against a JSON payload and returns the selected
+// value JSON encoded. It returns false when nothing is selected: the payload
+// is not JSON, or the selection is null or a list of nulls.
+func (s *Selector) Key(payload []byte) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return "", false
+	}
+
+	value := s.Select(document)
+	if isNull(value) {
+		return "", false
+	}
+
+	key, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+
+	return string(key), true
+}
+
+func isNull(value interface{}) bool {
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if !isNull(item) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return value == nil
+}
+
+// selectorParser is a recursive descent parser over the selector grammar:
+//
+//	expression  = primary { "." ( field | multiselect ) | index }
+//	primary     = field | index | multiselect
+//	index       = "[" integer "]"
+//	multiselect = "[" expression { "," expression } "]"
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) parseExpression() ([]step, error) {
+	p.skipSpace()
+
+	var first step
+	var err error
+	switch {
+	case p.peek() == '[' && p.indexAhead():
+		first, err = p.parseIndex()
+	case p.peek() == '[':
+		first, err = p.parseMultiSelect()
+	default:
+		first, err = p.parseField()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	steps := []step{first}
+	for {
+		p.skipSpace()
+		switch p.peek() {
+		case '.':
+			p.pos++
+			p.skipSpace()
+			var next step
+			if p.peek() == '[' {
+				next, err = p.parseMultiSelect()
+			} else {
+				next, err = p.parseField()
+			}
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, next)
+		case '[':
+			if !p.indexAhead() {
+				return nil, p.errorf("expected index")
+			}
+			next, err := p.parseIndex()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, next)
+		default:
+			return steps, nil
+		}
+	}
+}
+
+func (p *selectorParser) parseField() (step, error) {
+	var name string
+	if p.peek() == '"' {
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] != '"' {
+			if p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.input) {
+			return nil, p.errorf("unterminated quoted field")
+		}
+		if err := json.Unmarshal([]byte(p.input[p.pos:end+1]), &name); err != nil {
+			return nil, p.errorf("invalid quoted field")
+		}
+		p.pos = end + 1
+	} else {
+		start := p.pos
+		for p.pos < len(p.input) && isIdentifierByte(p.input[p.pos], p.pos == start) {
+			p.pos++
+		}
+		if p.pos == start {
+			return nil, p.errorf("expected field name")
+		}
+		name = p.input[start:p.pos]
+	}
+
+	return func(value interface{}) interface{} {
+		object, _ := value.(map[string]interface{})
+		return object[name]
+	}, nil
+}
+
+func (p *selectorParser) parseIndex() (step, error) {
+	p.pos++ // '['
+	p.skipSpace()
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	index, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil {
+		return nil, p.errorf("invalid index")
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return nil, p.errorf("expected ]")
+	}
+	p.pos++
+
+	return func(value interface{}) interface{} {
+		list, _ := value.([]interface{})
+		i := index
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 || i >= len(list) {
+			return nil
+		}
+		return list[i]
+	}, nil
+}
+
+func (p *selectorParser) parseMultiSelect() (step, error) {
+	p.pos++ // '['
+
+	var selectors []*Selector
+	for {
+		steps, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, &Selector{steps: steps})
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return func(value interface{}) interface{} {
+				if value == nil {
+					return nil
+				}
+				values := make([]interface{}, len(selectors))
+				for i, selector := range selectors {
+					values[i] = selector.Select(value)
+				}
+				return values
+			}, nil
+		default:
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+// indexAhead reports whether the "[" at the current position opens an index.
+func (p *selectorParser) indexAhead() bool {
+	next := strings.TrimLeft(p.input[p.pos+1:], " ")
+	return next != "" && (next[0] == '-' || next[0] >= '0' && next[0] <= '9')
+}
+
+func (p *selectorParser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+
+	return 0
+}
+
+func (p *selectorParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("idempotency selector %q: %s at offset %d", p.input, fmt.Sprintf(format, args...), p.pos)
+}
+
+func isIdentifierByte(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
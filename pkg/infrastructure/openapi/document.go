@@ -0,0 +1,206 @@
+// Package openapi models the subset of OpenAPI 3.1 needed to describe the
+// routes of this API, so the contract is written next to the handlers and
+// generated rather than maintained by hand.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strconv"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Parameter locations.
+const (
+	InQuery  = "query"
+	InHeader = "header"
+	InPath   = "path"
+)
+
+// Document is an OpenAPI document. Paths are keyed by path template, e.g.
+// "/hello" or "/users/{id}".
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components,omitempty"`
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL the API is served from.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path, keyed by lower-case HTTP method.
+type PathItem map[string]*Operation
+
+// Operation describes one method of a path.
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	// Security lists the alternative security requirements, each mapping a
+	// security scheme name to its scopes.
+	Security []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a query, header or path parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+	// Explode repeats array parameters as name=a&name=b when true.
+	Explode *bool `json:"explode,omitempty"`
+}
+
+// RequestBody describes the accepted request bodies by media type.
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation, by media type.
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// MediaType is the schema and examples of one media type.
+type MediaType struct {
+	Schema   *Schema            `json:"schema,omitempty"`
+	Examples map[string]Example `json:"examples,omitempty"`
+}
+
+// Example is a named example value.
+type Example struct {
+	Summary string      `json:"summary,omitempty"`
+	Value   interface{} `json:"value"`
+}
+
+// Schema is a JSON Schema (draft 2020-12, as used by OpenAPI 3.1).
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	// AdditionalProperties is the schema of the values of a map-like object.
+	AdditionalProperties *Schema       `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema     `json:"oneOf,omitempty"`
+	Enum                 []string      `json:"enum,omitempty"`
+	Pattern              string        `json:"pattern,omitempty"`
+	MinLength            *int          `json:"minLength,omitempty"`
+	MaxLength            *int          `json:"maxLength,omitempty"`
+	MinItems             *int          `json:"minItems,omitempty"`
+	MaxItems             *int          `json:"maxItems,omitempty"`
+	Examples             []interface{} `json:"examples,omitempty"`
+}
+
+// Components holds the schemas referenced with Ref and the security schemes
+// named by Operation.Security.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how requests authenticate, e.g. an HTTP bearer
+// token: SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}.
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// Ref returns a schema referencing the component schema name.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Int returns a pointer to n, for the optional bounds of a Schema.
+func Int(n int) *int {
+	return &n
+}
+
+// Bool returns a pointer to b, for Parameter.Explode.
+func Bool(b bool) *bool {
+	return &b
+}
+
+// WithResponse returns a copy of o that also documents response under status,
+// unless o already documents that status. It lets middleware-level failures,
+// such as rate limiting, be added to every operation.
+func (o Operation) WithResponse(status int, response Response) Operation {
+	key := strconv.Itoa(status)
+	if _, ok := o.Responses[key]; ok {
+		return o
+	}
+
+	responses := make(map[string]Response, len(o.Responses)+1)
+	for k, v := range o.Responses {
+		responses[k] = v
+	}
+	responses[key] = response
+	o.Responses = responses
+
+	return o
+}
+
+// WithParameter returns a copy of o that also accepts parameter.
+func (o Operation) WithParameter(parameter Parameter) Operation {
+	o.Parameters = append(append([]Parameter(nil), o.Parameters...), parameter)
+
+	return o
+}
+
+// MarshalIndent encodes d as indented JSON, without escaping HTML characters
+// so descriptions and examples stay readable.
+func (d *Document) MarshalIndent() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// WriteFile writes d to path as indented JSON, for client generators.
+func (d *Document) WriteFile(path string) error {
+	data, err := d.MarshalIndent()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DefaultMaxAttempts is how many times DynamoDBStore retries a Take that lost
+// an optimistic-locking race when DynamoDBStoreConfig.MaxAttempts is not set.
+const DefaultMaxAttempts = 5
+
+// ErrContention is returned when a bucket kept changing between read and write
+// for every attempt.
+var ErrContention = errors.New("rate limit bucket contention")
+
+// DynamoDBAPI is the subset of the DynamoDB client used by DynamoDBStore.
+// *dynamodb.Client satisfies it.
+type DynamoDBAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// DynamoDBStoreConfig configures a DynamoDBStore.
+type DynamoDBStoreConfig struct {
+	// Table is the table name. Its partition key must be a string attribute named "pk".
+	Table string
+	// MaxAttempts bounds optimistic-locking retries. Zero means DefaultMaxAttempts.
+	MaxAttempts int
+}
+
+// DynamoDBStore keeps token buckets in a DynamoDB table so every Lambda
+// container shares the same limits. Buckets are updated with optimistic
+// locking on a version attribute, and carry an "expires_at" epoch attribute
+// that can be enabled as the table TTL to clean up idle clients.
+//
+// Item layout:
+//
+//	pk (S)         bucket key, e.g. "ip:203.0.113.7"
+//	tokens (N)     tokens left at updated_at
+//	updated_at (N) last update, Unix nanoseconds
+//	version (N)    incremented on every write
+//	expires_at (N) Unix seconds after which the bucket is full again
+type DynamoDBStore struct {
+	client DynamoDBAPI
+	config DynamoDBStoreConfig
+}
+
+// NewDynamoDBStore returns a DynamoDBStore using client.
+func NewDynamoDBStore(client DynamoDBAPI, config DynamoDBStoreConfig) *DynamoDBStore {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return &DynamoDBStore{client: client, config: config}
+}
+
+// Take implements Store.
+func (s *DynamoDBStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	for attempt := 0; attempt < s.config.MaxAttempts; attempt++ {
+		current, version, err := s.get(ctx, key, limit, now)
+		if err != nil {
+			return Result{}, err
+		}
+
+		next, result := current.take(limit, now)
+
+		err = s.put(ctx, key, next, limit, version)
+		if err == nil {
+			return result, nil
+		}
+
+		var conditionFailed *types.ConditionalCheckFailedException
+		if !errors.As(err, &conditionFailed) {
+			return Result{}, fmt.Errorf("put rate limit bucket: %w", err)
+		}
+	}
+
+	return Result{}, ErrContention
+}
+
+// get reads the bucket for key, returning a full bucket and version 0 when it
+// does not exist yet.
+func (s *DynamoDBStore) get(ctx context.Context, key string, limit Limit, now time.Time) (bucket, int64, error) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.config.Table),
+		Key:            map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: key}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return bucket{}, 0, fmt.Errorf("get rate limit bucket: %w", err)
+	}
+	if len(output.Item) == 0 {
+		return newBucket(limit, now), 0, nil
+	}
+
+	tokens, err := numberAttribute(output.Item, "tokens")
+	if err != nil {
+		return bucket{}, 0, err
+	}
+	updatedAt, err := numberAttribute(output.Item, "updated_at")
+	if err != nil {
+		return bucket{}, 0, err
+	}
+	version, err := numberAttribute(output.Item, "version")
+	if err != nil {
+		return bucket{}, 0, err
+	}
+
+	return bucket{Tokens: tokens, UpdatedAt: time.Unix(0, int64(updatedAt))}, int64(version), nil
+}
+
+// put writes b if the stored version is still version.
+func (s *DynamoDBStore) put(ctx context.Context, key string, b bucket, limit Limit, version int64) error {
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(s.config.Table),
+		Item: map[string]types.AttributeValue{
+			"pk":         &types.AttributeValueMemberS{Value: key},
+			"tokens":     &types.AttributeValueMemberN{Value: strconv.FormatFloat(b.Tokens, 'f', -1, 64)},
+			"updated_at": &types.AttributeValueMemberN{Value: strconv.FormatInt(b.UpdatedAt.UnixNano(), 10)},
+			"version":    &types.AttributeValueMemberN{Value: strconv.FormatInt(version+1, 10)},
+			"expires_at": &types.AttributeValueMemberN{Value: strconv.FormatInt(b.fullAt(limit).Unix()+1, 10)},
+		},
+	}
+
+	if version == 0 {
+		input.ConditionExpression = aws.String("attribute_not_exists(pk)")
+	} else {
+		input.ConditionExpression = aws.String("version = :version")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberN{Value: strconv.FormatInt(version, 10)},
+		}
+	}
+
+	_, err := s.client.PutItem(ctx, input)
+
+	return err
+}
+
+func numberAttribute(item map[string]types.AttributeValue, name string) (float64, error) {
+	attribute, ok := item[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("rate limit bucket: attribute %q is not a number", name)
+	}
+
+	return strconv.ParseFloat(attribute.Value, 64)
+}
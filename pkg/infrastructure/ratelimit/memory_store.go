@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often MemoryStore drops buckets that have refilled.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps token buckets in memory. Limits are enforced per Lambda
+// container, so the effective limit grows with concurrency; use DynamoDBStore
+// to share buckets across containers.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+// memoryBucket is a bucket together with the limit it was last used with, so
+// idle buckets can be swept once they are full again.
+type memoryBucket struct {
+	bucket
+	limit Limit
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]memoryBucket)}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	current, ok := s.buckets[key]
+	if !ok {
+		current = memoryBucket{bucket: newBucket(limit, now)}
+	}
+
+	next, result := current.take(limit, now)
+	s.buckets[key] = memoryBucket{bucket: next, limit: limit}
+
+	return result, nil
+}
+
+// sweep drops buckets that are full at now, since a missing bucket and a full
+// one behave the same. Callers must hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !b.fullAt(b.limit).After(now) {
+			delete(s.buckets, key)
+		}
+	}
+}
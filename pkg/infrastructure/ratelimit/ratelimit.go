@@ -0,0 +1,122 @@
+// Package ratelimit implements token bucket rate limiting over a pluggable
+// state store, so limits can be kept per Lambda container (MemoryStore) or
+// shared across containers (DynamoDBStore).
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInvalidLimit is wrapped by the errors of Limit.Validate.
+var ErrInvalidLimit = errors.New("invalid rate limit")
+
+// Limit describes a token bucket: Requests tokens are added every Per, up to
+// Burst tokens. Each request takes one token.
+//
+// Example:
+//
+//	ratelimit.Limit{Requests: 10, Per: time.Second, Burst: 20} // 10 req/s, bursts of 20
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	// Burst is the bucket capacity. Zero means Requests.
+	Burst int
+}
+
+// Validate reports whether l describes a usable bucket: Requests and Per must
+// be positive and Burst must not be negative.
+func (l Limit) Validate() error {
+	switch {
+	case l.Requests <= 0:
+		return fmt.Errorf("%w: requests must be positive, got %d", ErrInvalidLimit, l.Requests)
+	case l.Per <= 0:
+		return fmt.Errorf("%w: per must be positive, got %s", ErrInvalidLimit, l.Per)
+	case l.Burst < 0:
+		return fmt.Errorf("%w: burst must not be negative, got %d", ErrInvalidLimit, l.Burst)
+	default:
+		return nil
+	}
+}
+
+// capacity returns the maximum number of tokens in the bucket.
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return float64(l.Requests)
+}
+
+// rate returns the number of tokens added per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	// Allowed reports whether a token was available.
+	Allowed bool
+	// Limit is the bucket capacity.
+	Limit int
+	// Remaining is the number of whole tokens left after this request.
+	Remaining int
+	// RetryAfter is how long to wait for the next token when not Allowed.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store keeps token buckets and takes tokens from them atomically.
+type Store interface {
+	// Take refills the bucket identified by key up to now and tries to take one
+	// token from it. It fails with ErrInvalidLimit when limit is not valid.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// bucket is the persisted state of a token bucket.
+type bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// newBucket returns a full bucket for limit.
+func newBucket(limit Limit, now time.Time) bucket {
+	return bucket{Tokens: limit.capacity(), UpdatedAt: now}
+}
+
+// take refills b up to now and tries to take one token, returning the new state.
+func (b bucket) take(limit Limit, now time.Time) (bucket, Result) {
+	capacity := limit.capacity()
+	rate := limit.rate()
+
+	if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+elapsed*rate)
+	}
+	b.UpdatedAt = now
+
+	result := Result{Limit: int(capacity)}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(b.Tokens))
+	result.Reset = seconds((capacity - b.Tokens) / rate)
+
+	return b, result
+}
+
+// fullAt returns when b will have refilled completely.
+func (b bucket) fullAt(limit Limit) time.Time {
+	return b.UpdatedAt.Add(seconds((limit.capacity() - b.Tokens) / limit.rate()))
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
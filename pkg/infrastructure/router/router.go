@@ -0,0 +1,349 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/openapi"
+)
+
+// route is a single registered method and path template.
+type route struct {
+	method    string
+	pattern   string
+	segments  []string
+	handler   handlers.Handler
+	operation *openapi.Operation
+}
+
+// Router dispatches API Gateway requests to handlers registered by HTTP method
+// and path template, so a single Lambda can serve a whole resource family.
+//
+// Path templates use the API Gateway syntax:
+//   - "/hello"               static segments
+//   - "/users/{id}"          a named parameter matching one segment
+//   - "/files/{proxy+}"      a greedy parameter matching the rest of the path
+//
+// Matched parameters are merged into request.PathParameters before the handler
+// runs. Routes are evaluated in registration order and the first match wins, so
+// register static paths before overlapping parameterised ones.
+//
+// Unknown paths return 404 and known paths with an unregistered method return 405
+// with an Allow header, both in the handlers.ErrorResponse JSON shape.
+//
+// Example:
+//
+//	r := router.New()
+//	r.Handle(http.MethodGet, "/hello", helloHandler.Handle)
+//	r.Handle(http.MethodGet, "/users/{id}", getUser)
+//	lambda.Start(r.HandleRequest)
+type Router struct {
+	routes      []route
+	middlewares []handlers.Middleware
+}
+
+// New creates an empty Router.
+func New() *Router {
+	return &Router{}
+}
+
+// Handle registers handler for method and pattern.
+// It panics if the pattern is malformed, mirroring http.ServeMux, because a bad
+// route table is a programming error that must surface at cold start.
+func (r *Router) Handle(method, pattern string, handler handlers.Handler) {
+	segments, err := parsePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+
+	r.routes = append(r.routes, route{
+		method:   strings.ToUpper(method),
+		pattern:  pattern,
+		segments: segments,
+		handler:  handler,
+	})
+}
+
+// Use registers middlewares that wrap the whole dispatch, including the 404 and
+// 405 responses, so concerns like CORS apply to unmatched requests as well.
+// The first middleware is the outermost one, as in handlers.Chain.
+func (r *Router) Use(middlewares ...handlers.Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// HandleRequest dispatches request to the first route matching its path and
+// method, through the middlewares registered with Use. It has the
+// handlers.Handler signature so it can be passed directly to lambda.Start.
+func (r *Router) HandleRequest(
+	ctx context.Context,
+	request events.APIGatewayProxyRequest,
+) (events.APIGatewayProxyResponse, error) {
+	return handlers.Chain(r.dispatch, r.middlewares...)(ctx, request)
+}
+
+func (r *Router) dispatch(
+	ctx context.Context,
+	request events.APIGatewayProxyRequest,
+) (events.APIGatewayProxyResponse, error) {
+	segments := splitPath(request.Path)
+	method := strings.ToUpper(request.HTTPMethod)
+
+	var allowed []string
+	for _, rt := range r.routes {
+		params, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+
+		if rt.method != method {
+			allowed = append(allowed, rt.method)
+			continue
+		}
+
+		if len(params) > 0 {
+			merged := make(map[string]string, len(request.PathParameters)+len(params))
+			for key, value := range request.PathParameters {
+				merged[key] = value
+			}
+			for key, value := range params {
+				merged[key] = value
+			}
+			request.PathParameters = merged
+		}
+
+		return rt.handler(ctx, request)
+	}
+
+	if len(allowed) > 0 {
+		response, err := handlers.CodedErrorResponse(http.StatusMethodNotAllowed, handlers.CodeMethodNotAllowed, "Method not allowed")
+		response.Headers["Allow"] = strings.Join(uniqueSorted(allowed), ", ")
+		return response, err
+	}
+
+	return handlers.CodedErrorResponse(http.StatusNotFound, handlers.CodeRouteNotFound, "Route not found")
+}
+
+// Describe documents the route registered for method and pattern, for the
+// OpenAPI document built by OpenAPI. Like Handle, it panics when no such route
+// is registered, so documentation cannot drift from the route table unnoticed.
+//
+// Example:
+//
+//	r.Handle(http.MethodGet, "/hello", helloHandler.Handle)
+//	r.Describe(http.MethodGet, "/hello", helloHandler.Operation(http.MethodGet, rules))
+func (r *Router) Describe(method, pattern string, operation openapi.Operation) {
+	method = strings.ToUpper(method)
+	for i := range r.routes {
+		if r.routes[i].method == method && r.routes[i].pattern == pattern {
+			r.routes[i].operation = &operation
+			return
+		}
+	}
+
+	panic(fmt.Sprintf("router: cannot describe unregistered route %s %s", method, pattern))
+}
+
+// OpenAPI returns a copy of base documenting every registered route, in the
+// OpenAPI 3.1 format. Routes without a Describe call get a placeholder
+// operation, and path parameters missing from an operation are added, so the
+// document always lists the whole route table. Greedy parameters ("{proxy+}")
+// are written as plain ones, since OpenAPI has no equivalent.
+//
+// Example:
+//
+//	document := r.OpenAPI(openapi.Document{Info: openapi.Info{Title: "Hello API", Version: "1.0.0"}})
+func (r *Router) OpenAPI(base openapi.Document) *openapi.Document {
+	document := base
+	document.OpenAPI = openapi.Version
+	document.Paths = make(map[string]openapi.PathItem, len(r.routes))
+	for path, item := range base.Paths {
+		document.Paths[path] = make(openapi.PathItem, len(item))
+		for method, operation := range item {
+			document.Paths[path][method] = operation
+		}
+	}
+
+	for _, rt := range r.routes {
+		operation := openapi.Operation{
+			Responses: map[string]openapi.Response{"default": {Description: "Undocumented response"}},
+		}
+		if rt.operation != nil {
+			operation = *rt.operation
+		}
+
+		var path strings.Builder
+		for _, segment := range rt.segments {
+			path.WriteString("/")
+			name, _, isParam := paramName(segment)
+			if !isParam {
+				path.WriteString(segment)
+				continue
+			}
+
+			path.WriteString("{" + name + "}")
+			if !hasPathParameter(operation, name) {
+				operation = operation.WithParameter(openapi.Parameter{
+					Name:     name,
+					In:       openapi.InPath,
+					Required: true,
+					Schema:   &openapi.Schema{Type: "string"},
+				})
+			}
+		}
+		if path.Len() == 0 {
+			path.WriteString("/")
+		}
+
+		item := document.Paths[path.String()]
+		if item == nil {
+			item = openapi.PathItem{}
+			document.Paths[path.String()] = item
+		}
+		item[strings.ToLower(rt.method)] = &operation
+	}
+
+	return &document
+}
+
+func hasPathParameter(operation openapi.Operation, name string) bool {
+	for _, parameter := range operation.Parameters {
+		if parameter.In == openapi.InPath && parameter.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Routes returns the registered "METHOD pattern" pairs in registration order.
+func (r *Router) Routes() []string {
+	routes := make([]string, 0, len(r.routes))
+	for _, rt := range r.routes {
+		routes = append(routes, rt.method+" "+rt.pattern)
+	}
+
+	return routes
+}
+
+func (rt route) match(segments []string) (map[string]string, bool) {
+	var params map[string]string
+
+	for i, segment := range rt.segments {
+		name, greedy, isParam := paramName(segment)
+
+		if greedy {
+			if i >= len(segments) {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = strings.Join(segments[i:], "/")
+			return params, true
+		}
+
+		if i >= len(segments) {
+			return nil, false
+		}
+
+		if !isParam {
+			if segment != segments[i] {
+				return nil, false
+			}
+			continue
+		}
+
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[name] = segments[i]
+	}
+
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+func parsePattern(pattern string) ([]string, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("router: pattern %q must start with /", pattern)
+	}
+
+	segments := splitPath(pattern)
+	seen := make(map[string]bool, len(segments))
+
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "{}") && !strings.HasPrefix(segment, "{") {
+			return nil, fmt.Errorf("router: pattern %q mixes text and parameters in one segment", pattern)
+		}
+
+		name, greedy, isParam := paramName(segment)
+		if !isParam {
+			continue
+		}
+
+		if name == "" {
+			return nil, fmt.Errorf("router: pattern %q has an empty parameter name", pattern)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("router: pattern %q repeats parameter %q", pattern, name)
+		}
+		if greedy && i != len(segments)-1 {
+			return nil, fmt.Errorf("router: greedy parameter %q must be the last segment of %q", name, pattern)
+		}
+
+		seen[name] = true
+	}
+
+	return segments, nil
+}
+
+// paramName reports whether segment is a "{name}" or "{name+}" template.
+func paramName(segment string) (name string, greedy bool, ok bool) {
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return "", false, false
+	}
+
+	name = segment[1 : len(segment)-1]
+	if strings.HasSuffix(name, "+") {
+		return strings.TrimSuffix(name, "+"), true, true
+	}
+
+	return name, false, true
+}
+
+// splitPath splits a path into its non-empty segments, so "/hello", "/hello/"
+// and "hello" are equivalent.
+func splitPath(path string) []string {
+	parts := strings.Split(path, "/")
+	segments := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			segments = append(segments, part)
+		}
+	}
+
+	return segments
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+
+	return unique
+}
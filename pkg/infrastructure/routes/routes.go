@@ -0,0 +1,237 @@
+package routes
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/config"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/featureflags"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/idempotency"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/openapi"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/ratelimit"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/router"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
+)
+
+// info describes the API in its OpenAPI document.
+var info = openapi.Info{
+	Title:       "Hello API",
+	Version:     "1.0.0",
+	Description: "Greets people by name, one at a time or in batches.",
+}
+
+// publicPaths are served without bearer authentication.
+var publicPaths = []string{"/health"}
+
+// Config carries the dependencies built once at cold start and shared by every
+// route.
+type Config struct {
+	// Logger is used by the middleware. Defaults to logger.NewLogger().
+	Logger services.Logger
+	// Hello serves GET and POST /hello. Defaults to a HelloHandler using Logger,
+	// hello.Greeter and the default configuration.
+	Hello *handlers.HelloHandler
+	// Rules are the rules Hello and Batch greet with, so the OpenAPI
+	// document reports the name limits they enforce. The zero Rules are the
+	// defaults of hello.Greeter.
+	Rules hello.Rules
+	// HelloCache configures the caching of GET /hello. Defaults to five
+	// minutes for clients and an hour for shared caches. It is made private
+	// and varies on Authorization when bearer authentication is enabled, since
+	// greetings then depend on the caller.
+	HelloCache handlers.CacheConfig
+	// Batch configures POST /hello/batch. Its Logger defaults to Logger; set
+	// its Greet to the use case of Hello so both endpoints greet alike.
+	Batch handlers.BatchConfig
+	// CORS configures cross-origin requests. Defaults to
+	// handlers.DefaultCORSConfig() when no origin is allowed.
+	CORS handlers.CORSConfig
+	// Timeout configures the request timeout. Its Timeout defaults to 29
+	// seconds, the API Gateway integration timeout, and its Logger to Logger.
+	Timeout handlers.TimeoutConfig
+	// Health configures GET /health and its dependency checks. Its Logger
+	// defaults to Logger.
+	Health handlers.HealthConfig
+	// Verifier enables bearer authentication of every route but GET /health.
+	// Nil disables it.
+	Verifier *auth.Verifier
+	// RateLimit is the per-client limit. Defaults to handlers.DefaultRateLimit.
+	RateLimit ratelimit.Limit
+}
+
+// New builds the route table served by the Lambda entry point and the local
+// HTTP server, so both always expose exactly the same endpoints. Every route is
+// described, with the responses of the middleware in front of it, in the
+// OpenAPI document served at GET /openapi.json.
+//
+// Bearer authentication is enabled when config.Verifier is set. GET /health
+// stays public so monitors can poll it without a token.
+func New(config Config) *router.Router {
+	loggerService := config.Logger
+	if loggerService == nil {
+		loggerService = logger.NewLogger()
+	}
+	helloHandler := config.Hello
+	if helloHandler == nil {
+		helloHandler = handlers.NewHelloHandler(loggerService, hello.Greeter{}.Greet, handlers.HelloHandlerConfig{})
+	}
+	if config.Batch.Logger == nil {
+		config.Batch.Logger = loggerService
+	}
+	if len(config.CORS.AllowedOrigins) == 0 {
+		config.CORS = handlers.DefaultCORSConfig()
+	}
+	if config.Timeout.Timeout <= 0 {
+		config.Timeout.Timeout = 29 * time.Second
+	}
+	if config.Timeout.Logger == nil {
+		config.Timeout.Logger = loggerService
+	}
+	if config.Health.Logger == nil {
+		config.Health.Logger = loggerService
+	}
+
+	verifier := config.Verifier
+	if config.HelloCache.MaxAge <= 0 {
+		config.HelloCache = handlers.CacheConfig{MaxAge: 5 * time.Minute, SharedMaxAge: time.Hour}
+	}
+	if verifier != nil {
+		config.HelloCache = privateCache(config.HelloCache)
+	}
+
+	r := router.New()
+	r.Use(
+		handlers.RequestID(),
+		handlers.Recover(loggerService),
+		handlers.RequestLogger(loggerService),
+		handlers.Compress(handlers.DefaultCompressionConfig()),
+		handlers.CORS(config.CORS),
+		handlers.ProblemDetails(handlers.ProblemConfig{DefaultFormat: handlers.ErrorFormatLegacy}),
+		handlers.Timeout(config.Timeout),
+	)
+	if verifier != nil {
+		r.Use(handlers.JWTAuth(handlers.JWTAuthConfig{
+			Verifier:  verifier,
+			Logger:    loggerService,
+			SkipPaths: publicPaths,
+		}))
+	}
+	r.Use(handlers.FlagTargeting())
+	r.Use(handlers.RateLimit(handlers.RateLimitConfig{
+		Store:  ratelimit.NewMemoryStore(),
+		Limit:  config.RateLimit,
+		Logger: loggerService,
+	}))
+	r.Use(handlers.Idempotency(handlers.IdempotencyConfig{
+		Store:  idempotency.NewMemoryStore(),
+		Logger: loggerService,
+	}))
+
+	describe := func(method, pattern string, operation openapi.Operation) {
+		if verifier != nil && !slices.Contains(publicPaths, pattern) {
+			operation = handlers.DocumentJWTAuth(operation)
+		}
+		if method == http.MethodPost || method == http.MethodPatch {
+			operation = handlers.DocumentIdempotency(operation)
+		}
+		r.Describe(method, pattern, handlers.DocumentTimeout(handlers.DocumentRateLimit(operation)))
+	}
+
+	r.Handle(http.MethodGet, "/hello", handlers.Chain(helloHandler.Handle,
+		handlers.ConditionalGET(config.HelloCache),
+	))
+	describe(http.MethodGet, "/hello", handlers.DocumentConditionalGET(helloHandler.Operation(http.MethodGet, config.Rules)))
+	r.Handle(http.MethodPost, "/hello", helloHandler.Handle)
+	describe(http.MethodPost, "/hello", helloHandler.Operation(http.MethodPost, config.Rules))
+	r.Handle(http.MethodPost, "/hello/batch", handlers.HelloBatchHandler(config.Batch))
+	describe(http.MethodPost, "/hello/batch", handlers.HelloBatchOperation(config.Batch, config.Rules))
+	r.Handle(http.MethodGet, "/health", handlers.HealthHandler(config.Health))
+	describe(http.MethodGet, "/health", handlers.HealthOperation())
+	r.Handle(http.MethodGet, "/openapi.json", handlers.OpenAPIHandler(func() *openapi.Document { return OpenAPI(r) }))
+	describe(http.MethodGet, "/openapi.json", handlers.OpenAPIOperation())
+
+	return r
+}
+
+// OpenAPI returns the OpenAPI 3.1 document of the routes registered on r by
+// New, as served at GET /openapi.json.
+func OpenAPI(r *router.Router) *openapi.Document {
+	components := handlers.OpenAPIComponents()
+
+	return r.OpenAPI(openapi.Document{Info: info, Components: &components})
+}
+
+// NewFromConfig builds the route table with the dependencies described by
+// cfg, logging to loggerService. It panics when the feature flag file or the
+// JWKS file cannot be loaded. Remote dependencies, such as the AppConfig feature flag
+// provider, are checked by GET /health.
+func NewFromConfig(cfg config.Config, loggerService services.Logger) *router.Router {
+	flags := featureFlags(cfg.Flags, loggerService)
+	rules := cfg.Rules()
+	greeter := hello.Greeter{Rules: rules, Flags: flags}
+
+	batch := cfg.Batch()
+	batch.Greet = greeter.Greet
+
+	// Flags may serve each caller a different greeting, which shared caches
+	// must not hand to others.
+	var helloCache handlers.CacheConfig
+	if flags != nil {
+		helloCache = privateCache(handlers.CacheConfig{MaxAge: 5 * time.Minute})
+	}
+
+	health := cfg.Health()
+	if checker, ok := flags.(services.HealthChecker); ok {
+		health.Checkers = append(health.Checkers, checker)
+	}
+
+	verifier, err := cfg.Verifier()
+	if err != nil {
+		panic("routes: " + err.Error())
+	}
+
+	return New(Config{
+		Logger:     loggerService,
+		Hello:      handlers.NewHelloHandler(loggerService, greeter.Greet, cfg.HelloHandler()),
+		Rules:      rules,
+		HelloCache: helloCache,
+		Batch:      batch,
+		CORS:       cfg.CORS(),
+		Timeout:    cfg.Timeout(),
+		Health:     health,
+		Verifier:   verifier,
+		RateLimit:  cfg.RateLimit(),
+	})
+}
+
+// privateCache restricts config to the client and varies it on the caller.
+func privateCache(config handlers.CacheConfig) handlers.CacheConfig {
+	config.Private = true
+	config.Vary = append(append([]string(nil), config.Vary...), "Authorization")
+
+	return config
+}
+
+// featureFlags opens the provider selected by cfg, or returns nil when flags
+// are not configured.
+func featureFlags(cfg config.FlagsConfig, loggerService services.Logger) services.FeatureFlags {
+	switch {
+	case cfg.File != "":
+		document, err := featureflags.LoadFile(cfg.File)
+		if err != nil {
+			panic("routes: " + err.Error())
+		}
+		return document
+	case cfg.AppConfig.Application != "":
+		appConfig := cfg.AppConfig
+		appConfig.Logger = loggerService
+		return featureflags.NewAppConfigProvider(appConfig)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+)
+
+// MaxBodyBytes mirrors the API Gateway payload limit (10 MB).
+const MaxBodyBytes = 10 << 20
+
+// LocalStage is the stage name reported in the request context of translated requests.
+const LocalStage = "local"
+
+// HTTPAdapter exposes a handlers.Handler as a plain net/http handler by translating
+// each HTTP request into an events.APIGatewayProxyRequest and the resulting
+// events.APIGatewayProxyResponse back into an HTTP response.
+//
+// It is meant for local development: the real handler chain runs unchanged, without
+// Docker or the Lambda Runtime Interface Emulator.
+//
+// Example:
+//
+//	http.ListenAndServe(":8080", server.NewHTTPAdapter(routes.New(routes.Config{}).HandleRequest, logger.NewLogger()))
+//	// curl "localhost:8080/hello?name=Ana"
+type HTTPAdapter struct {
+	handler handlers.Handler
+	logger  services.Logger
+}
+
+// NewHTTPAdapter creates an HTTPAdapter that forwards every request to handler.
+func NewHTTPAdapter(handler handlers.Handler, logger services.Logger) *HTTPAdapter {
+	return &HTTPAdapter{
+		handler: handler,
+		logger:  logger,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (a *HTTPAdapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request, err := ToProxyRequest(r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeGatewayError(w, http.StatusRequestEntityTooLarge, "Request Too Long")
+			return
+		}
+
+		writeGatewayError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	ctx := r.Context()
+	response, err := a.handler(ctx, request)
+	if err != nil {
+		a.logger.Log(ctx, services.LevelError, "Handler returned an error",
+			services.Field{Key: "error", Value: err.Error()},
+			services.Field{Key: "path", Value: request.Path},
+		)
+
+		// API Gateway answers a failed Lambda invocation with this exact response.
+		writeGatewayError(w, http.StatusBadGateway, "Internal server error")
+		return
+	}
+
+	if err := WriteProxyResponse(w, response); err != nil {
+		a.logger.Log(ctx, services.LevelError, "Failed to write response",
+			services.Field{Key: "error", Value: err.Error()},
+		)
+	}
+}
+
+// ToProxyRequest translates an incoming HTTP request into the event API Gateway
+// would deliver for it through a REST API proxy integration.
+// Bodies that are not valid UTF-8 are base64 encoded, as API Gateway does for
+// binary media types.
+func ToProxyRequest(r *http.Request) (events.APIGatewayProxyRequest, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, MaxBodyBytes))
+	if err != nil {
+		return events.APIGatewayProxyRequest{}, err
+	}
+
+	headers := make(map[string]string, len(r.Header))
+	multiValueHeaders := make(map[string][]string, len(r.Header))
+	for key, values := range r.Header {
+		headers[key] = values[0]
+		multiValueHeaders[key] = values
+	}
+	if r.Host != "" {
+		headers["Host"] = r.Host
+		multiValueHeaders["Host"] = []string{r.Host}
+	}
+
+	query := r.URL.Query()
+	queryParameters := make(map[string]string, len(query))
+	for key, values := range query {
+		queryParameters[key] = values[0]
+	}
+
+	sourceIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		sourceIP = r.RemoteAddr
+	}
+
+	request := events.APIGatewayProxyRequest{
+		Resource:                        r.URL.Path,
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               multiValueHeaders,
+		QueryStringParameters:           queryParameters,
+		MultiValueQueryStringParameters: query,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID:        newRequestID(),
+			Stage:            LocalStage,
+			HTTPMethod:       r.Method,
+			Path:             r.URL.Path,
+			Protocol:         r.Proto,
+			RequestTimeEpoch: time.Now().UnixMilli(),
+			Identity: events.APIGatewayRequestIdentity{
+				SourceIP:  sourceIP,
+				UserAgent: r.UserAgent(),
+			},
+		},
+	}
+
+	if utf8.Valid(body) {
+		request.Body = string(body)
+	} else {
+		request.Body = base64.StdEncoding.EncodeToString(body)
+		request.IsBase64Encoded = true
+	}
+
+	return request, nil
+}
+
+// WriteProxyResponse writes an API Gateway proxy response to w, decoding base64
+// bodies and merging single and multi-value headers.
+func WriteProxyResponse(w http.ResponseWriter, response events.APIGatewayProxyResponse) error {
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(response.Body)
+		if err != nil {
+			writeGatewayError(w, http.StatusBadGateway, "Internal server error")
+			return err
+		}
+		body = decoded
+	}
+
+	for key, values := range response.MultiValueHeaders {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+
+	statusCode := response.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	w.WriteHeader(statusCode)
+	_, err := w.Write(body)
+
+	return err
+}
+
+func writeGatewayError(w http.ResponseWriter, statusCode int, message string) {
+	body, _ := json.Marshal(map[string]string{"message": message})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "local"
+	}
+
+	return hex.EncodeToString(id)
+}
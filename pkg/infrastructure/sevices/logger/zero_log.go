@@ -2,16 +2,56 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/rs/zerolog/pkgerrors"
 
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
 	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
 )
 
-type ZerologLogger struct{}
+// Format selects how log entries are written.
+type Format string
+
+const (
+	// FormatJSON writes one JSON object per line, as CloudWatch Logs Insights expects.
+	FormatJSON Format = "json"
+	// FormatConsole writes colorized, human-readable lines for local development.
+	FormatConsole Format = "console"
+)
+
+// ParseFormat returns the Format named s, case-insensitively.
+func ParseFormat(s string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(s))); format {
+	case FormatJSON, FormatConsole:
+		return format, nil
+	default:
+		return FormatJSON, fmt.Errorf("unknown log format %q", s)
+	}
+}
+
+// Config configures a ZerologLogger built with New.
+type Config struct {
+	// Level is the minimum level written. The zero value, LevelTrace, writes everything.
+	Level services.Level
+	// Format selects JSON or console output. Defaults to FormatJSON.
+	Format Format
+	// Output receives the entries. Defaults to the zerolog global logger, which
+	// writes to stderr.
+	Output io.Writer
+}
+
+type ZerologLogger struct {
+	level  services.Level
+	logger *zerolog.Logger
+}
 
 // NewLogger creates and configures a new ZerologLogger instance.
 // It sets up zerolog with Unix timestamp format and stack trace support.
@@ -35,6 +75,33 @@ func NewLogger() *ZerologLogger {
 	return &ZerologLogger{}
 }
 
+// New creates a ZerologLogger like NewLogger, filtering entries below
+// config.Level and writing them in config.Format. Build it once at cold start
+// and share it.
+//
+// Example:
+//
+//	loggerService := logger.New(logger.Config{Level: services.LevelInfo, Format: logger.FormatConsole})
+func New(config Config) *ZerologLogger {
+	z := NewLogger()
+	z.level = config.Level
+
+	if config.Format == FormatConsole || config.Output != nil {
+		output := config.Output
+		if output == nil {
+			output = os.Stderr
+		}
+		if config.Format == FormatConsole {
+			output = zerolog.ConsoleWriter{Out: output, TimeFormat: time.RFC3339}
+		}
+
+		logger := zerolog.New(output).With().Timestamp().Logger()
+		z.logger = &logger
+	}
+
+	return z
+}
+
 // Log writes a log message at the specified level with optional structured fields.
 // It automatically captures:
 //   - Caller's file and line number for debugging
@@ -46,7 +113,8 @@ func NewLogger() *ZerologLogger {
 //   - "request_id": AWS Lambda request ID or HTTP request ID
 //   - "trace_id": AWS X-Ray trace ID or OpenTelemetry trace ID
 //   - "correlation_id": Correlation ID for request tracking across services
-//   - "user_id": Authenticated user identifier
+//   - "user_id": Authenticated user identifier; the subject of an entities.Principal
+//     stored with entities.ContextWithPrincipal takes precedence
 //
 // Example:
 //
@@ -58,6 +126,10 @@ func NewLogger() *ZerologLogger {
 //
 // This reduces code duplication by consolidating all log levels into a single implementation.
 func (z *ZerologLogger) Log(ctx context.Context, level services.Level, msg string, fields ...services.Field) {
+	if level < z.level {
+		return
+	}
+
 	_, file, line, ok := runtime.Caller(1)
 	if !ok {
 		file = "unknown"
@@ -81,7 +153,9 @@ func (z *ZerologLogger) Log(ctx context.Context, level services.Level, msg strin
 			event = event.Interface("correlation_id", correlationID)
 		}
 
-		if userID := ctx.Value("user_id"); userID != nil {
+		if principal, ok := entities.PrincipalFromContext(ctx); ok && principal.Subject != "" {
+			event = event.Str("user_id", principal.Subject)
+		} else if userID := ctx.Value("user_id"); userID != nil {
 			event = event.Interface("user_id", userID)
 		}
 	}
@@ -94,18 +168,23 @@ func (z *ZerologLogger) Log(ctx context.Context, level services.Level, msg strin
 }
 
 func (z *ZerologLogger) getEventForLevel(level services.Level) *zerolog.Event {
+	logger := &log.Logger
+	if z.logger != nil {
+		logger = z.logger
+	}
+
 	switch level {
 	case services.LevelTrace:
-		return log.Trace()
+		return logger.Trace()
 	case services.LevelDebug:
-		return log.Debug()
+		return logger.Debug()
 	case services.LevelInfo:
-		return log.Info()
+		return logger.Info()
 	case services.LevelWarn:
-		return log.Warn()
+		return logger.Warn()
 	case services.LevelError:
-		return log.Error().Stack()
+		return logger.Error().Stack()
 	default:
-		return log.Info()
+		return logger.Info()
 	}
 }
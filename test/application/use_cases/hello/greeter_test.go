@@ -0,0 +1,118 @@
+package hello
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type GreeterTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	flags   *service.MockFeatureFlags
+	greeter hello.Greeter
+	result  string
+	err     error
+}
+
+func TestGreeterTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(GreeterTestSuite))
+}
+
+func (suite *GreeterTestSuite) SetupTest() {
+	suite.ctx = entities.ContextWithFlagTarget(context.Background(), entities.FlagTarget{Key: "user-42"})
+	suite.flags = new(service.MockFeatureFlags)
+	suite.greeter = hello.Greeter{Flags: suite.flags}
+	suite.result = ""
+	suite.err = nil
+}
+
+func (suite *GreeterTestSuite) givenSalutationVariant(variant string) {
+	suite.flags.On("Variant", suite.ctx, hello.SalutationFlag, entities.FlagTarget{Key: "user-42"}, hello.DefaultSalutation).
+		Return(variant)
+}
+
+func (suite *GreeterTestSuite) whenSayHelloToAllIsCalled(names ...string) {
+	suite.result, suite.err = suite.greeter.SayHelloToAll(suite.ctx, names)
+}
+
+func (suite *GreeterTestSuite) TestWithoutFlags_ShouldMatchRules() {
+	// Given
+	suite.greeter = hello.Greeter{}
+
+	// When
+	suite.whenSayHelloToAllIsCalled("Ana", "Luis")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal("Hello Ana and Luis!", suite.result)
+}
+
+func (suite *GreeterTestSuite) TestSalutationVariant_ShouldBeUsed() {
+	// Given
+	suite.givenSalutationVariant("Hi")
+
+	// When
+	suite.whenSayHelloToAllIsCalled("Ana")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal("Hi Ana!", suite.result)
+	suite.flags.AssertExpectations(suite.T())
+}
+
+func (suite *GreeterTestSuite) TestInvalidSalutationVariant_ShouldBeIgnored() {
+	// Given
+	suite.givenSalutationVariant("<b>Hi</b>")
+
+	// When
+	suite.whenSayHelloToAllIsCalled("Ana")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal("Hello Ana!", suite.result)
+}
+
+func (suite *GreeterTestSuite) TestInvalidName_ShouldStillFail() {
+	// Given
+	suite.givenSalutationVariant("Hi")
+
+	// When
+	suite.whenSayHelloToAllIsCalled("<script>")
+
+	// Then
+	suite.ErrorIs(suite.err, hello.ErrInvalidCharacters)
+	suite.Empty(suite.result)
+}
+
+func (suite *GreeterTestSuite) TestGreet_ShouldUseSalutationVariantAndReportNames() {
+	// Given
+	suite.greeter.Rules = hello.Rules{DefaultName: "friend"}
+	suite.givenSalutationVariant("Hi")
+
+	// When
+	greeting, err := suite.greeter.Greet(suite.ctx, nil)
+
+	// Then
+	suite.NoError(err)
+	suite.Equal(hello.Greeting{Message: "Hi friend!", Names: []string{"friend"}}, greeting)
+}
+
+func (suite *GreeterTestSuite) TestSalutationVariant_LongerThanConfiguredLimit_ShouldBeIgnored() {
+	// Given
+	suite.greeter.Rules = hello.Rules{MaxNameLength: 5}
+	suite.givenSalutationVariant("Greetings")
+
+	// When
+	suite.whenSayHelloToAllIsCalled("Ana")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal("Hello Ana!", suite.result)
+}
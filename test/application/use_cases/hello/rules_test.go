@@ -0,0 +1,160 @@
+package hello
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+)
+
+type RulesTestSuite struct {
+	suite.Suite
+	rules  hello.Rules
+	result string
+	err    error
+}
+
+func TestRulesTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(RulesTestSuite))
+}
+
+func (suite *RulesTestSuite) SetupTest() {
+	suite.rules = hello.Rules{}
+	suite.result = ""
+	suite.err = nil
+}
+
+func (suite *RulesTestSuite) givenRules(defaultName string, maxNameLength int) {
+	suite.rules = hello.Rules{DefaultName: defaultName, MaxNameLength: maxNameLength}
+}
+
+func (suite *RulesTestSuite) whenSayHelloIsCalled(name string) {
+	suite.result, suite.err = suite.rules.SayHello(name)
+}
+
+func (suite *RulesTestSuite) whenSayHelloToAllIsCalled(names ...string) {
+	suite.result, suite.err = suite.rules.SayHelloToAll(names)
+}
+
+func (suite *RulesTestSuite) thenShouldReturnGreeting(expected string) {
+	suite.NoError(suite.err)
+	suite.Equal(expected, suite.result)
+}
+
+func (suite *RulesTestSuite) thenShouldReturnNameTooLong(maxLength int) {
+	suite.True(errors.Is(suite.err, hello.ErrNameTooLong))
+	suite.Equal(hello.ErrNameTooLong.Error(), suite.err.Error())
+
+	var tooLong *hello.NameTooLongError
+	suite.Require().True(errors.As(suite.err, &tooLong))
+	suite.Equal(maxLength, tooLong.MaxLength)
+	suite.Empty(suite.result)
+}
+
+func (suite *RulesTestSuite) TestZeroRules_ShouldMatchSayHelloUseCase() {
+	for _, name := range []string{"", "Ana", "<script>", strings.Repeat("a", hello.MaxNameLength+1)} {
+		// Given
+		expected, expectedErr := hello.SayHelloUseCase(name)
+
+		// When
+		suite.whenSayHelloIsCalled(name)
+
+		// Then
+		suite.Equal(expected, suite.result)
+		suite.Equal(expectedErr == nil, suite.err == nil)
+	}
+}
+
+func (suite *RulesTestSuite) TestDefaultName_ShouldBeGreetedWithoutName() {
+	// Given
+	suite.givenRules("friend", 0)
+
+	// When
+	suite.whenSayHelloToAllIsCalled("", "  ")
+
+	// Then
+	suite.thenShouldReturnGreeting("Hello friend!")
+	suite.Equal("friend", suite.rules.Name(" "))
+	suite.Equal("Ana", suite.rules.Name(" Ana "))
+}
+
+func (suite *RulesTestSuite) TestGreet_ShouldReportWhoWasGreeted() {
+	// Given
+	suite.givenRules("friend", 0)
+
+	// When
+	withoutName, err := suite.rules.Greet([]string{" "})
+	suite.Require().NoError(err)
+	withNames, err := suite.rules.Greet([]string{" Ana", "", "Luis"})
+	suite.Require().NoError(err)
+
+	// Then
+	suite.Equal(hello.Greeting{Message: "Hello friend!", Names: []string{"friend"}}, withoutName)
+	suite.Equal(hello.Greeting{Message: "Hello Ana and Luis!", Names: []string{"Ana", "Luis"}}, withNames)
+}
+
+func (suite *RulesTestSuite) TestGreet_InvalidName_ShouldFail() {
+	// When
+	greeting, err := suite.rules.Greet([]string{"Ana", "<script>"})
+
+	// Then
+	suite.ErrorIs(err, hello.ErrInvalidCharacters)
+	suite.Zero(greeting)
+}
+
+func (suite *RulesTestSuite) TestMaxNameLength_ShouldRejectLongerNames() {
+	// Given
+	suite.givenRules("", 5)
+
+	// When
+	suite.whenSayHelloIsCalled("Joanna")
+
+	// Then
+	suite.thenShouldReturnNameTooLong(5)
+}
+
+func (suite *RulesTestSuite) TestMaxNameLength_ShouldApplyToEveryName() {
+	// Given
+	suite.givenRules("", 5)
+
+	// When
+	suite.whenSayHelloToAllIsCalled("Ana", "Joanna")
+
+	// Then
+	suite.thenShouldReturnNameTooLong(5)
+}
+
+func (suite *RulesTestSuite) TestMaxNameLength_ShouldAllowNamesAtLimit() {
+	// Given
+	suite.givenRules("", 5)
+
+	// When
+	suite.whenSayHelloToAllIsCalled("Ana", "Maria")
+
+	// Then
+	suite.thenShouldReturnGreeting("Hello Ana and Maria!")
+}
+
+func (suite *RulesTestSuite) TestZeroMaxNameLength_ShouldUseDefault() {
+	// Given
+	suite.givenRules("", 0)
+
+	// When
+	suite.whenSayHelloIsCalled(strings.Repeat("a", hello.MaxNameLength+1))
+
+	// Then
+	suite.thenShouldReturnNameTooLong(hello.MaxNameLength)
+}
+
+func (suite *RulesTestSuite) TestNameLengthLimit_ShouldReportLimitInForce() {
+	// Given
+	suite.givenRules("", 5)
+
+	// When / Then
+	suite.Equal(5, suite.rules.NameLengthLimit())
+	suite.Equal(hello.MaxNameLength, hello.Rules{}.NameLengthLimit())
+}
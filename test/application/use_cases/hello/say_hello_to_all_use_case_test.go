@@ -0,0 +1,117 @@
+package hello
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+)
+
+type SayHelloToAllUseCaseTestSuite struct {
+	suite.Suite
+	names  []string
+	result string
+	err    error
+}
+
+func TestSayHelloToAllUseCaseTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(SayHelloToAllUseCaseTestSuite))
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) SetupTest() {
+	suite.names = nil
+	suite.result = ""
+	suite.err = nil
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) givenNames(names ...string) {
+	suite.names = names
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) whenSayHelloToAllUseCaseIsCalled() {
+	suite.result, suite.err = hello.SayHelloToAllUseCase(suite.names)
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) thenShouldReturnGreeting(expected string) {
+	suite.NoError(suite.err)
+	suite.Equal(expected, suite.result)
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) thenShouldReturnError(expectedErr error) {
+	suite.True(errors.Is(suite.err, expectedErr))
+	suite.Empty(suite.result)
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) TestSayHelloToTwoNames() {
+	// Given
+	suite.givenNames("Ana", "Luis")
+
+	// When
+	suite.whenSayHelloToAllUseCaseIsCalled()
+
+	// Then
+	suite.thenShouldReturnGreeting("Hello Ana and Luis!")
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) TestSayHelloToThreeNames() {
+	// Given
+	suite.givenNames("Ana", " Luis ", "Eva")
+
+	// When
+	suite.whenSayHelloToAllUseCaseIsCalled()
+
+	// Then
+	suite.thenShouldReturnGreeting("Hello Ana, Luis and Eva!")
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) TestBlankNamesShouldBeSkipped() {
+	// Given
+	suite.givenNames("", "Ana", "   ")
+
+	// When
+	suite.whenSayHelloToAllUseCaseIsCalled()
+
+	// Then
+	suite.thenShouldReturnGreeting("Hello Ana!")
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) TestNoNamesShouldGreetDefaultName() {
+	// When
+	suite.whenSayHelloToAllUseCaseIsCalled()
+
+	// Then
+	suite.thenShouldReturnGreeting("Hello world!")
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) TestInvalidNameShouldFail() {
+	// Given
+	suite.givenNames("Ana", "<script>")
+
+	// When
+	suite.whenSayHelloToAllUseCaseIsCalled()
+
+	// Then
+	suite.thenShouldReturnError(hello.ErrInvalidCharacters)
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) TestLongNameShouldFail() {
+	// Given
+	suite.givenNames("Ana", strings.Repeat("a", hello.MaxNameLength+1))
+
+	// When
+	suite.whenSayHelloToAllUseCaseIsCalled()
+
+	// Then
+	suite.thenShouldReturnError(hello.ErrNameTooLong)
+}
+
+func (suite *SayHelloToAllUseCaseTestSuite) TestJoinNames() {
+	suite.Equal("", hello.JoinNames(nil))
+	suite.Equal("Ana", hello.JoinNames([]string{"Ana"}))
+	suite.Equal("Ana and Luis", hello.JoinNames([]string{"Ana", "Luis"}))
+	suite.Equal("Ana, Luis and Eva", hello.JoinNames([]string{"Ana", "Luis", "Eva"}))
+}
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+)
+
+type FlagTargetTestSuite struct {
+	suite.Suite
+	ctx context.Context
+}
+
+func TestFlagTargetTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(FlagTargetTestSuite))
+}
+
+func (suite *FlagTargetTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+}
+
+func (suite *FlagTargetTestSuite) TestStoredTarget_ShouldBeReturned() {
+	// Given
+	target := entities.FlagTarget{Key: "user-42", Attributes: map[string]string{"path": "/hello"}}
+	suite.ctx = entities.ContextWithFlagTarget(suite.ctx, target)
+
+	// When
+	stored := entities.FlagTargetFromContext(suite.ctx)
+
+	// Then
+	suite.Equal(target, stored)
+}
+
+func (suite *FlagTargetTestSuite) TestMissingTarget_ShouldReturnZeroValue() {
+	// When
+	stored := entities.FlagTargetFromContext(suite.ctx)
+
+	// Then
+	suite.Equal(entities.FlagTarget{}, stored)
+}
@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+)
+
+type PrincipalTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	principal entities.Principal
+	found     bool
+}
+
+func TestPrincipalTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(PrincipalTestSuite))
+}
+
+func (suite *PrincipalTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+}
+
+func (suite *PrincipalTestSuite) whenPrincipalIsRead() {
+	suite.principal, suite.found = entities.PrincipalFromContext(suite.ctx)
+}
+
+func (suite *PrincipalTestSuite) TestStoredPrincipal_ShouldBeReturned() {
+	// Given
+	suite.ctx = entities.ContextWithPrincipal(suite.ctx, entities.Principal{
+		Subject: "user-42",
+		Claims:  map[string]interface{}{"scope": "greetings:read"},
+	})
+
+	// When
+	suite.whenPrincipalIsRead()
+
+	// Then
+	suite.True(suite.found)
+	suite.Equal("user-42", suite.principal.Subject)
+	suite.Equal("greetings:read", suite.principal.Claims["scope"])
+}
+
+func (suite *PrincipalTestSuite) TestStringKey_ShouldNotCollide() {
+	// Given
+	suite.ctx = context.WithValue(suite.ctx, "principal", entities.Principal{Subject: "spoofed"})
+
+	// When
+	suite.whenPrincipalIsRead()
+
+	// Then
+	suite.False(suite.found)
+}
+
+func (suite *PrincipalTestSuite) TestNilContext_ShouldNotPanic() {
+	// Given
+	suite.ctx = nil
+
+	// When
+	suite.whenPrincipalIsRead()
+
+	// Then
+	suite.False(suite.found)
+}
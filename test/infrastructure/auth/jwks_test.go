@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+)
+
+type JWKSTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	server  *httptest.Server
+	fetches atomic.Int32
+	env     map[string]string
+	keys    auth.KeySet
+	key     interface{}
+	err     error
+}
+
+func TestJWKSTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(JWKSTestSuite))
+}
+
+func (suite *JWKSTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.fetches.Store(0)
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		suite.fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(signer.JWKS())
+	}))
+	suite.env = map[string]string{}
+	suite.err = nil
+}
+
+func (suite *JWKSTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *JWKSTestSuite) givenJWKSFile() string {
+	path := filepath.Join(suite.T().TempDir(), "jwks.json")
+	suite.Require().NoError(os.WriteFile(path, signer.JWKS(), 0o600))
+
+	return path
+}
+
+func (suite *JWKSTestSuite) givenRemoteJWKS(minRefresh time.Duration) {
+	suite.keys = auth.NewRemoteJWKS(auth.RemoteJWKSConfig{URL: suite.server.URL, MinRefreshInterval: minRefresh})
+}
+
+func (suite *JWKSTestSuite) whenKeyIsResolved(kid, alg string) {
+	suite.key, suite.err = suite.keys.VerificationKey(suite.ctx, kid, alg)
+}
+
+func (suite *JWKSTestSuite) getenv(name string) string {
+	return suite.env[name]
+}
+
+func (suite *JWKSTestSuite) TestFile_ShouldLoadSigningKeys() {
+	// Given
+	jwks, err := auth.LoadJWKSFile(suite.givenJWKSFile())
+	suite.Require().NoError(err)
+	suite.keys = jwks
+
+	// When
+	suite.whenKeyIsResolved("rsa-1", auth.AlgorithmRS256)
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(&signer.RSAKey.PublicKey, suite.key)
+}
+
+func (suite *JWKSTestSuite) TestMissingFile_ShouldFail() {
+	// When
+	_, err := auth.LoadJWKSFile(filepath.Join(suite.T().TempDir(), "missing.json"))
+
+	// Then
+	suite.ErrorContains(err, "read JWKS")
+}
+
+func (suite *JWKSTestSuite) TestInvalidECPoint_ShouldFail() {
+	// When
+	_, err := auth.ParseJWKS([]byte(`{"keys":[{"kty":"EC","crv":"P-256","x":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA","y":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}]}`))
+
+	// Then
+	suite.ErrorContains(err, "invalid P-256 point")
+}
+
+func (suite *JWKSTestSuite) TestRemote_ShouldFetchOnceAndCache() {
+	// Given
+	suite.givenRemoteJWKS(time.Hour)
+
+	// When
+	suite.whenKeyIsResolved("ec-1", auth.AlgorithmES256)
+	suite.whenKeyIsResolved("rsa-1", auth.AlgorithmRS256)
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(int32(1), suite.fetches.Load())
+}
+
+func (suite *JWKSTestSuite) TestRemoteUnknownKid_ShouldNotRefetchWithinInterval() {
+	// Given
+	suite.givenRemoteJWKS(time.Hour)
+	suite.whenKeyIsResolved("ec-1", auth.AlgorithmES256)
+
+	// When
+	suite.whenKeyIsResolved("rotated", auth.AlgorithmES256)
+
+	// Then
+	suite.ErrorIs(suite.err, auth.ErrUnknownKey)
+	suite.Equal(int32(1), suite.fetches.Load())
+}
+
+func (suite *JWKSTestSuite) TestRemoteUnknownKid_ShouldRefetchAfterInterval() {
+	// Given
+	suite.givenRemoteJWKS(time.Nanosecond)
+	suite.whenKeyIsResolved("ec-1", auth.AlgorithmES256)
+	time.Sleep(time.Millisecond)
+
+	// When
+	suite.whenKeyIsResolved("rotated", auth.AlgorithmES256)
+
+	// Then
+	suite.ErrorIs(suite.err, auth.ErrUnknownKey)
+	suite.Equal(int32(2), suite.fetches.Load())
+}
+
+func (suite *JWKSTestSuite) TestRemoteFailure_ShouldReturnError() {
+	// Given
+	suite.server.Close()
+	suite.givenRemoteJWKS(time.Hour)
+
+	// When
+	suite.whenKeyIsResolved("ec-1", auth.AlgorithmES256)
+
+	// Then
+	suite.ErrorContains(suite.err, "fetch JWKS")
+}
+
+func (suite *JWKSTestSuite) TestEnvWithoutKeySource_ShouldDisableAuth() {
+	// When
+	verifier, err := auth.VerifierFromEnv(suite.getenv)
+
+	// Then
+	suite.NoError(err)
+	suite.Nil(verifier)
+}
+
+func (suite *JWKSTestSuite) TestEnvWithJWKSURL_ShouldVerifyTokens() {
+	// Given
+	suite.env[auth.EnvJWKSURL] = suite.server.URL
+	suite.env[auth.EnvAudience] = "greetings-api"
+	verifier, err := auth.VerifierFromEnv(suite.getenv)
+	suite.Require().NoError(err)
+
+	// When
+	claims, err := verifier.Verify(suite.ctx, signer.Sign("RS256", "rsa-1", map[string]interface{}{
+		"sub": "user-42",
+		"aud": "greetings-api",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}))
+
+	// Then
+	suite.NoError(err)
+	suite.Equal("user-42", claims.Subject())
+}
+
+func (suite *JWKSTestSuite) TestEnvWithSecret_ShouldVerifyHS256() {
+	// Given
+	suite.env[auth.EnvHS256Secret] = string(signer.Secret)
+	verifier, err := auth.VerifierFromEnv(suite.getenv)
+	suite.Require().NoError(err)
+
+	// When
+	claims, err := verifier.Verify(suite.ctx, signer.Sign("HS256", "", map[string]interface{}{
+		"sub": "user-42",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}))
+
+	// Then
+	suite.NoError(err)
+	suite.Equal("user-42", claims.Subject())
+}
+
+func (suite *JWKSTestSuite) TestEnvWithSeveralKeySources_ShouldFail() {
+	// Given
+	suite.env[auth.EnvJWKSFile] = suite.givenJWKSFile()
+	suite.env[auth.EnvHS256Secret] = "secret"
+
+	// When
+	_, err := auth.VerifierFromEnv(suite.getenv)
+
+	// Then
+	suite.ErrorContains(err, "set only one of")
+}
@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+var signer = service.NewTokenSigner()
+
+type VerifierTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	now    time.Time
+	config auth.VerifierConfig
+	claims map[string]interface{}
+	token  string
+	result auth.Claims
+	err    error
+}
+
+func TestVerifierTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(VerifierTestSuite))
+}
+
+func (suite *VerifierTestSuite) SetupTest() {
+	jwks, err := auth.ParseJWKS(signer.JWKS())
+	suite.Require().NoError(err)
+
+	suite.ctx = context.Background()
+	suite.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	suite.config = auth.VerifierConfig{
+		Keys:     jwks,
+		Issuer:   "https://issuer.example.com",
+		Audience: "greetings-api",
+		Now:      func() time.Time { return suite.now },
+	}
+	suite.claims = map[string]interface{}{
+		"sub":   "user-42",
+		"iss":   "https://issuer.example.com",
+		"aud":   []string{"other-api", "greetings-api"},
+		"exp":   suite.now.Add(time.Hour).Unix(),
+		"scope": "greetings:read",
+	}
+	suite.err = nil
+}
+
+func (suite *VerifierTestSuite) givenClaim(name string, value interface{}) {
+	suite.claims[name] = value
+}
+
+func (suite *VerifierTestSuite) givenNoClaim(name string) {
+	delete(suite.claims, name)
+}
+
+func (suite *VerifierTestSuite) givenTokenSignedWith(alg, kid string) {
+	suite.token = signer.Sign(alg, kid, suite.claims)
+}
+
+func (suite *VerifierTestSuite) whenVerifyIsCalled() {
+	suite.result, suite.err = auth.NewVerifier(suite.config).Verify(suite.ctx, suite.token)
+}
+
+func (suite *VerifierTestSuite) thenTokenShouldBeValid() {
+	suite.Require().NoError(suite.err)
+	suite.Equal("user-42", suite.result.Subject())
+	suite.Equal("greetings:read", suite.result["scope"])
+}
+
+func (suite *VerifierTestSuite) thenErrorShouldBe(target error) {
+	suite.ErrorIs(suite.err, target)
+	suite.Nil(suite.result)
+}
+
+func (suite *VerifierTestSuite) TestHS256_ShouldVerify() {
+	// Given
+	suite.givenTokenSignedWith("HS256", "hmac-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenTokenShouldBeValid()
+}
+
+func (suite *VerifierTestSuite) TestRS256_ShouldVerify() {
+	// Given
+	suite.givenTokenSignedWith("RS256", "rsa-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenTokenShouldBeValid()
+}
+
+func (suite *VerifierTestSuite) TestES256_ShouldVerify() {
+	// Given
+	suite.givenTokenSignedWith("ES256", "ec-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenTokenShouldBeValid()
+}
+
+func (suite *VerifierTestSuite) TestSingleKeyWithoutKid_ShouldVerify() {
+	// Given
+	suite.givenTokenSignedWith("ES256", "")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenTokenShouldBeValid()
+}
+
+func (suite *VerifierTestSuite) TestTamperedClaims_ShouldFailSignature() {
+	// Given
+	suite.givenTokenSignedWith("RS256", "rsa-1")
+	parts := strings.Split(suite.token, ".")
+	suite.givenClaim("sub", "admin")
+	forged := strings.Split(signer.Sign("RS256", "rsa-1", suite.claims), ".")
+	suite.token = parts[0] + "." + forged[1] + "." + parts[2]
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrInvalidSignature)
+}
+
+func (suite *VerifierTestSuite) TestNoneAlgorithm_ShouldBeRejected() {
+	// Given
+	suite.givenTokenSignedWith("none", "")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrUnsupportedAlgorithm)
+}
+
+func (suite *VerifierTestSuite) TestAlgorithmConfusion_ShouldBeRejected() {
+	// Given
+	suite.givenTokenSignedWith("HS256", "rsa-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrUnknownKey)
+}
+
+func (suite *VerifierTestSuite) TestUnknownKid_ShouldBeRejected() {
+	// Given
+	suite.givenTokenSignedWith("RS256", "rotated-away")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrUnknownKey)
+}
+
+func (suite *VerifierTestSuite) TestExpiredToken_ShouldBeRejected() {
+	// Given
+	suite.givenClaim("exp", suite.now.Add(-time.Second).Unix())
+	suite.givenTokenSignedWith("HS256", "hmac-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrTokenExpired)
+}
+
+func (suite *VerifierTestSuite) TestLeeway_ShouldTolerateClockSkew() {
+	// Given
+	suite.config.Leeway = time.Minute
+	suite.givenClaim("exp", suite.now.Add(-30*time.Second).Unix())
+	suite.givenTokenSignedWith("HS256", "hmac-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenTokenShouldBeValid()
+}
+
+func (suite *VerifierTestSuite) TestMissingExpiry_ShouldBeRejected() {
+	// Given
+	suite.givenNoClaim("exp")
+	suite.givenTokenSignedWith("HS256", "hmac-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrMissingClaim)
+}
+
+func (suite *VerifierTestSuite) TestNotBefore_ShouldBeHonoured() {
+	// Given
+	suite.givenClaim("nbf", suite.now.Add(time.Minute).Unix())
+	suite.givenTokenSignedWith("HS256", "hmac-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrTokenNotYetValid)
+}
+
+func (suite *VerifierTestSuite) TestWrongIssuer_ShouldBeRejected() {
+	// Given
+	suite.givenClaim("iss", "https://evil.example.com")
+	suite.givenTokenSignedWith("ES256", "ec-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrInvalidIssuer)
+}
+
+func (suite *VerifierTestSuite) TestWrongAudience_ShouldBeRejected() {
+	// Given
+	suite.givenClaim("aud", "other-api")
+	suite.givenTokenSignedWith("ES256", "ec-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrInvalidAudience)
+}
+
+func (suite *VerifierTestSuite) TestMissingSubject_ShouldBeRejected() {
+	// Given
+	suite.givenNoClaim("sub")
+	suite.givenTokenSignedWith("HS256", "hmac-1")
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrMissingClaim)
+}
+
+func (suite *VerifierTestSuite) TestGarbage_ShouldBeMalformed() {
+	// Given
+	suite.token = "not-a-token"
+
+	// When
+	suite.whenVerifyIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(auth.ErrMalformedToken)
+}
@@ -0,0 +1,33 @@
+package buildinfo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/buildinfo"
+)
+
+type BuildInfoTestSuite struct {
+	suite.Suite
+}
+
+func TestBuildInfoTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(BuildInfoTestSuite))
+}
+
+func (suite *BuildInfoTestSuite) TestVersion_ShouldNeverBeEmpty() {
+	// When
+	version := buildinfo.Version()
+
+	// Then
+	suite.NotEmpty(version)
+}
+
+func (suite *BuildInfoTestSuite) TestColdStart_ShouldBeSetWhenLoaded() {
+	// Then
+	suite.False(buildinfo.ColdStart.IsZero())
+	suite.False(buildinfo.ColdStart.After(time.Now()))
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/config"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/featureflags"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/ratelimit"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+	variables map[string]string
+	config    config.Config
+	err       error
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+func (suite *ConfigTestSuite) SetupTest() {
+	suite.variables = map[string]string{}
+	suite.config = config.Config{}
+	suite.err = nil
+}
+
+func (suite *ConfigTestSuite) givenVariable(key, value string) {
+	suite.variables[key] = value
+}
+
+func (suite *ConfigTestSuite) whenConfigIsLoaded() {
+	suite.config, suite.err = config.Load(func(key string) string { return suite.variables[key] })
+}
+
+func (suite *ConfigTestSuite) thenProblemsShouldBe(problems ...string) {
+	var validationErr *config.ValidationError
+	suite.Require().True(errors.As(suite.err, &validationErr))
+
+	reported := make([]string, len(validationErr.Problems))
+	for i, problem := range validationErr.Problems {
+		reported[i] = problem.Error()
+	}
+	suite.Equal(problems, reported)
+}
+
+func (suite *ConfigTestSuite) TestEmptyEnvironment_ShouldLoadDefaults() {
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.Require().NoError(suite.err)
+	suite.Equal(config.HelloConfig{
+		DefaultName:   hello.DefaultName,
+		MaxNameLength: hello.MaxNameLength,
+		MaxNames:      0,
+		MaxBatchSize:  handlers.DefaultMaxBatchSize,
+	}, suite.config.Hello)
+	suite.Equal(config.LogConfig{Level: services.LevelInfo, Format: logger.FormatJSON}, suite.config.Log)
+	suite.Equal(config.HTTPConfig{
+		RequestTimeout:      config.DefaultRequestTimeout,
+		TimeoutSafetyMargin: handlers.DefaultTimeoutSafetyMargin,
+		AllowedOrigins:      []string{"*"},
+		HealthCheckTimeout:  handlers.DefaultHealthCheckTimeout,
+		RateLimit:           handlers.DefaultRateLimit,
+	}, suite.config.HTTP)
+	suite.Equal(handlers.DefaultCORSConfig(), suite.config.CORS())
+	suite.Equal(auth.Settings{}, suite.config.Auth)
+}
+
+func (suite *ConfigTestSuite) TestVariables_ShouldFeedEveryComponent() {
+	// Given
+	suite.givenVariable(config.EnvHelloDefaultName, "friend")
+	suite.givenVariable(config.EnvHelloMaxNameLength, "20")
+	suite.givenVariable(config.EnvHelloMaxNames, "5")
+	suite.givenVariable(config.EnvHelloMaxBatchSize, "50")
+	suite.givenVariable(config.EnvLogLevel, "WARN")
+	suite.givenVariable(config.EnvLogFormat, "console")
+	suite.givenVariable(config.EnvRequestTimeout, "10s")
+	suite.givenVariable(config.EnvTimeoutSafetyMargin, "1s")
+	suite.givenVariable(config.EnvCORSAllowedOrigins, "https://a.example.com, https://*.example.org")
+	suite.givenVariable(config.EnvHealthCheckTimeout, "750ms")
+	suite.givenVariable(config.EnvRateLimitRequests, "100")
+	suite.givenVariable(config.EnvRateLimitPer, "1m")
+	suite.givenVariable(config.EnvRateLimitBurst, "0")
+	suite.givenVariable(config.EnvJWTHS256Secret, "secret")
+	suite.givenVariable(config.EnvJWTAudience, "greetings-api")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.Require().NoError(suite.err)
+	suite.Equal(hello.Rules{DefaultName: "friend", MaxNameLength: 20}, suite.config.Rules())
+	suite.Equal(handlers.HelloHandlerConfig{MaxNames: 5}, suite.config.HelloHandler())
+	suite.Equal(50, suite.config.Batch().MaxBatchSize)
+	suite.Equal(logger.Config{Level: services.LevelWarn, Format: logger.FormatConsole}, suite.config.Logger())
+	suite.Equal(handlers.TimeoutConfig{SafetyMargin: time.Second, Timeout: 10 * time.Second}, suite.config.Timeout())
+	suite.Equal([]string{"https://a.example.com", "https://*.example.org"}, suite.config.CORS().AllowedOrigins)
+	suite.Equal(handlers.HealthConfig{Timeout: 750 * time.Millisecond}, suite.config.Health())
+	suite.Equal(ratelimit.Limit{Requests: 100, Per: time.Minute}, suite.config.RateLimit())
+	suite.Equal(auth.Settings{HS256Secret: "secret", Audience: "greetings-api"}, suite.config.Auth)
+
+	verifier, err := suite.config.Verifier()
+	suite.NoError(err)
+	suite.NotNil(verifier)
+}
+
+func (suite *ConfigTestSuite) TestEmptyEnvironment_ShouldDisableAuth() {
+	// When
+	suite.whenConfigIsLoaded()
+	verifier, err := suite.config.Verifier()
+
+	// Then
+	suite.NoError(err)
+	suite.Nil(verifier)
+}
+
+func (suite *ConfigTestSuite) TestInvalidRateLimit_ShouldBeRejected() {
+	// Given
+	suite.givenVariable(config.EnvRateLimitRequests, "0")
+	suite.givenVariable(config.EnvRateLimitPer, "0s")
+	suite.givenVariable(config.EnvRateLimitBurst, "-1")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe(
+		"RATE_LIMIT_REQUESTS must be positive, got 0",
+		"RATE_LIMIT_PER must be positive, got 0s",
+		"RATE_LIMIT_BURST must not be negative, got -1",
+	)
+}
+
+func (suite *ConfigTestSuite) TestSeveralJWTKeySources_ShouldBeRejected() {
+	// Given
+	suite.givenVariable(config.EnvJWTJWKSURL, "https://issuer.example.com/.well-known/jwks.json")
+	suite.givenVariable(config.EnvJWTHS256Secret, "secret")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe("JWT_HS256_SECRET must not be set together with JWT_JWKS_URL")
+}
+
+func (suite *ConfigTestSuite) TestInvalidVariables_ShouldBeAggregated() {
+	// Given
+	suite.givenVariable(config.EnvHelloMaxNameLength, "long")
+	suite.givenVariable(config.EnvHelloMaxNames, "-1")
+	suite.givenVariable(config.EnvHelloMaxBatchSize, "0")
+	suite.givenVariable(config.EnvLogLevel, "verbose")
+	suite.givenVariable(config.EnvLogFormat, "xml")
+	suite.givenVariable(config.EnvRequestTimeout, "soon")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe(
+		`HELLO_MAX_NAME_LENGTH must be an integer, got "long"`,
+		`REQUEST_TIMEOUT must be a duration such as 500ms or 30s, got "soon"`,
+		`LOG_LEVEL must be trace, debug, info, warn or error, got "verbose"`,
+		`LOG_FORMAT must be json or console, got "xml"`,
+		`HELLO_MAX_NAMES must not be negative, got -1`,
+		`HELLO_MAX_BATCH_SIZE must be positive, got 0`,
+	)
+	suite.ErrorContains(suite.err, "invalid configuration: ")
+}
+
+func (suite *ConfigTestSuite) TestDefaultName_ShouldFollowNameRules() {
+	// Given
+	suite.givenVariable(config.EnvHelloDefaultName, "<team>")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe("HELLO_DEFAULT_NAME must be a valid name: name contains invalid characters")
+}
+
+func (suite *ConfigTestSuite) TestDefaultName_ShouldFitMaxNameLength() {
+	// Given
+	suite.givenVariable(config.EnvHelloDefaultName, "everybody")
+	suite.givenVariable(config.EnvHelloMaxNameLength, "5")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe("HELLO_DEFAULT_NAME must be a valid name: name exceeds maximum length")
+}
+
+func (suite *ConfigTestSuite) TestSafetyMargin_ShouldBeShorterThanTimeout() {
+	// Given
+	suite.givenVariable(config.EnvRequestTimeout, "1s")
+	suite.givenVariable(config.EnvTimeoutSafetyMargin, "2s")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe("TIMEOUT_SAFETY_MARGIN must be shorter than REQUEST_TIMEOUT (1s), got 2s")
+}
+
+func (suite *ConfigTestSuite) TestNonPositiveDurations_ShouldBeRejected() {
+	// Given
+	suite.givenVariable(config.EnvRequestTimeout, "0s")
+	suite.givenVariable(config.EnvTimeoutSafetyMargin, "-1s")
+	suite.givenVariable(config.EnvHealthCheckTimeout, "0s")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe(
+		"REQUEST_TIMEOUT must be positive, got 0s",
+		"TIMEOUT_SAFETY_MARGIN must be positive, got -1s",
+		"HEALTH_CHECK_TIMEOUT must be positive, got 0s",
+	)
+}
+
+func (suite *ConfigTestSuite) TestAppConfigVariables_ShouldSelectAppConfigProvider() {
+	// Given
+	suite.givenVariable(config.EnvAppConfigApplication, "hello-api")
+	suite.givenVariable(config.EnvAppConfigEnvironment, "prod")
+	suite.givenVariable(config.EnvAppConfigProfile, "flags")
+	suite.givenVariable(config.EnvFeatureFlagsRefreshInterval, "1m")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.Require().NoError(suite.err)
+	suite.Equal(config.FlagsConfig{AppConfig: featureflags.AppConfigConfig{
+		URL:             featureflags.DefaultAppConfigURL,
+		Application:     "hello-api",
+		Environment:     "prod",
+		Profile:         "flags",
+		RefreshInterval: time.Minute,
+	}}, suite.config.Flags)
+}
+
+func (suite *ConfigTestSuite) TestPartialAppConfigVariables_ShouldBeRejected() {
+	// Given
+	suite.givenVariable(config.EnvAppConfigApplication, "hello-api")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe(
+		"APPCONFIG_ENVIRONMENT is required",
+		"APPCONFIG_PROFILE is required",
+	)
+}
+
+func (suite *ConfigTestSuite) TestFlagsFileAndAppConfig_ShouldBeExclusive() {
+	// Given
+	suite.givenVariable(config.EnvFeatureFlagsFile, "flags.yaml")
+	suite.givenVariable(config.EnvAppConfigApplication, "hello-api")
+	suite.givenVariable(config.EnvAppConfigEnvironment, "prod")
+	suite.givenVariable(config.EnvAppConfigProfile, "flags")
+	suite.givenVariable(config.EnvFeatureFlagsRefreshInterval, "0s")
+
+	// When
+	suite.whenConfigIsLoaded()
+
+	// Then
+	suite.thenProblemsShouldBe(
+		"FEATURE_FLAGS_FILE must not be set together with APPCONFIG_APPLICATION",
+		"FEATURE_FLAGS_REFRESH_INTERVAL must be positive, got 0s",
+	)
+}
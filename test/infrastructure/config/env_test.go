@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/config"
+)
+
+type EnvTestSuite struct {
+	suite.Suite
+	variables map[string]string
+	env       *config.Env
+}
+
+func TestEnvTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(EnvTestSuite))
+}
+
+func (suite *EnvTestSuite) SetupTest() {
+	suite.variables = map[string]string{}
+	suite.env = config.NewEnv(func(key string) string { return suite.variables[key] })
+}
+
+func (suite *EnvTestSuite) givenVariable(key, value string) {
+	suite.variables[key] = value
+}
+
+func (suite *EnvTestSuite) thenProblemsShouldBe(problems ...string) {
+	err := suite.env.Err()
+	if len(problems) == 0 {
+		suite.NoError(err)
+		return
+	}
+
+	var validationErr *config.ValidationError
+	suite.Require().True(errors.As(err, &validationErr))
+	suite.Require().Len(validationErr.Problems, len(problems))
+	for i, problem := range problems {
+		suite.Equal(problem, validationErr.Problems[i].Error())
+	}
+}
+
+func (suite *EnvTestSuite) TestUnsetVariables_ShouldUseDefaults() {
+	// Given
+	suite.givenVariable("BLANK", "   ")
+
+	// When / Then
+	suite.Equal("def", suite.env.String("BLANK", "def"))
+	suite.Equal(7, suite.env.Int("INT", 7))
+	suite.True(suite.env.Bool("BOOL", true))
+	suite.Equal(time.Second, suite.env.Duration("DURATION", time.Second))
+	suite.Equal([]string{"a"}, suite.env.List("LIST", []string{"a"}))
+	suite.thenProblemsShouldBe()
+}
+
+func (suite *EnvTestSuite) TestTypedVariables_ShouldBeParsed() {
+	// Given
+	suite.givenVariable("STRING", "  value ")
+	suite.givenVariable("INT", "42")
+	suite.givenVariable("BOOL", "false")
+	suite.givenVariable("DURATION", "1m30s")
+	suite.givenVariable("LIST", " a, ,b ,c")
+
+	// When / Then
+	suite.Equal("value", suite.env.String("STRING", ""))
+	suite.Equal(42, suite.env.Int("INT", 0))
+	suite.False(suite.env.Bool("BOOL", true))
+	suite.Equal(90*time.Second, suite.env.Duration("DURATION", 0))
+	suite.Equal([]string{"a", "b", "c"}, suite.env.List("LIST", nil))
+	suite.thenProblemsShouldBe()
+}
+
+func (suite *EnvTestSuite) TestMalformedVariables_ShouldBeReportedTogether() {
+	// Given
+	suite.givenVariable("INT", "ten")
+	suite.givenVariable("BOOL", "maybe")
+	suite.givenVariable("DURATION", "5")
+
+	// When
+	intValue := suite.env.Int("INT", 10)
+	boolValue := suite.env.Bool("BOOL", true)
+	durationValue := suite.env.Duration("DURATION", time.Second)
+
+	// Then
+	suite.Equal(10, intValue)
+	suite.True(boolValue)
+	suite.Equal(time.Second, durationValue)
+	suite.thenProblemsShouldBe(
+		`INT must be an integer, got "ten"`,
+		`BOOL must be a boolean, got "maybe"`,
+		`DURATION must be a duration such as 500ms or 30s, got "5"`,
+	)
+	suite.EqualError(suite.env.Err(), `invalid configuration: INT must be an integer, got "ten"; `+
+		`BOOL must be a boolean, got "maybe"; DURATION must be a duration such as 500ms or 30s, got "5"`)
+}
+
+func (suite *EnvTestSuite) TestRequire_ShouldReportMissingKeys() {
+	// Given
+	suite.givenVariable("PRESENT", "yes")
+	suite.givenVariable("BLANK", " ")
+
+	// When
+	suite.env.Require("PRESENT", "BLANK", "ABSENT")
+
+	// Then
+	suite.thenProblemsShouldBe("BLANK is required", "ABSENT is required")
+	suite.True(errors.Is(suite.env.Err(), config.ErrMissing))
+}
+
+func (suite *EnvTestSuite) TestInvalid_ShouldRecordCustomProblem() {
+	// When
+	suite.env.Invalid("PORT", "must be below %d", 65536)
+
+	// Then
+	suite.thenProblemsShouldBe("PORT must be below 65536")
+}
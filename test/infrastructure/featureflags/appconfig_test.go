@@ -0,0 +1,192 @@
+package featureflags
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/featureflags"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+// appConfigStandIn mimics the AppConfig Lambda extension, serving whatever
+// configuration was deployed last.
+type appConfigStandIn struct {
+	mu          sync.Mutex
+	contentType string
+	body        string
+	status      int
+	paths       []string
+}
+
+func (s *appConfigStandIn) deploy(contentType, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.contentType, s.body, s.status = contentType, body, http.StatusOK
+}
+
+func (s *appConfigStandIn) fail(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *appConfigStandIn) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.paths)
+}
+
+func (s *appConfigStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paths = append(s.paths, r.URL.EscapedPath())
+	if s.status != http.StatusOK {
+		w.WriteHeader(s.status)
+		return
+	}
+	w.Header().Set("Content-Type", s.contentType)
+	_, _ = w.Write([]byte(s.body))
+}
+
+type AppConfigProviderTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	standIn  *appConfigStandIn
+	server   *httptest.Server
+	logger   *service.MockLogger
+	now      time.Time
+	provider *featureflags.AppConfigProvider
+}
+
+func TestAppConfigProviderTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(AppConfigProviderTestSuite))
+}
+
+func (suite *AppConfigProviderTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.standIn = &appConfigStandIn{}
+	suite.standIn.deploy("application/json", `{"flags":{"emoji":{"enabled":true}}}`)
+	suite.server = httptest.NewServer(suite.standIn)
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.now = time.Unix(1700000000, 0)
+	suite.provider = featureflags.NewAppConfigProvider(featureflags.AppConfigConfig{
+		URL:             suite.server.URL,
+		Application:     "hello api",
+		Environment:     "prod",
+		Profile:         "flags",
+		RefreshInterval: time.Minute,
+		Logger:          suite.logger,
+		Now:             func() time.Time { return suite.now },
+	})
+}
+
+func (suite *AppConfigProviderTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+func (suite *AppConfigProviderTestSuite) whenEmojiIsEvaluated() bool {
+	return suite.provider.Bool(suite.ctx, "emoji", entities.FlagTarget{Key: "user-1"}, false)
+}
+
+func (suite *AppConfigProviderTestSuite) TestEvaluation_ShouldFetchFromExtension() {
+	// When
+	enabled := suite.whenEmojiIsEvaluated()
+
+	// Then
+	suite.True(enabled)
+	suite.Equal([]string{"/applications/hello%20api/environments/prod/configurations/flags"}, suite.standIn.paths)
+}
+
+func (suite *AppConfigProviderTestSuite) TestDocument_ShouldBeCachedUntilRefreshInterval() {
+	// Given
+	suite.whenEmojiIsEvaluated()
+	suite.standIn.deploy("application/json", `{"flags":{"emoji":{"enabled":false}}}`)
+
+	// When
+	cached := suite.whenEmojiIsEvaluated()
+	suite.now = suite.now.Add(time.Minute)
+	refreshed := suite.whenEmojiIsEvaluated()
+
+	// Then
+	suite.True(cached)
+	suite.False(refreshed)
+	suite.Equal(2, suite.standIn.requests())
+}
+
+func (suite *AppConfigProviderTestSuite) TestYAMLConfiguration_ShouldBeParsed() {
+	// Given
+	suite.standIn.deploy("application/x-yaml", "flags:\n  emoji:\n    enabled: true\n")
+
+	// When / Then
+	suite.True(suite.whenEmojiIsEvaluated())
+}
+
+func (suite *AppConfigProviderTestSuite) TestFailedRefresh_ShouldKeepLastDocument() {
+	// Given
+	suite.whenEmojiIsEvaluated()
+	suite.standIn.fail(http.StatusInternalServerError)
+	suite.now = suite.now.Add(time.Minute)
+
+	// When
+	enabled := suite.whenEmojiIsEvaluated()
+	suite.whenEmojiIsEvaluated()
+
+	// Then
+	suite.True(enabled)
+	suite.Equal(2, suite.standIn.requests(), "a failed fetch waits for the refresh interval too")
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelWarn, "Feature flags refresh failed", mock.Anything)
+}
+
+func (suite *AppConfigProviderTestSuite) TestUnavailableExtension_ShouldServeDefaults() {
+	// Given
+	suite.standIn.fail(http.StatusNotFound)
+
+	// When / Then
+	suite.False(suite.whenEmojiIsEvaluated())
+	suite.Equal("Hey", suite.provider.Variant(suite.ctx, "emoji", entities.FlagTarget{}, "Hey"))
+}
+
+func (suite *AppConfigProviderTestSuite) TestInvalidConfiguration_ShouldBeRejected() {
+	// Given
+	suite.standIn.deploy("application/json", `{"flags":{"emoji":{"enabled":true,"rollout":200}}}`)
+
+	// When
+	err := suite.provider.Refresh(suite.ctx)
+
+	// Then
+	suite.ErrorContains(err, "rollout must be between 0 and 100")
+	suite.False(suite.whenEmojiIsEvaluated())
+}
+
+func (suite *AppConfigProviderTestSuite) TestHealthCheck_ShouldReportUnavailableExtension() {
+	// Given
+	suite.whenEmojiIsEvaluated()
+	suite.standIn.fail(http.StatusServiceUnavailable)
+
+	// When
+	err := suite.provider.Check(suite.ctx)
+
+	// Then
+	suite.Error(err)
+	suite.Equal("feature_flags", suite.provider.Name())
+	suite.True(suite.whenEmojiIsEvaluated(), "a failed check keeps the cached flags")
+}
+
+func (suite *AppConfigProviderTestSuite) TestHealthCheck_ShouldPassWhenExtensionServesFlags() {
+	// When
+	err := suite.provider.Check(suite.ctx)
+
+	// Then
+	suite.NoError(err)
+}
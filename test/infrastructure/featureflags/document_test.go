@@ -0,0 +1,258 @@
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/featureflags"
+)
+
+const documentJSON = `{
+  "flags": {
+    "emoji": {"enabled": true},
+    "disabled": {"enabled": false, "rules": [{"attribute": "user_id", "operator": "in", "values": ["ana"], "serve": "on"}]},
+    "quarter": {"enabled": true, "rollout": 25},
+    "beta": {
+      "enabled": true,
+      "rollout": 0,
+      "rules": [
+        {"attribute": "user_id", "operator": "in", "values": ["ana", "luis"], "serve": "on"},
+        {"attribute": "path", "operator": "starts_with", "values": ["/internal"], "serve": "true"}
+      ]
+    },
+    "salutation": {
+      "enabled": true,
+      "variants": [{"name": "Hello", "weight": 3}, {"name": "Hi", "weight": 1}],
+      "rules": [{"attribute": "user_id", "operator": "ends_with", "values": ["@example.com"], "serve": "Hi"}]
+    },
+    "salutation-off": {"enabled": false, "variants": [{"name": "Hi", "weight": 1}]}
+  }
+}`
+
+const documentYAML = `
+flags:
+  emoji:
+    enabled: true
+  disabled:
+    enabled: false
+    rules:
+      - {attribute: user_id, operator: in, values: [ana], serve: "on"}
+  quarter:
+    enabled: true
+    rollout: 25
+  beta:
+    enabled: true
+    rollout: 0
+    rules:
+      - attribute: user_id
+        operator: in
+        values: [ana, luis]
+        serve: "on"
+      - attribute: path
+        operator: starts_with
+        values: [/internal]
+        serve: "true"
+  salutation:
+    enabled: true
+    variants:
+      - {name: Hello, weight: 3}
+      - {name: Hi, weight: 1}
+    rules:
+      - {attribute: user_id, operator: ends_with, values: ["@example.com"], serve: Hi}
+  salutation-off:
+    enabled: false
+    variants:
+      - {name: Hi, weight: 1}
+`
+
+type DocumentTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	document *featureflags.Document
+	err      error
+}
+
+func TestDocumentTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(DocumentTestSuite))
+}
+
+func (suite *DocumentTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.document, suite.err = featureflags.Parse([]byte(documentJSON), featureflags.FormatJSON)
+	suite.Require().NoError(suite.err)
+}
+
+func (suite *DocumentTestSuite) whenDocumentIsParsed(data string, format featureflags.Format) {
+	suite.document, suite.err = featureflags.Parse([]byte(data), format)
+}
+
+func (suite *DocumentTestSuite) target(key string, attributes ...string) entities.FlagTarget {
+	target := entities.FlagTarget{Key: key, Attributes: map[string]string{}}
+	for i := 0; i+1 < len(attributes); i += 2 {
+		target.Attributes[attributes[i]] = attributes[i+1]
+	}
+
+	return target
+}
+
+// share returns the fraction of n distinct keys for which the flag is on.
+func (suite *DocumentTestSuite) share(flag string, n int) float64 {
+	on := 0
+	for i := 0; i < n; i++ {
+		if suite.document.Bool(suite.ctx, flag, suite.target(fmt.Sprintf("user-%d", i)), false) {
+			on++
+		}
+	}
+
+	return float64(on) / float64(n)
+}
+
+func (suite *DocumentTestSuite) TestBooleanFlags_ShouldFollowEnabled() {
+	// When / Then
+	suite.True(suite.document.Bool(suite.ctx, "emoji", suite.target("a"), false))
+	suite.False(suite.document.Bool(suite.ctx, "disabled", suite.target("a", "user_id", "ana"), true),
+		"a disabled flag ignores its rules")
+	suite.True(suite.document.Bool(suite.ctx, "unknown", suite.target("a"), true))
+	suite.False(suite.document.Bool(suite.ctx, "unknown", suite.target("a"), false))
+}
+
+func (suite *DocumentTestSuite) TestRollout_ShouldServeShareOfTargets() {
+	// When
+	share := suite.share("quarter", 4000)
+
+	// Then
+	suite.InDelta(0.25, share, 0.03)
+}
+
+func (suite *DocumentTestSuite) TestRollout_ShouldBeStickyPerKey() {
+	for i := 0; i < 50; i++ {
+		// Given
+		target := suite.target(fmt.Sprintf("user-%d", i))
+
+		// When
+		first := suite.document.Bool(suite.ctx, "quarter", target, false)
+
+		// Then
+		suite.Equal(first, suite.document.Bool(suite.ctx, "quarter", target, false))
+	}
+}
+
+func (suite *DocumentTestSuite) TestRules_ShouldTargetAttributes() {
+	// When / Then
+	suite.True(suite.document.Bool(suite.ctx, "beta", suite.target("1", "user_id", "luis"), false))
+	suite.True(suite.document.Bool(suite.ctx, "beta", suite.target("2", "path", "/internal/stats"), false))
+	suite.False(suite.document.Bool(suite.ctx, "beta", suite.target("3", "user_id", "eva", "path", "/hello"), true))
+	suite.False(suite.document.Bool(suite.ctx, "beta", suite.target("4"), true), "missing attributes never match")
+}
+
+func (suite *DocumentTestSuite) TestNotInRule_ShouldExcludeValues() {
+	// Given
+	suite.whenDocumentIsParsed(`{"flags":{"f":{"enabled":true,"rollout":0,
+		"rules":[{"attribute":"method","operator":"not_in","values":["POST"],"serve":"on"}]}}}`, featureflags.FormatJSON)
+	suite.Require().NoError(suite.err)
+
+	// When / Then
+	suite.True(suite.document.Bool(suite.ctx, "f", suite.target("1", "method", "GET"), false))
+	suite.False(suite.document.Bool(suite.ctx, "f", suite.target("1", "method", "POST"), true))
+}
+
+func (suite *DocumentTestSuite) TestVariants_ShouldSplitByWeight() {
+	// When
+	hello := 0
+	for i := 0; i < 4000; i++ {
+		if suite.document.Variant(suite.ctx, "salutation", suite.target(fmt.Sprintf("user-%d", i)), "") == "Hello" {
+			hello++
+		}
+	}
+
+	// Then
+	suite.InDelta(0.75, float64(hello)/4000, 0.03)
+}
+
+func (suite *DocumentTestSuite) TestVariants_ShouldFollowRulesAndDefaults() {
+	// When / Then
+	suite.Equal("Hi", suite.document.Variant(suite.ctx, "salutation", suite.target("1", "user_id", "ana@example.com"), "Hey"))
+	suite.Equal("Hey", suite.document.Variant(suite.ctx, "salutation-off", suite.target("1"), "Hey"))
+	suite.Equal("Hey", suite.document.Variant(suite.ctx, "unknown", suite.target("1"), "Hey"))
+	suite.Equal("on", suite.document.Variant(suite.ctx, "emoji", suite.target("1"), "Hey"))
+	suite.True(suite.document.Bool(suite.ctx, "salutation", suite.target("1"), true), "variant flags are not booleans")
+}
+
+func (suite *DocumentTestSuite) TestNilDocument_ShouldServeDefaults() {
+	// Given
+	var document *featureflags.Document
+
+	// When / Then
+	suite.True(document.Bool(suite.ctx, "emoji", suite.target("1"), true))
+	suite.Equal("Hey", document.Variant(suite.ctx, "salutation", suite.target("1"), "Hey"))
+}
+
+func (suite *DocumentTestSuite) TestYAML_ShouldMatchJSON() {
+	// Given
+	fromJSON := suite.document
+
+	// When
+	suite.whenDocumentIsParsed(documentYAML, featureflags.FormatYAML)
+
+	// Then
+	suite.Require().NoError(suite.err)
+	suite.Equal(fromJSON, suite.document)
+}
+
+func (suite *DocumentTestSuite) TestInvalidDocument_ShouldReportEveryProblem() {
+	// When
+	suite.whenDocumentIsParsed(`{"flags":{
+		"a":{"enabled":true,"rollout":120},
+		"b":{"enabled":true,"variants":[{"name":"x","weight":0},{"name":"x","weight":-1}]},
+		"c":{"enabled":true,"rules":[{"attribute":"","operator":"like","values":[],"serve":"maybe"}]}
+	}}`, featureflags.FormatJSON)
+
+	// Then
+	suite.Require().Error(suite.err)
+	for _, problem := range []string{
+		`feature flag "a": rollout must be between 0 and 100, got 120`,
+		`feature flag "b": duplicate variant "x"`,
+		`feature flag "b": variant weights must add up to more than 0`,
+		`feature flag "c": rule 0: attribute must not be blank`,
+		`feature flag "c": rule 0: unknown operator "like"`,
+	} {
+		suite.Contains(suite.err.Error(), problem)
+	}
+}
+
+func (suite *DocumentTestSuite) TestUnknownField_ShouldBeRejected() {
+	// When
+	suite.whenDocumentIsParsed("flags:\n  a:\n    enable: true\n", featureflags.FormatYAML)
+
+	// Then
+	suite.ErrorContains(suite.err, "enable")
+}
+
+func (suite *DocumentTestSuite) TestLoadFile_ShouldPickFormatFromExtension() {
+	// Given
+	dir := suite.T().TempDir()
+	jsonPath := filepath.Join(dir, "flags.json")
+	yamlPath := filepath.Join(dir, "flags.yml")
+	suite.Require().NoError(os.WriteFile(jsonPath, []byte(documentJSON), 0o600))
+	suite.Require().NoError(os.WriteFile(yamlPath, []byte(documentYAML), 0o600))
+
+	// When
+	fromJSON, jsonErr := featureflags.LoadFile(jsonPath)
+	fromYAML, yamlErr := featureflags.LoadFile(yamlPath)
+	_, txtErr := featureflags.LoadFile(filepath.Join(dir, "flags.txt"))
+	_, missingErr := featureflags.LoadFile(filepath.Join(dir, "missing.json"))
+
+	// Then
+	suite.NoError(jsonErr)
+	suite.NoError(yamlErr)
+	suite.Equal(fromJSON, fromYAML)
+	suite.ErrorContains(txtErr, "extension must be .json, .yaml or .yml")
+	suite.ErrorIs(missingErr, os.ErrNotExist)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+var tokenSigner = service.NewTokenSigner()
+
+type JWTAuthTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	logger    *service.MockLogger
+	verifier  *auth.Verifier
+	skipPaths []string
+	request   events.APIGatewayProxyRequest
+	response  events.APIGatewayProxyResponse
+	err       error
+	principal entities.Principal
+	called    bool
+}
+
+func TestJWTAuthTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(JWTAuthTestSuite))
+}
+
+func (suite *JWTAuthTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.verifier = auth.NewVerifier(auth.VerifierConfig{
+		Keys:     auth.HMACKeySet(tokenSigner.Secret),
+		Audience: "greetings-api",
+	})
+	suite.skipPaths = nil
+	suite.request = events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/hello", Headers: map[string]string{}}
+	suite.principal = entities.Principal{}
+	suite.called = false
+	suite.err = nil
+}
+
+func (suite *JWTAuthTestSuite) givenAuthorization(value string) {
+	suite.request.Headers["Authorization"] = value
+}
+
+func (suite *JWTAuthTestSuite) givenToken(expiresIn time.Duration) string {
+	return tokenSigner.Sign("HS256", "", map[string]interface{}{
+		"sub":   "user-42",
+		"aud":   "greetings-api",
+		"exp":   time.Now().Add(expiresIn).Unix(),
+		"scope": "greetings:read",
+	})
+}
+
+func (suite *JWTAuthTestSuite) whenRequestIsAuthenticated() {
+	handler := handlers.Chain(func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		suite.called = true
+		suite.principal, _ = entities.PrincipalFromContext(ctx)
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	}, handlers.JWTAuth(handlers.JWTAuthConfig{
+		Verifier:  suite.verifier,
+		Logger:    suite.logger,
+		SkipPaths: suite.skipPaths,
+	}))
+
+	suite.response, suite.err = handler(suite.ctx, suite.request)
+}
+
+func (suite *JWTAuthTestSuite) thenResponseShouldBeUnauthorized(code, challenge string) {
+	suite.NoError(suite.err)
+	suite.False(suite.called)
+	suite.Equal(401, suite.response.StatusCode)
+	suite.Equal(challenge, suite.response.Headers["WWW-Authenticate"])
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal(code, body["code"])
+}
+
+func (suite *JWTAuthTestSuite) TestValidToken_ShouldStorePrincipal() {
+	// Given
+	suite.givenAuthorization("Bearer " + suite.givenToken(time.Hour))
+
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.NoError(suite.err)
+	suite.True(suite.called)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal("user-42", suite.principal.Subject)
+	suite.Equal("greetings:read", suite.principal.Claims["scope"])
+}
+
+func (suite *JWTAuthTestSuite) TestLowercaseScheme_ShouldBeAccepted() {
+	// Given
+	suite.givenAuthorization("bearer " + suite.givenToken(time.Hour))
+
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.True(suite.called)
+}
+
+func (suite *JWTAuthTestSuite) TestMissingToken_ShouldReturnUnauthorized() {
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.thenResponseShouldBeUnauthorized(handlers.CodeMissingToken, `Bearer realm="api"`)
+}
+
+func (suite *JWTAuthTestSuite) TestBasicScheme_ShouldReturnUnauthorized() {
+	// Given
+	suite.givenAuthorization("Basic dXNlcjpwYXNz")
+
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.thenResponseShouldBeUnauthorized(handlers.CodeMissingToken, `Bearer realm="api"`)
+}
+
+func (suite *JWTAuthTestSuite) TestExpiredToken_ShouldReturnUnauthorized() {
+	// Given
+	suite.givenAuthorization("Bearer " + suite.givenToken(-time.Minute))
+
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.thenResponseShouldBeUnauthorized(handlers.CodeInvalidToken, `Bearer realm="api", error="invalid_token"`)
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelWarn, "Authentication failed", mock.Anything)
+}
+
+func (suite *JWTAuthTestSuite) TestForgedToken_ShouldReturnUnauthorized() {
+	// Given
+	suite.givenAuthorization("Bearer " + tokenSigner.Sign("RS256", "", map[string]interface{}{
+		"sub": "user-42",
+		"aud": "greetings-api",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}))
+
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.thenResponseShouldBeUnauthorized(handlers.CodeInvalidToken, `Bearer realm="api", error="invalid_token"`)
+}
+
+func (suite *JWTAuthTestSuite) TestPreflight_ShouldPassThrough() {
+	// Given
+	suite.request.HTTPMethod = http.MethodOptions
+
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.True(suite.called)
+}
+
+func (suite *JWTAuthTestSuite) TestSkipPaths_ShouldPassThroughWithoutToken() {
+	// Given
+	suite.skipPaths = []string{"/health"}
+	suite.request.Path = "/health/"
+
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.True(suite.called)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Empty(suite.principal.Subject)
+}
+
+func (suite *JWTAuthTestSuite) TestSkipPaths_ShouldNotMatchOtherPaths() {
+	// Given
+	suite.skipPaths = []string{"/health"}
+	suite.request.Path = "/health/details"
+
+	// When
+	suite.whenRequestIsAuthenticated()
+
+	// Then
+	suite.thenResponseShouldBeUnauthorized(handlers.CodeMissingToken, `Bearer realm="api"`)
+}
+
+func (suite *JWTAuthTestSuite) TestPrincipal_ShouldKeyRateLimit() {
+	// Given
+	ctx := entities.ContextWithPrincipal(suite.ctx, entities.Principal{Subject: "user-42"})
+
+	// When
+	key := handlers.RateLimitByUser(ctx, suite.request)
+
+	// Then
+	suite.Equal("user:user-42", key)
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/auth"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+const methodArn = "arn:aws:execute-api:us-east-1:123456789012:abc123/prod/GET/hello"
+
+type AuthorizerTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	logger   *service.MockLogger
+	config   handlers.AuthorizerConfig
+	response events.APIGatewayCustomAuthorizerResponse
+	err      error
+}
+
+func TestAuthorizerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(AuthorizerTestSuite))
+}
+
+func (suite *AuthorizerTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.config = handlers.AuthorizerConfig{
+		Verifier: auth.NewVerifier(auth.VerifierConfig{Keys: auth.HMACKeySet(tokenSigner.Secret)}),
+		Logger:   suite.logger,
+	}
+	suite.err = nil
+}
+
+func (suite *AuthorizerTestSuite) givenToken(claims map[string]interface{}) string {
+	claims["sub"] = "user-42"
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+	}
+
+	return tokenSigner.Sign("HS256", "", claims)
+}
+
+func (suite *AuthorizerTestSuite) givenDisabledUsers(subjects ...string) {
+	suite.config.Authorize = func(_ context.Context, principal entities.Principal) bool {
+		for _, subject := range subjects {
+			if principal.Subject == subject {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func (suite *AuthorizerTestSuite) whenTokenAuthorizerIsCalled(authorizationToken string) {
+	suite.response, suite.err = handlers.TokenAuthorizer(suite.config)(suite.ctx, events.APIGatewayCustomAuthorizerRequest{
+		Type:               "TOKEN",
+		AuthorizationToken: authorizationToken,
+		MethodArn:          methodArn,
+	})
+}
+
+func (suite *AuthorizerTestSuite) whenRequestAuthorizerIsCalled(headers map[string]string) {
+	suite.response, suite.err = handlers.RequestAuthorizer(suite.config)(suite.ctx, events.APIGatewayCustomAuthorizerRequestTypeRequest{
+		Type:      "REQUEST",
+		MethodArn: methodArn,
+		Headers:   headers,
+	})
+}
+
+func (suite *AuthorizerTestSuite) thenPolicyShouldBe(effect string) {
+	suite.Require().NoError(suite.err)
+	suite.Equal("user-42", suite.response.PrincipalID)
+	suite.Equal("2012-10-17", suite.response.PolicyDocument.Version)
+	suite.Require().Len(suite.response.PolicyDocument.Statement, 1)
+
+	statement := suite.response.PolicyDocument.Statement[0]
+	suite.Equal(effect, statement.Effect)
+	suite.Equal([]string{"execute-api:Invoke"}, statement.Action)
+	suite.Equal([]string{"arn:aws:execute-api:us-east-1:123456789012:abc123/prod/*/*"}, statement.Resource)
+}
+
+func (suite *AuthorizerTestSuite) thenShouldBeUnauthorized() {
+	suite.Equal(handlers.ErrUnauthorized, suite.err)
+	suite.Equal("Unauthorized", suite.err.Error())
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelWarn, "Authorization failed", mock.Anything)
+}
+
+func (suite *AuthorizerTestSuite) TestTokenAuthorizer_ValidBearerToken_ShouldAllowWholeStage() {
+	// Given
+	token := suite.givenToken(map[string]interface{}{})
+
+	// When
+	suite.whenTokenAuthorizerIsCalled("Bearer " + token)
+
+	// Then
+	suite.thenPolicyShouldBe(handlers.PolicyEffectAllow)
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelInfo, "Authorization granted", mock.Anything)
+}
+
+func (suite *AuthorizerTestSuite) TestTokenAuthorizer_BareToken_ShouldAllow() {
+	// Given
+	token := suite.givenToken(map[string]interface{}{})
+
+	// When
+	suite.whenTokenAuthorizerIsCalled(token)
+
+	// Then
+	suite.thenPolicyShouldBe(handlers.PolicyEffectAllow)
+}
+
+func (suite *AuthorizerTestSuite) TestTokenAuthorizer_ExpiredToken_ShouldBeUnauthorized() {
+	// Given
+	token := suite.givenToken(map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()})
+
+	// When
+	suite.whenTokenAuthorizerIsCalled("Bearer " + token)
+
+	// Then
+	suite.thenShouldBeUnauthorized()
+}
+
+func (suite *AuthorizerTestSuite) TestTokenAuthorizer_OtherScheme_ShouldBeUnauthorized() {
+	// When
+	suite.whenTokenAuthorizerIsCalled("Basic dXNlcjpwYXNz")
+
+	// Then
+	suite.thenShouldBeUnauthorized()
+}
+
+func (suite *AuthorizerTestSuite) TestTokenAuthorizer_DisabledUser_ShouldDenyWholeStage() {
+	// Given
+	suite.givenDisabledUsers("user-42")
+	token := suite.givenToken(map[string]interface{}{})
+
+	// When
+	suite.whenTokenAuthorizerIsCalled("Bearer " + token)
+
+	// Then
+	suite.thenPolicyShouldBe(handlers.PolicyEffectDeny)
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelWarn, "Authorization denied", mock.Anything)
+}
+
+func (suite *AuthorizerTestSuite) TestRequestAuthorizer_AuthorizationHeader_ShouldAllow() {
+	// Given
+	token := suite.givenToken(map[string]interface{}{})
+
+	// When
+	suite.whenRequestAuthorizerIsCalled(map[string]string{"authorization": "Bearer " + token})
+
+	// Then
+	suite.thenPolicyShouldBe(handlers.PolicyEffectAllow)
+}
+
+func (suite *AuthorizerTestSuite) TestRequestAuthorizer_MissingHeader_ShouldBeUnauthorized() {
+	// When
+	suite.whenRequestAuthorizerIsCalled(map[string]string{})
+
+	// Then
+	suite.thenShouldBeUnauthorized()
+}
+
+func (suite *AuthorizerTestSuite) TestContext_ShouldFlattenClaims() {
+	// Given
+	token := suite.givenToken(map[string]interface{}{
+		"scope":    "greetings:read",
+		"admin":    false,
+		"level":    3,
+		"ratio":    0.5,
+		"groups":   []string{"staff", "beta"},
+		"address":  map[string]string{"country": "MX"},
+		"mixed":    []interface{}{"a", 1},
+		"verified": true,
+	})
+
+	// When
+	suite.whenTokenAuthorizerIsCalled("Bearer " + token)
+
+	// Then
+	suite.Require().NoError(suite.err)
+	values := suite.response.Context
+	suite.Equal("user-42", values["sub"])
+	suite.Equal("greetings:read", values["scope"])
+	suite.Equal(false, values["admin"])
+	suite.Equal(int64(3), values["level"])
+	suite.Equal(0.5, values["ratio"])
+	suite.Equal("staff beta", values["groups"])
+	suite.Equal(`{"country":"MX"}`, values["address"])
+	suite.Equal(`["a",1]`, values["mixed"])
+
+	_, err := json.Marshal(suite.response)
+	suite.NoError(err)
+}
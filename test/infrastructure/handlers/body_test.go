@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+)
+
+type HelloBodyTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	request  events.APIGatewayProxyRequest
+	response events.APIGatewayProxyResponse
+	err      error
+}
+
+func TestHelloBodyTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(HelloBodyTestSuite))
+}
+
+func (suite *HelloBodyTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.request = events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Path: "/hello"}
+	suite.err = nil
+}
+
+func (suite *HelloBodyTestSuite) givenBody(contentType, body string) {
+	suite.request.Headers = map[string]string{"Content-Type": contentType}
+	suite.request.Body = body
+}
+
+func (suite *HelloBodyTestSuite) givenBase64Body(contentType, body string) {
+	suite.givenBody(contentType, base64.StdEncoding.EncodeToString([]byte(body)))
+	suite.request.IsBase64Encoded = true
+}
+
+func (suite *HelloBodyTestSuite) whenHelloHandleRequestIsCalled() {
+	suite.response, suite.err = newHelloHandler().Handle(suite.ctx, suite.request)
+}
+
+func (suite *HelloBodyTestSuite) thenGreetingShouldBe(body string) {
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal(body, suite.response.Body)
+}
+
+func (suite *HelloBodyTestSuite) thenErrorShouldBe(statusCode int, code, messagePart string) {
+	suite.NoError(suite.err)
+	suite.Equal(statusCode, suite.response.StatusCode)
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal(code, body["code"])
+	suite.Contains(body["error"], messagePart)
+}
+
+func (suite *HelloBodyTestSuite) TestJSONBody_ShouldGreetName() {
+	// Given
+	suite.givenBody("application/json; charset=utf-8", `{"name":"Joe"}`)
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenGreetingShouldBe(`{"message":"Hello Joe!","name":"Joe"}`)
+}
+
+func (suite *HelloBodyTestSuite) TestFormBody_ShouldGreetName() {
+	// Given
+	suite.givenBody("application/x-www-form-urlencoded", "name=Mary-Jane")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenGreetingShouldBe(`{"message":"Hello Mary-Jane!","name":"Mary-Jane"}`)
+}
+
+func (suite *HelloBodyTestSuite) TestBase64Body_ShouldBeDecoded() {
+	// Given
+	suite.givenBase64Body("application/json", `{"name":"José"}`)
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenGreetingShouldBe(`{"message":"Hello José!","name":"José"}`)
+}
+
+func (suite *HelloBodyTestSuite) TestEmptyBody_ShouldGreetDefaultName() {
+	// Given
+	suite.givenBody("", "")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenGreetingShouldBe(`{"message":"Hello world!","name":"world"}`)
+}
+
+func (suite *HelloBodyTestSuite) TestBodyName_ShouldTakePrecedenceOverQuery() {
+	// Given
+	suite.givenBody("application/json", `{"name":"Joe"}`)
+	suite.request.QueryStringParameters = map[string]string{"name": "Ana"}
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenGreetingShouldBe(`{"message":"Hello Joe!","name":"Joe"}`)
+}
+
+func (suite *HelloBodyTestSuite) TestInvalidName_ShouldApplyUseCaseRules() {
+	// Given
+	suite.givenBody("application/json", `{"name":"<script>"}`)
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(400, handlers.CodeInvalidCharacters, "contains invalid characters")
+}
+
+func (suite *HelloBodyTestSuite) TestMalformedJSON_ShouldReturnBadRequest() {
+	// Given
+	suite.givenBody("application/json", `{"name":`)
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(400, handlers.CodeMalformedBody, "malformed request body")
+}
+
+func (suite *HelloBodyTestSuite) TestWrongFieldType_ShouldNameTheField() {
+	// Given
+	suite.givenBody("application/json", `{"name":42}`)
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(400, handlers.CodeMalformedBody, `field "name" must be a string`)
+}
+
+func (suite *HelloBodyTestSuite) TestNonObjectJSON_ShouldReturnBadRequest() {
+	// Given
+	suite.givenBody("application/json", `["Joe"]`)
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(400, handlers.CodeMalformedBody, "body must be a JSON object")
+}
+
+func (suite *HelloBodyTestSuite) TestInvalidBase64_ShouldReturnBadRequest() {
+	// Given
+	suite.givenBody("application/json", "%%%")
+	suite.request.IsBase64Encoded = true
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(400, handlers.CodeMalformedBody, "invalid base64 encoding")
+}
+
+func (suite *HelloBodyTestSuite) TestUnsupportedMediaType_ShouldReturn415() {
+	// Given
+	suite.givenBody("text/plain", "Joe")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(415, handlers.CodeUnsupportedMediaType, "application/json")
+}
+
+func (suite *HelloBodyTestSuite) TestMissingContentType_ShouldReturn415() {
+	// Given
+	suite.givenBody("", `{"name":"Joe"}`)
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorShouldBe(415, handlers.CodeUnsupportedMediaType, "unsupported media type")
+}
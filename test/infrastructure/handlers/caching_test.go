@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+)
+
+type ConditionalGETTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	config   handlers.CacheConfig
+	request  events.APIGatewayProxyRequest
+	response events.APIGatewayProxyResponse
+	err      error
+}
+
+func TestConditionalGETTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(ConditionalGETTestSuite))
+}
+
+func (suite *ConditionalGETTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.config = handlers.CacheConfig{MaxAge: 5 * time.Minute}
+	suite.request = events.APIGatewayProxyRequest{
+		HTTPMethod:            http.MethodGet,
+		Path:                  "/hello",
+		Headers:               map[string]string{},
+		QueryStringParameters: map[string]string{"name": "Joe"},
+	}
+	suite.err = nil
+}
+
+func (suite *ConditionalGETTestSuite) givenHeader(name, value string) {
+	suite.request.Headers[name] = value
+}
+
+func (suite *ConditionalGETTestSuite) whenHelloIsServed() {
+	handler := handlers.Chain(newHelloHandler().Handle, handlers.ConditionalGET(suite.config))
+	suite.response, suite.err = handler(suite.ctx, suite.request)
+}
+
+func (suite *ConditionalGETTestSuite) whenHelloIsServedCompressed() {
+	handler := handlers.Chain(newHelloHandler().Handle,
+		handlers.Compress(handlers.CompressionConfig{MinSize: 1}),
+		handlers.ConditionalGET(suite.config),
+	)
+	suite.response, suite.err = handler(suite.ctx, suite.request)
+}
+
+func (suite *ConditionalGETTestSuite) thenResponseShouldBeCacheable(cacheControl string) string {
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal(cacheControl, suite.response.Headers["Cache-Control"])
+
+	etag := suite.response.Headers["ETag"]
+	suite.Regexp(`^"[0-9a-f]{32}"$`, etag)
+
+	return etag
+}
+
+func (suite *ConditionalGETTestSuite) thenResponseShouldBeNotModified(etag string) {
+	suite.NoError(suite.err)
+	suite.Equal(304, suite.response.StatusCode)
+	suite.Empty(suite.response.Body)
+	suite.Empty(suite.response.Headers["Content-Type"])
+	suite.Equal(etag, suite.response.Headers["ETag"])
+	suite.Equal("public, max-age=300", suite.response.Headers["Cache-Control"])
+}
+
+func (suite *ConditionalGETTestSuite) TestSuccess_ShouldEmitETagAndCacheControl() {
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.thenResponseShouldBeCacheable("public, max-age=300")
+	suite.Equal(`{"message":"Hello Joe!","name":"Joe"}`, suite.response.Body)
+}
+
+func (suite *ConditionalGETTestSuite) TestSameName_ShouldProduceSameETag() {
+	// Given
+	suite.whenHelloIsServed()
+	first := suite.thenResponseShouldBeCacheable("public, max-age=300")
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.Equal(first, suite.thenResponseShouldBeCacheable("public, max-age=300"))
+}
+
+func (suite *ConditionalGETTestSuite) TestDifferentRepresentation_ShouldProduceDifferentETag() {
+	// Given
+	suite.whenHelloIsServed()
+	jsonETag := suite.thenResponseShouldBeCacheable("public, max-age=300")
+	suite.givenHeader("Accept", "text/plain")
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.NotEqual(jsonETag, suite.thenResponseShouldBeCacheable("public, max-age=300"))
+}
+
+func (suite *ConditionalGETTestSuite) TestMatchingIfNoneMatch_ShouldReturnNotModified() {
+	// Given
+	suite.whenHelloIsServed()
+	etag := suite.thenResponseShouldBeCacheable("public, max-age=300")
+	suite.givenHeader("If-None-Match", `"other", W/`+etag)
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.thenResponseShouldBeNotModified(etag)
+	suite.Equal("Accept", suite.response.Headers["Vary"])
+}
+
+func (suite *ConditionalGETTestSuite) TestWildcardIfNoneMatch_ShouldReturnNotModified() {
+	// Given
+	suite.givenHeader("If-None-Match", "*")
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.Equal(304, suite.response.StatusCode)
+}
+
+func (suite *ConditionalGETTestSuite) TestStaleIfNoneMatch_ShouldReturnFullResponse() {
+	// Given
+	suite.givenHeader("If-None-Match", `"0123456789abcdef0123456789abcdef"`)
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.thenResponseShouldBeCacheable("public, max-age=300")
+	suite.NotEmpty(suite.response.Body)
+}
+
+func (suite *ConditionalGETTestSuite) TestCacheConfig_ShouldRenderDirectives() {
+	// Given
+	suite.config = handlers.CacheConfig{MaxAge: time.Minute, SharedMaxAge: time.Hour}
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.thenResponseShouldBeCacheable("public, max-age=60, s-maxage=3600")
+}
+
+func (suite *ConditionalGETTestSuite) TestPrivateCacheConfig_ShouldOmitSharedMaxAge() {
+	// Given
+	suite.config = handlers.CacheConfig{MaxAge: time.Minute, SharedMaxAge: time.Hour, Private: true}
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.thenResponseShouldBeCacheable("private, max-age=60")
+}
+
+func (suite *ConditionalGETTestSuite) TestVary_ShouldBeAddedAndKeptOnNotModified() {
+	// Given
+	suite.config = handlers.CacheConfig{MaxAge: time.Minute, Private: true, Vary: []string{"Authorization"}}
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	etag := suite.thenResponseShouldBeCacheable("private, max-age=60")
+	suite.Equal("Accept, Authorization", suite.response.Headers["Vary"])
+
+	// Given
+	suite.givenHeader("If-None-Match", etag)
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.Equal(304, suite.response.StatusCode)
+	suite.Equal("Accept, Authorization", suite.response.Headers["Vary"])
+}
+
+func (suite *ConditionalGETTestSuite) TestErrorResponse_ShouldNotBeCached() {
+	// Given
+	suite.request.QueryStringParameters["name"] = "<script>"
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.Equal(400, suite.response.StatusCode)
+	suite.Empty(suite.response.Headers["ETag"])
+	suite.Empty(suite.response.Headers["Cache-Control"])
+}
+
+func (suite *ConditionalGETTestSuite) TestPost_ShouldNotBeCached() {
+	// Given
+	suite.request.HTTPMethod = http.MethodPost
+
+	// When
+	suite.whenHelloIsServed()
+
+	// Then
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Empty(suite.response.Headers["ETag"])
+}
+
+func (suite *ConditionalGETTestSuite) TestCompressedResponse_ShouldHaveEncodingSpecificETag() {
+	// Given
+	suite.whenHelloIsServed()
+	identityETag := suite.thenResponseShouldBeCacheable("public, max-age=300")
+	suite.givenHeader("Accept-Encoding", "gzip")
+
+	// When
+	suite.whenHelloIsServedCompressed()
+
+	// Then
+	suite.Equal("gzip", suite.response.Headers["Content-Encoding"])
+	suite.Equal(strings.TrimSuffix(identityETag, `"`)+`-gzip"`, suite.response.Headers["ETag"])
+}
+
+func (suite *ConditionalGETTestSuite) TestCompressedETag_ShouldRevalidate() {
+	// Given
+	suite.givenHeader("Accept-Encoding", "gzip")
+	suite.whenHelloIsServedCompressed()
+	gzipETag := suite.response.Headers["ETag"]
+	suite.givenHeader("If-None-Match", gzipETag)
+
+	// When
+	suite.whenHelloIsServedCompressed()
+
+	// Then
+	suite.Equal(304, suite.response.StatusCode)
+	suite.Empty(suite.response.Body)
+	suite.Empty(suite.response.Headers["Content-Encoding"])
+	suite.Equal(gzipETag, suite.response.Headers["ETag"])
+	suite.Equal("Accept, Accept-Encoding", suite.response.Headers["Vary"])
+}
+
+func (suite *ConditionalGETTestSuite) TestIdentityETag_ShouldRevalidateWithIdentityETag() {
+	// Given
+	suite.whenHelloIsServed()
+	identityETag := suite.thenResponseShouldBeCacheable("public, max-age=300")
+	suite.givenHeader("Accept-Encoding", "gzip")
+	suite.givenHeader("If-None-Match", identityETag)
+
+	// When
+	suite.whenHelloIsServedCompressed()
+
+	// Then
+	suite.Equal(304, suite.response.StatusCode)
+	suite.Equal(identityETag, suite.response.Headers["ETag"])
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+)
+
+type CompressionTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	config   handlers.CompressionConfig
+	request  events.APIGatewayProxyRequest
+	upstream events.APIGatewayProxyResponse
+	response events.APIGatewayProxyResponse
+	err      error
+}
+
+func TestCompressionTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(CompressionTestSuite))
+}
+
+func (suite *CompressionTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.config = handlers.DefaultCompressionConfig()
+	suite.request = events.APIGatewayProxyRequest{HTTPMethod: "GET", Path: "/hello"}
+	suite.err = nil
+}
+
+func (suite *CompressionTestSuite) givenAcceptEncoding(acceptEncoding string) {
+	suite.request.Headers = map[string]string{"Accept-Encoding": acceptEncoding}
+}
+
+func (suite *CompressionTestSuite) givenUpstreamBody(contentType, body string) {
+	suite.upstream = events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": contentType},
+		Body:       body,
+	}
+}
+
+func (suite *CompressionTestSuite) largeJSON() string {
+	return `{"message":"` + strings.Repeat("Hello world! ", 200) + `"}`
+}
+
+func (suite *CompressionTestSuite) whenCompressIsApplied() {
+	handler := handlers.Chain(func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		return suite.upstream, nil
+	}, handlers.Compress(suite.config))
+	suite.response, suite.err = handler(suite.ctx, suite.request)
+}
+
+func (suite *CompressionTestSuite) thenBodyShouldBeEncodedWith(encoding, expected string) {
+	suite.NoError(suite.err)
+	suite.True(suite.response.IsBase64Encoded)
+	suite.Equal(encoding, suite.response.Headers["Content-Encoding"])
+	suite.Equal("Accept-Encoding", suite.response.Headers["Vary"])
+
+	raw, err := base64.StdEncoding.DecodeString(suite.response.Body)
+	suite.Require().NoError(err)
+
+	var reader io.Reader
+	switch encoding {
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(raw))
+	case "gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(raw))
+	case "deflate":
+		reader, err = zlib.NewReader(bytes.NewReader(raw))
+	}
+	suite.Require().NoError(err)
+
+	decoded, err := io.ReadAll(reader)
+	suite.Require().NoError(err)
+	suite.Equal(expected, string(decoded))
+}
+
+func (suite *CompressionTestSuite) thenBodyShouldBeUncompressed(expected string) {
+	suite.NoError(suite.err)
+	suite.False(suite.response.IsBase64Encoded)
+	suite.Empty(suite.response.Headers["Content-Encoding"])
+	suite.Equal(expected, suite.response.Body)
+}
+
+func (suite *CompressionTestSuite) TestGzip_ShouldCompressLargeJSON() {
+	// Given
+	suite.givenAcceptEncoding("gzip")
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeEncodedWith("gzip", suite.largeJSON())
+	suite.Less(len(suite.response.Body), len(suite.largeJSON()))
+}
+
+func (suite *CompressionTestSuite) TestDeflate_ShouldUseZlibFormat() {
+	// Given
+	suite.givenAcceptEncoding("deflate")
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeEncodedWith("deflate", suite.largeJSON())
+}
+
+func (suite *CompressionTestSuite) TestBrotli_ShouldBePreferredOnTies() {
+	// Given
+	suite.givenAcceptEncoding("gzip, deflate, br")
+	suite.givenUpstreamBody("text/plain; charset=utf-8", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeEncodedWith("br", suite.largeJSON())
+}
+
+func (suite *CompressionTestSuite) TestQualityValues_ShouldBeHonoured() {
+	// Given
+	suite.givenAcceptEncoding("br;q=0.5, gzip;q=0.9, *;q=0")
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeEncodedWith("gzip", suite.largeJSON())
+}
+
+func (suite *CompressionTestSuite) TestWildcard_ShouldMatchPreferredEncoding() {
+	// Given
+	suite.givenAcceptEncoding("*")
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeEncodedWith("br", suite.largeJSON())
+}
+
+func (suite *CompressionTestSuite) TestConfiguredEncodings_ShouldLimitOffer() {
+	// Given
+	suite.config.Encodings = []string{handlers.EncodingGzip}
+	suite.givenAcceptEncoding("br, gzip")
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeEncodedWith("gzip", suite.largeJSON())
+}
+
+func (suite *CompressionTestSuite) TestBase64Body_ShouldBeDecodedBeforeCompression() {
+	// Given
+	suite.givenAcceptEncoding("gzip")
+	suite.givenUpstreamBody("application/json", base64.StdEncoding.EncodeToString([]byte(suite.largeJSON())))
+	suite.upstream.IsBase64Encoded = true
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeEncodedWith("gzip", suite.largeJSON())
+}
+
+func (suite *CompressionTestSuite) TestSmallBody_ShouldNotBeCompressed() {
+	// Given
+	suite.givenAcceptEncoding("gzip")
+	suite.givenUpstreamBody("application/json", `{"message":"Hello Joe!","name":"Joe"}`)
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeUncompressed(`{"message":"Hello Joe!","name":"Joe"}`)
+	suite.Equal("Accept-Encoding", suite.response.Headers["Vary"])
+}
+
+func (suite *CompressionTestSuite) TestMinSize_ShouldBeConfigurable() {
+	// Given
+	suite.config.MinSize = 10
+	suite.givenAcceptEncoding("gzip")
+	suite.givenUpstreamBody("application/json", `{"message":"Hello Joe!","name":"Joe"}`)
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeEncodedWith("gzip", `{"message":"Hello Joe!","name":"Joe"}`)
+}
+
+func (suite *CompressionTestSuite) TestNoAcceptEncoding_ShouldNotCompress() {
+	// Given
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeUncompressed(suite.largeJSON())
+}
+
+func (suite *CompressionTestSuite) TestIdentityOnly_ShouldNotCompress() {
+	// Given
+	suite.givenAcceptEncoding("identity, gzip;q=0")
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeUncompressed(suite.largeJSON())
+}
+
+func (suite *CompressionTestSuite) TestBinaryMediaType_ShouldNotCompress() {
+	// Given
+	suite.givenAcceptEncoding("gzip")
+	suite.givenUpstreamBody("image/png", suite.largeJSON())
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.thenBodyShouldBeUncompressed(suite.largeJSON())
+	suite.Empty(suite.response.Headers["Vary"])
+}
+
+func (suite *CompressionTestSuite) TestAlreadyEncoded_ShouldPassThrough() {
+	// Given
+	suite.givenAcceptEncoding("gzip")
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+	suite.upstream.Headers["Content-Encoding"] = "br"
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.False(suite.response.IsBase64Encoded)
+	suite.Equal("br", suite.response.Headers["Content-Encoding"])
+}
+
+func (suite *CompressionTestSuite) TestExistingVary_ShouldBeExtended() {
+	// Given
+	suite.givenAcceptEncoding("gzip")
+	suite.givenUpstreamBody("application/json", suite.largeJSON())
+	suite.upstream.Headers["Vary"] = "Accept"
+
+	// When
+	suite.whenCompressIsApplied()
+
+	// Then
+	suite.Equal("Accept, Accept-Encoding", suite.response.Headers["Vary"])
+}
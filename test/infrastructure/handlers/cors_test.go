@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+)
+
+type CORSTestSuite struct {
+	suite.Suite
+	ctx           context.Context
+	config        handlers.CORSConfig
+	request       events.APIGatewayProxyRequest
+	response      events.APIGatewayProxyResponse
+	err           error
+	handlerCalled bool
+	handlerStatus int
+}
+
+func TestCORSTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(CORSTestSuite))
+}
+
+func (suite *CORSTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.config = handlers.DefaultCORSConfig()
+	suite.request = events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/hello"}
+	suite.handlerCalled = false
+	suite.handlerStatus = 200
+	suite.err = nil
+}
+
+func (suite *CORSTestSuite) nextHandler(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	suite.handlerCalled = true
+	if suite.handlerStatus != 200 {
+		return handlers.ErrorResponse(suite.handlerStatus, "failure")
+	}
+	return events.APIGatewayProxyResponse{StatusCode: 200, Body: "Hello world!"}, nil
+}
+
+func (suite *CORSTestSuite) givenConfig(config handlers.CORSConfig) {
+	suite.config = config
+}
+
+func (suite *CORSTestSuite) givenOrigin(origin string) {
+	if suite.request.Headers == nil {
+		suite.request.Headers = map[string]string{}
+	}
+	suite.request.Headers["origin"] = origin
+}
+
+func (suite *CORSTestSuite) givenPreflight(method, headers string) {
+	suite.request.HTTPMethod = http.MethodOptions
+	if suite.request.Headers == nil {
+		suite.request.Headers = map[string]string{}
+	}
+	suite.request.Headers["Access-Control-Request-Method"] = method
+	if headers != "" {
+		suite.request.Headers["Access-Control-Request-Headers"] = headers
+	}
+}
+
+func (suite *CORSTestSuite) givenHandlerFailsWith(statusCode int) {
+	suite.handlerStatus = statusCode
+}
+
+func (suite *CORSTestSuite) whenCORSHandlerIsCalled() {
+	handler := handlers.Chain(suite.nextHandler, handlers.CORS(suite.config))
+	suite.response, suite.err = handler(suite.ctx, suite.request)
+}
+
+func (suite *CORSTestSuite) thenStatusShouldBe(statusCode int) {
+	suite.NoError(suite.err)
+	suite.Equal(statusCode, suite.response.StatusCode)
+}
+
+func (suite *CORSTestSuite) thenHeaderShouldBe(name, value string) {
+	suite.Equal(value, suite.response.Headers[name], name)
+}
+
+func (suite *CORSTestSuite) thenHeaderShouldBeAbsent(name string) {
+	suite.NotContains(suite.response.Headers, name)
+}
+
+func (suite *CORSTestSuite) TestWithoutOrigin_ShouldPassThrough() {
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.True(suite.handlerCalled)
+	suite.thenHeaderShouldBeAbsent("Access-Control-Allow-Origin")
+}
+
+func (suite *CORSTestSuite) TestWildcardOrigin_ShouldAllowAny() {
+	// Given
+	suite.givenOrigin("https://anywhere.test")
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.thenHeaderShouldBe("Access-Control-Allow-Origin", "*")
+}
+
+func (suite *CORSTestSuite) TestErrorResponse_ShouldIncludeCORSHeaders() {
+	// Given
+	suite.givenOrigin("https://anywhere.test")
+	suite.givenHandlerFailsWith(400)
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(400)
+	suite.thenHeaderShouldBe("Content-Type", "application/json")
+	suite.thenHeaderShouldBe("Access-Control-Allow-Origin", "*")
+}
+
+func (suite *CORSTestSuite) TestExactOrigin_WithCredentials_ShouldEchoOrigin() {
+	// Given
+	suite.givenConfig(handlers.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+		ExposedHeaders:   []string{"X-Request-Id"},
+	})
+	suite.givenOrigin("https://app.example.com")
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.thenHeaderShouldBe("Access-Control-Allow-Origin", "https://app.example.com")
+	suite.thenHeaderShouldBe("Access-Control-Allow-Credentials", "true")
+	suite.thenHeaderShouldBe("Access-Control-Expose-Headers", "X-Request-Id")
+	suite.thenHeaderShouldBe("Vary", "Origin")
+}
+
+func (suite *CORSTestSuite) TestWildcardSubdomain_ShouldMatchSubdomains() {
+	// Given
+	suite.givenConfig(handlers.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}})
+	suite.givenOrigin("https://api.eu.example.com")
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenHeaderShouldBe("Access-Control-Allow-Origin", "https://api.eu.example.com")
+}
+
+func (suite *CORSTestSuite) TestWildcardSubdomain_ShouldRejectOtherDomains() {
+	for _, origin := range []string{
+		"https://example.com",
+		"https://evilexample.com",
+		"http://api.example.com",
+		"https://api.example.com.evil.test",
+	} {
+		// Given
+		suite.SetupTest()
+		suite.givenConfig(handlers.CORSConfig{AllowedOrigins: []string{"https://*.example.com"}})
+		suite.givenOrigin(origin)
+
+		// When
+		suite.whenCORSHandlerIsCalled()
+
+		// Then
+		suite.True(suite.handlerCalled, origin)
+		suite.thenHeaderShouldBeAbsent("Access-Control-Allow-Origin")
+		suite.thenHeaderShouldBe("Vary", "Origin")
+	}
+}
+
+func (suite *CORSTestSuite) TestAllowList_WithoutOrigin_ShouldVaryOnOrigin() {
+	// Given
+	suite.givenConfig(handlers.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}})
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.thenHeaderShouldBeAbsent("Access-Control-Allow-Origin")
+	suite.thenHeaderShouldBe("Vary", "Origin")
+}
+
+func (suite *CORSTestSuite) TestWildcardOrigin_ShouldNotVaryOnOrigin() {
+	// Given
+	suite.givenOrigin("https://anywhere.test")
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenHeaderShouldBeAbsent("Vary")
+}
+
+func (suite *CORSTestSuite) TestPreflight_ShouldShortCircuit() {
+	// Given
+	suite.givenConfig(handlers.CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Content-Type"},
+		MaxAge:         time.Hour,
+	})
+	suite.givenOrigin("https://app.example.com")
+	suite.givenPreflight(http.MethodPost, "content-type")
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(204)
+	suite.False(suite.handlerCalled)
+	suite.thenHeaderShouldBe("Access-Control-Allow-Origin", "https://app.example.com")
+	suite.thenHeaderShouldBe("Access-Control-Allow-Methods", "GET, POST")
+	suite.thenHeaderShouldBe("Access-Control-Allow-Headers", "Content-Type")
+	suite.thenHeaderShouldBe("Access-Control-Max-Age", "3600")
+	suite.thenHeaderShouldBe("Vary", "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+}
+
+func (suite *CORSTestSuite) TestPreflight_WithoutAllowedHeaders_ShouldEchoRequestedHeaders() {
+	// Given
+	suite.givenConfig(handlers.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}})
+	suite.givenOrigin("https://anywhere.test")
+	suite.givenPreflight(http.MethodGet, "X-Custom, Authorization")
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(204)
+	suite.thenHeaderShouldBe("Access-Control-Allow-Headers", "X-Custom, Authorization")
+	suite.thenHeaderShouldBeAbsent("Access-Control-Max-Age")
+}
+
+func (suite *CORSTestSuite) TestPreflight_FromDisallowedOrigin_ShouldBeForbidden() {
+	// Given
+	suite.givenConfig(handlers.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}})
+	suite.givenOrigin("https://evil.test")
+	suite.givenPreflight(http.MethodGet, "")
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(403)
+	suite.False(suite.handlerCalled)
+	suite.thenHeaderShouldBeAbsent("Access-Control-Allow-Origin")
+	suite.thenHeaderShouldBe("Vary", "Origin")
+}
+
+func (suite *CORSTestSuite) TestPlainOptions_ShouldReachHandler() {
+	// Given
+	suite.givenOrigin("https://anywhere.test")
+	suite.request.HTTPMethod = http.MethodOptions
+
+	// When
+	suite.whenCORSHandlerIsCalled()
+
+	// Then
+	suite.True(suite.handlerCalled)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+)
+
+type FlagTargetingTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	request events.APIGatewayProxyRequest
+	target  entities.FlagTarget
+}
+
+func TestFlagTargetingTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(FlagTargetingTestSuite))
+}
+
+func (suite *FlagTargetingTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.request = events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/hello",
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID: "req-1",
+			Identity:  events.APIGatewayRequestIdentity{SourceIP: "203.0.113.7"},
+		},
+	}
+	suite.target = entities.FlagTarget{}
+}
+
+func (suite *FlagTargetingTestSuite) whenRequestIsHandled() {
+	handler := handlers.FlagTargeting()(func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		suite.target = entities.FlagTargetFromContext(ctx)
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	})
+
+	_, err := handler(suite.ctx, suite.request)
+	suite.Require().NoError(err)
+}
+
+func (suite *FlagTargetingTestSuite) TestAnonymousCaller_ShouldBeTargetedByRequestID() {
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.Equal(entities.FlagTarget{
+		Key: "req-1",
+		Attributes: map[string]string{
+			"request_id": "req-1",
+			"method":     "GET",
+			"path":       "/hello",
+			"source_ip":  "203.0.113.7",
+		},
+	}, suite.target)
+}
+
+func (suite *FlagTargetingTestSuite) TestAuthenticatedCaller_ShouldBeTargetedBySubject() {
+	// Given
+	suite.ctx = entities.ContextWithPrincipal(suite.ctx, entities.Principal{Subject: "user-42"})
+
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.Equal("user-42", suite.target.Key)
+	suite.Equal("user-42", suite.target.Attributes["user_id"])
+	suite.Equal("req-1", suite.target.Attributes["request_id"])
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type healthResponseBody struct {
+	Status        string    `json:"status"`
+	Version       string    `json:"version"`
+	ColdStart     time.Time `json:"cold_start"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	Checks        map[string]struct {
+		Status     string `json:"status"`
+		DurationMS int64  `json:"duration_ms"`
+	} `json:"checks"`
+}
+
+type HealthHandlerTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	logger   *service.MockLogger
+	config   handlers.HealthConfig
+	response events.APIGatewayProxyResponse
+	body     healthResponseBody
+	err      error
+}
+
+func TestHealthHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(HealthHandlerTestSuite))
+}
+
+func (suite *HealthHandlerTestSuite) SetupTest() {
+	coldStart := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.config = handlers.HealthConfig{
+		Version:   "v1.2.3",
+		ColdStart: coldStart,
+		Logger:    suite.logger,
+		Now:       func() time.Time { return coldStart.Add(90 * time.Second) },
+	}
+	suite.body = healthResponseBody{}
+	suite.err = nil
+}
+
+func (suite *HealthHandlerTestSuite) givenChecker(name string, timeout time.Duration, check func(ctx context.Context) error) {
+	suite.config.Checkers = append(suite.config.Checkers, services.NewHealthChecker(name, timeout, check))
+}
+
+func (suite *HealthHandlerTestSuite) whenHealthIsRequested() {
+	suite.response, suite.err = handlers.HealthHandler(suite.config)(suite.ctx, events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/health",
+	})
+	suite.Require().NoError(suite.err)
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &suite.body))
+}
+
+func (suite *HealthHandlerTestSuite) thenCheckShouldFail(name, message string) {
+	suite.Equal(http.StatusServiceUnavailable, suite.response.StatusCode)
+	suite.Equal(handlers.HealthStatusUnavailable, suite.body.Status)
+	suite.Equal(handlers.HealthStatusFail, suite.body.Checks[name].Status)
+	suite.NotContains(suite.response.Body, message)
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelWarn, "Health check failed",
+		mock.MatchedBy(func(fields []services.Field) bool {
+			return slices.Contains(fields, services.Field{Key: "error", Value: message})
+		}))
+}
+
+func (suite *HealthHandlerTestSuite) TestNoCheckers_ShouldReportBuildAndUptime() {
+	// When
+	suite.whenHealthIsRequested()
+
+	// Then
+	suite.Equal(http.StatusOK, suite.response.StatusCode)
+	suite.Equal(handlers.MediaTypeJSON, suite.response.Headers["Content-Type"])
+	suite.Equal("no-store", suite.response.Headers["Cache-Control"])
+	suite.Equal(handlers.HealthStatusOK, suite.body.Status)
+	suite.Equal("v1.2.3", suite.body.Version)
+	suite.Equal(suite.config.ColdStart, suite.body.ColdStart)
+	suite.Equal(90.0, suite.body.UptimeSeconds)
+	suite.Empty(suite.body.Checks)
+	suite.Contains(suite.response.Body, `"checks":{}`)
+}
+
+func (suite *HealthHandlerTestSuite) TestPassingCheckers_ShouldReturnOK() {
+	// Given
+	suite.givenChecker("dynamodb", 0, func(context.Context) error { return nil })
+	suite.givenChecker("feature_flags", 0, func(context.Context) error { return nil })
+
+	// When
+	suite.whenHealthIsRequested()
+
+	// Then
+	suite.Equal(http.StatusOK, suite.response.StatusCode)
+	suite.Len(suite.body.Checks, 2)
+	suite.Equal(handlers.HealthStatusOK, suite.body.Checks["dynamodb"].Status)
+	suite.logger.AssertNotCalled(suite.T(), "Log", mock.Anything, services.LevelWarn, "Health check failed", mock.Anything)
+}
+
+func (suite *HealthHandlerTestSuite) TestFailingChecker_ShouldReturnServiceUnavailable() {
+	// Given
+	suite.givenChecker("dynamodb", 0, func(context.Context) error { return nil })
+	suite.givenChecker("feature_flags", 0, func(context.Context) error { return errors.New("extension unreachable") })
+
+	// When
+	suite.whenHealthIsRequested()
+
+	// Then
+	suite.thenCheckShouldFail("feature_flags", "extension unreachable")
+	suite.Equal(handlers.HealthStatusOK, suite.body.Checks["dynamodb"].Status)
+}
+
+func (suite *HealthHandlerTestSuite) TestSlowChecker_ShouldTimeOutWithItsTimeout() {
+	// Given
+	suite.givenChecker("slow", 10*time.Millisecond, func(context.Context) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+
+	// When
+	start := time.Now()
+	suite.whenHealthIsRequested()
+
+	// Then
+	suite.Less(time.Since(start), 150*time.Millisecond)
+	suite.thenCheckShouldFail("slow", context.DeadlineExceeded.Error())
+}
+
+func (suite *HealthHandlerTestSuite) TestCheckerWithoutTimeout_ShouldUseDefaultTimeout() {
+	// Given
+	suite.config.Timeout = 10 * time.Millisecond
+	suite.givenChecker("slow", 0, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// When
+	suite.whenHealthIsRequested()
+
+	// Then
+	suite.thenCheckShouldFail("slow", context.DeadlineExceeded.Error())
+}
+
+func (suite *HealthHandlerTestSuite) TestPanickingChecker_ShouldFail() {
+	// Given
+	suite.givenChecker("broken", 0, func(context.Context) error { panic("boom") })
+
+	// When
+	suite.whenHealthIsRequested()
+
+	// Then
+	suite.thenCheckShouldFail("broken", "panic: boom")
+}
+
+func (suite *HealthHandlerTestSuite) TestDuplicateCheckerNames_ShouldPanic() {
+	// Given
+	suite.givenChecker("dynamodb", 0, func(context.Context) error { return nil })
+	suite.givenChecker("dynamodb", 0, func(context.Context) error { return nil })
+
+	// When / Then
+	suite.PanicsWithValue(`handlers: duplicate health checker "dynamodb"`, func() {
+		handlers.HealthHandler(suite.config)
+	})
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type HelloALBHandlerTestSuite struct {
+	suite.Suite
+	logger   *service.MockLogger
+	greet    handlers.GreetUseCase
+	ctx      context.Context
+	request  events.ALBTargetGroupRequest
+	response events.ALBTargetGroupResponse
+	err      error
+}
+
+func TestHelloALBHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(HelloALBHandlerTestSuite))
+}
+
+func (suite *HelloALBHandlerTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.greet = hello.Greeter{}.Greet
+	suite.request = events.ALBTargetGroupRequest{}
+	suite.err = nil
+}
+
+func (suite *HelloALBHandlerTestSuite) givenRequestWithName(name string) {
+	suite.request.QueryStringParameters = map[string]string{
+		"name": name,
+	}
+}
+
+func (suite *HelloALBHandlerTestSuite) givenRequestWithoutName() {
+	suite.request.QueryStringParameters = map[string]string{}
+}
+
+func (suite *HelloALBHandlerTestSuite) givenRequestWithNameContainingOnlySpaces() {
+	suite.request.QueryStringParameters = map[string]string{"name": "   "}
+}
+
+func (suite *HelloALBHandlerTestSuite) givenRequestWithLongName(length int) {
+	longName := strings.Repeat("a", length)
+	suite.request.QueryStringParameters = map[string]string{"name": longName}
+}
+
+func (suite *HelloALBHandlerTestSuite) givenRequestWithInvalidName(invalidName string) {
+	suite.request.QueryStringParameters = map[string]string{"name": invalidName}
+}
+
+func (suite *HelloALBHandlerTestSuite) givenMultiValueRequestWithNames(names ...string) {
+	suite.request.MultiValueQueryStringParameters = map[string][]string{"name": names}
+	suite.request.MultiValueHeaders = map[string][]string{"accept": {"*/*"}}
+}
+
+func (suite *HelloALBHandlerTestSuite) whenHelloALBHandleRequestIsCalled() {
+	suite.response, suite.err = handlers.NewHelloALBHandler(suite.logger, suite.greet).Handle(suite.ctx, suite.request)
+}
+
+func (suite *HelloALBHandlerTestSuite) thenResponseShouldBeSuccessful() {
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+}
+
+func (suite *HelloALBHandlerTestSuite) thenResponseShouldBeBadRequest() {
+	suite.NoError(suite.err)
+	suite.Equal(400, suite.response.StatusCode)
+}
+
+func (suite *HelloALBHandlerTestSuite) thenGreetingMessageShouldBe(expectedMessage string) {
+	contentType := suite.response.Headers["Content-Type"]
+	if values := suite.response.MultiValueHeaders["Content-Type"]; len(values) > 0 {
+		contentType = values[0]
+	}
+	suite.Equal("application/json", contentType)
+
+	var greeting map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &greeting))
+	suite.Equal(expectedMessage, greeting["message"])
+}
+
+func (suite *HelloALBHandlerTestSuite) thenResponseBodyShouldContain(expectedText string) {
+	suite.Contains(suite.response.Body, expectedText)
+}
+
+func (suite *HelloALBHandlerTestSuite) thenStatusDescriptionShouldBe(expected string) {
+	suite.Equal(expected, suite.response.StatusDescription)
+}
+
+func (suite *HelloALBHandlerTestSuite) thenResponseShouldUseMultiValueHeaders() {
+	suite.Nil(suite.response.Headers)
+	suite.Equal([]string{"application/json"}, suite.response.MultiValueHeaders["Content-Type"])
+}
+
+func (suite *HelloALBHandlerTestSuite) thenResponseShouldUseSingleValueHeaders() {
+	suite.Nil(suite.response.MultiValueHeaders)
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+}
+
+func (suite *HelloALBHandlerTestSuite) thenResponseShouldBeValidJSON() {
+	var jsonResponse map[string]string
+	err := json.Unmarshal([]byte(suite.response.Body), &jsonResponse)
+	suite.NoError(err)
+	suite.Contains(jsonResponse, "error")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestHelloALBHandlerWithName() {
+	// Given
+	suite.givenRequestWithName("Joe")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Joe!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestHelloALBHandlerWithoutName() {
+	// Given
+	suite.givenRequestWithoutName()
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello world!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestValidName_WithSpaces() {
+	// Given
+	suite.givenRequestWithName("John Doe")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello John Doe!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestValidName_WithInternationalCharacters() {
+	// Given
+	suite.givenRequestWithName("José María")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello José María!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestValidName_WithHyphen() {
+	// Given
+	suite.givenRequestWithName("Mary-Jane")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Mary-Jane!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestValidName_WithApostrophe() {
+	// Given
+	suite.givenRequestWithName("O'Brien")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello O'Brien!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestNameWithOnlySpaces_ShouldDefaultToWorld() {
+	// Given
+	suite.givenRequestWithNameContainingOnlySpaces()
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello world!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestNameTooLong_ShouldReject() {
+	// Given
+	suite.givenRequestWithLongName(101)
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("exceeds maximum length")
+	suite.thenResponseBodyShouldContain("100")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloALBHandlerTestSuite) TestInvalidCharacters_ScriptTag_ShouldReject() {
+	// Given
+	suite.givenRequestWithInvalidName("<script>alert('xss')</script>")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloALBHandlerTestSuite) TestInvalidCharacters_SQLInjection_ShouldReject() {
+	// Given
+	suite.givenRequestWithInvalidName("'; DROP TABLE users--")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloALBHandlerTestSuite) TestInvalidCharacters_SpecialSymbols_ShouldReject() {
+	// Given
+	suite.givenRequestWithInvalidName("John@Doe")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloALBHandlerTestSuite) TestInvalidCharacters_PathTraversal_ShouldReject() {
+	// Given
+	suite.givenRequestWithInvalidName("../../../etc/passwd")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloALBHandlerTestSuite) TestSuccess_ShouldSetStatusDescription() {
+	// Given
+	suite.givenRequestWithName("Joe")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenStatusDescriptionShouldBe("200 OK")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestValidationError_ShouldSetStatusDescription() {
+	// Given
+	suite.givenRequestWithInvalidName("John@Doe")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenStatusDescriptionShouldBe("400 Bad Request")
+	suite.thenResponseShouldUseSingleValueHeaders()
+}
+
+func (suite *HelloALBHandlerTestSuite) TestEncodedName_ShouldBeDecoded() {
+	// Given
+	suite.givenRequestWithName("Jos%C3%A9+Mar%C3%ADa")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello José María!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestMultiValueMode_ShouldUseFirstName() {
+	// Given
+	suite.givenMultiValueRequestWithNames("Ana", "Luis")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Ana!")
+}
+
+func (suite *HelloALBHandlerTestSuite) TestMultiValueMode_ValidationError_ShouldUseMultiValueHeaders() {
+	// Given
+	suite.givenMultiValueRequestWithNames("<script>")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseShouldUseMultiValueHeaders()
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloALBHandlerTestSuite) TestConfiguredRules_ShouldBeApplied() {
+	// Given
+	suite.greet = hello.Greeter{Rules: hello.Rules{DefaultName: "friend", MaxNameLength: 5}}.Greet
+	suite.givenRequestWithoutName()
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello friend!")
+	suite.thenResponseBodyShouldContain(`"name":"friend"`)
+
+	// Given
+	suite.givenRequestWithName("Joanna")
+
+	// When
+	suite.whenHelloALBHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("Maximum 5 characters allowed.")
+}
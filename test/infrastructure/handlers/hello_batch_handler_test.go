@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type batchResult struct {
+	Index   int    `json:"index"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+}
+
+type batchBody struct {
+	Results   []batchResult `json:"results"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+}
+
+type HelloBatchHandlerTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	config   handlers.BatchConfig
+	request  events.APIGatewayProxyRequest
+	response events.APIGatewayProxyResponse
+	err      error
+	body     batchBody
+}
+
+func TestHelloBatchHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(HelloBatchHandlerTestSuite))
+}
+
+func (suite *HelloBatchHandlerTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.config = handlers.BatchConfig{}
+	suite.request = events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Path: "/hello/batch"}
+	suite.body = batchBody{}
+	suite.err = nil
+}
+
+func (suite *HelloBatchHandlerTestSuite) givenMaxBatchSize(size int) {
+	suite.config.MaxBatchSize = size
+}
+
+func (suite *HelloBatchHandlerTestSuite) givenBody(contentType, body string) {
+	suite.request.Headers = map[string]string{"Content-Type": contentType}
+	suite.request.Body = body
+}
+
+func (suite *HelloBatchHandlerTestSuite) givenNames(names ...string) {
+	encoded, _ := json.Marshal(map[string][]string{"names": names})
+	suite.givenBody("application/json", string(encoded))
+}
+
+func (suite *HelloBatchHandlerTestSuite) whenHelloBatchHandlerIsCalled() {
+	suite.response, suite.err = handlers.HelloBatchHandler(suite.config)(suite.ctx, suite.request)
+}
+
+func (suite *HelloBatchHandlerTestSuite) thenStatusShouldBe(statusCode int) {
+	suite.NoError(suite.err)
+	suite.Equal(statusCode, suite.response.StatusCode)
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+}
+
+func (suite *HelloBatchHandlerTestSuite) thenResultsShouldBeParsed(succeeded, failed int) {
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &suite.body))
+	suite.Equal(succeeded, suite.body.Succeeded)
+	suite.Equal(failed, suite.body.Failed)
+	suite.Len(suite.body.Results, succeeded+failed)
+}
+
+func (suite *HelloBatchHandlerTestSuite) thenResultShouldBeGreeting(index int, name, message string) {
+	result := suite.body.Results[index]
+	suite.Equal(index, result.Index)
+	suite.Equal(name, result.Name)
+	suite.Equal(message, result.Message)
+	suite.Empty(result.Code)
+}
+
+func (suite *HelloBatchHandlerTestSuite) thenResultShouldBeError(index int, name, code string) {
+	result := suite.body.Results[index]
+	suite.Equal(index, result.Index)
+	suite.Equal(name, result.Name)
+	suite.Equal(code, result.Code)
+	suite.NotEmpty(result.Error)
+	suite.Empty(result.Message)
+}
+
+func (suite *HelloBatchHandlerTestSuite) thenErrorCodeShouldBe(code string) {
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal(code, body["code"])
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestAllValid_ShouldReturnOK() {
+	// Given
+	suite.givenNames("John", "  Jane  ", "")
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.thenResultsShouldBeParsed(3, 0)
+	suite.thenResultShouldBeGreeting(0, "John", "Hello John!")
+	suite.thenResultShouldBeGreeting(1, "Jane", "Hello Jane!")
+	suite.thenResultShouldBeGreeting(2, "world", "Hello world!")
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestMixedResults_ShouldReturnMultiStatus() {
+	// Given
+	suite.givenNames("John", "<script>", strings.Repeat("a", 101))
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(207)
+	suite.thenResultsShouldBeParsed(1, 2)
+	suite.thenResultShouldBeGreeting(0, "John", "Hello John!")
+	suite.thenResultShouldBeError(1, "<script>", handlers.CodeInvalidCharacters)
+	suite.thenResultShouldBeError(2, strings.Repeat("a", 101), handlers.CodeNameTooLong)
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestBareArray_ShouldBeAccepted() {
+	// Given
+	suite.givenBody("application/json", `["John","Jane"]`)
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.thenResultsShouldBeParsed(2, 0)
+	suite.thenResultShouldBeGreeting(1, "Jane", "Hello Jane!")
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestFormBody_ShouldUseRepeatedNames() {
+	// Given
+	suite.givenBody("application/x-www-form-urlencoded", "name=John&name=Mary-Jane")
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.thenResultsShouldBeParsed(2, 0)
+	suite.thenResultShouldBeGreeting(1, "Mary-Jane", "Hello Mary-Jane!")
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestEmptyBatch_ShouldReturnBadRequest() {
+	// Given
+	suite.givenNames()
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(400)
+	suite.thenErrorCodeShouldBe(handlers.CodeEmptyBatch)
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestBatchTooLarge_ShouldReturnBadRequest() {
+	// Given
+	suite.givenMaxBatchSize(2)
+	suite.givenNames("John", "Jane", "Joe")
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(400)
+	suite.thenErrorCodeShouldBe(handlers.CodeBatchTooLarge)
+	suite.Contains(suite.response.Body, "Maximum 2 names allowed")
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestDefaultMaxBatchSize_ShouldApply() {
+	// Given
+	names := make([]string, handlers.DefaultMaxBatchSize+1)
+	suite.givenNames(names...)
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(400)
+	suite.thenErrorCodeShouldBe(handlers.CodeBatchTooLarge)
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestMalformedJSON_ShouldReturnBadRequest() {
+	// Given
+	suite.givenBody("application/json", `{"names":["John"`)
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(400)
+	suite.thenErrorCodeShouldBe(handlers.CodeMalformedBody)
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestUnsupportedMediaType_ShouldReturn415() {
+	// Given
+	suite.givenBody("text/csv", "John,Jane")
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(415)
+	suite.thenErrorCodeShouldBe(handlers.CodeUnsupportedMediaType)
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestRules_ShouldApplyToEveryName() {
+	// Given
+	suite.config.Greet = hello.Greeter{Rules: hello.Rules{DefaultName: "friend", MaxNameLength: 5}}.Greet
+	suite.givenNames("", "Joanna")
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(http.StatusMultiStatus, suite.response.StatusCode)
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &suite.body))
+	suite.Equal(batchResult{Index: 0, Name: "friend", Message: "Hello friend!"}, suite.body.Results[0])
+	suite.Equal(handlers.CodeNameTooLong, suite.body.Results[1].Code)
+	suite.Contains(suite.body.Results[1].Error, "Maximum 5 characters allowed.")
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestGreet_ShouldServeFlaggedSalutation() {
+	// Given
+	flags := new(service.MockFeatureFlags)
+	flags.On("Variant", mock.Anything, hello.SalutationFlag, mock.Anything, hello.DefaultSalutation).Return("Hi")
+	suite.config.Greet = hello.Greeter{Flags: flags}.Greet
+	suite.givenNames("Ana")
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(http.StatusOK)
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &suite.body))
+	suite.Equal(batchResult{Index: 0, Name: "Ana", Message: "Hi Ana!"}, suite.body.Results[0])
+}
+
+func (suite *HelloBatchHandlerTestSuite) TestLogger_ShouldBeInjected() {
+	// Given
+	loggerService := new(service.MockLogger)
+	loggerService.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.config.Logger = loggerService
+	suite.givenNames("Ana")
+
+	// When
+	suite.whenHelloBatchHandlerIsCalled()
+
+	// Then
+	suite.Equal(http.StatusOK, suite.response.StatusCode)
+	loggerService.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelInfo, "Batch processed", mock.Anything)
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type streamedLine struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+type HelloFunctionURLHandlerTestSuite struct {
+	suite.Suite
+	logger            *service.MockLogger
+	greet             handlers.GreetUseCase
+	ctx               context.Context
+	cancel            context.CancelFunc
+	request           events.LambdaFunctionURLRequest
+	response          events.LambdaFunctionURLResponse
+	streamingResponse *events.LambdaFunctionURLStreamingResponse
+	lines             []streamedLine
+	err               error
+}
+
+func TestHelloFunctionURLHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(HelloFunctionURLHandlerTestSuite))
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) SetupTest() {
+	suite.ctx, suite.cancel = context.WithCancel(context.Background())
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.greet = hello.Greeter{}.Greet
+	suite.request = events.LambdaFunctionURLRequest{}
+	suite.streamingResponse = nil
+	suite.lines = nil
+	suite.err = nil
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TearDownTest() {
+	suite.cancel()
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) givenRequestWithName(name string) {
+	suite.request.QueryStringParameters = map[string]string{"name": name}
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) givenRequestWithRawQueryString(rawQueryString string) {
+	suite.request.RawQueryString = rawQueryString
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) givenCancelledContext() {
+	suite.cancel()
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) whenHelloFunctionURLHandleRequestIsCalled() {
+	suite.response, suite.err = handlers.NewHelloFunctionURLHandler(suite.logger, suite.greet).Handle(suite.ctx, suite.request)
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) whenHelloFunctionURLStreamHandleRequestIsCalled() {
+	suite.streamingResponse, suite.err = handlers.NewHelloFunctionURLHandler(suite.logger, suite.greet).HandleStream(suite.ctx, suite.request)
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) whenStreamIsConsumed() {
+	scanner := bufio.NewScanner(suite.streamingResponse.Body)
+	for scanner.Scan() {
+		var line streamedLine
+		suite.Require().NoError(json.Unmarshal(scanner.Bytes(), &line))
+		suite.lines = append(suite.lines, line)
+	}
+	suite.err = scanner.Err()
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) thenResponseShouldHaveStatus(statusCode int) {
+	suite.NoError(suite.err)
+	suite.Equal(statusCode, suite.response.StatusCode)
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) thenGreetingMessageShouldBe(expectedMessage string) {
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+
+	var greeting map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &greeting))
+	suite.Equal(expectedMessage, greeting["message"])
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) thenResponseBodyShouldContain(expectedText string) {
+	suite.Contains(suite.response.Body, expectedText)
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) thenStreamShouldBeNDJSON() {
+	suite.Require().NotNil(suite.streamingResponse)
+	suite.Equal(200, suite.streamingResponse.StatusCode)
+	suite.Equal("application/x-ndjson", suite.streamingResponse.Headers["Content-Type"])
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) thenStreamShouldContain(expected ...streamedLine) {
+	suite.NoError(suite.err)
+	suite.Equal(expected, suite.lines)
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) thenStreamShouldFailWith(expectedErr error) {
+	suite.ErrorIs(suite.err, expectedErr)
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TestBuffered_WithName() {
+	// Given
+	suite.givenRequestWithName("Joe")
+
+	// When
+	suite.whenHelloFunctionURLHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldHaveStatus(200)
+	suite.thenGreetingMessageShouldBe("Hello Joe!")
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TestBuffered_WithoutName() {
+	// When
+	suite.whenHelloFunctionURLHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldHaveStatus(200)
+	suite.thenGreetingMessageShouldBe("Hello world!")
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TestBuffered_RawQueryString() {
+	// Given
+	suite.givenRequestWithRawQueryString("name=Jos%C3%A9")
+
+	// When
+	suite.whenHelloFunctionURLHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldHaveStatus(200)
+	suite.thenGreetingMessageShouldBe("Hello José!")
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TestBuffered_InvalidName_ShouldReject() {
+	// Given
+	suite.givenRequestWithName("<script>")
+
+	// When
+	suite.whenHelloFunctionURLHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldHaveStatus(400)
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TestStream_MultipleNames() {
+	// Given
+	suite.givenRequestWithRawQueryString("name=Ana&name=Luis&name=%3Cx%3E")
+
+	// When
+	suite.whenHelloFunctionURLStreamHandleRequestIsCalled()
+	suite.whenStreamIsConsumed()
+
+	// Then
+	suite.thenStreamShouldBeNDJSON()
+	suite.thenStreamShouldContain(
+		streamedLine{Name: "Ana", Message: "Hello Ana!"},
+		streamedLine{Name: "Luis", Message: "Hello Luis!"},
+		streamedLine{Name: "<x>", Error: "name contains invalid characters"},
+	)
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TestStream_WithoutName_ShouldGreetWorld() {
+	// When
+	suite.whenHelloFunctionURLStreamHandleRequestIsCalled()
+	suite.whenStreamIsConsumed()
+
+	// Then
+	suite.thenStreamShouldBeNDJSON()
+	suite.thenStreamShouldContain(streamedLine{Name: "", Message: "Hello world!"})
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TestStream_CancelledContext_ShouldStop() {
+	// Given
+	suite.givenRequestWithRawQueryString("name=Ana&name=Luis")
+	suite.givenCancelledContext()
+
+	// When
+	suite.whenHelloFunctionURLStreamHandleRequestIsCalled()
+	_, suite.err = io.ReadAll(suite.streamingResponse.Body)
+
+	// Then
+	suite.thenStreamShouldFailWith(context.Canceled)
+}
+
+func (suite *HelloFunctionURLHandlerTestSuite) TestConfiguredRules_ShouldBeApplied() {
+	// Given
+	suite.greet = hello.Greeter{Rules: hello.Rules{DefaultName: "friend", MaxNameLength: 5}}.Greet
+
+	// When
+	suite.whenHelloFunctionURLHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldHaveStatus(200)
+	suite.thenGreetingMessageShouldBe("Hello friend!")
+
+	// Given
+	suite.givenRequestWithRawQueryString("name=Ana&name=Joanna")
+
+	// When
+	suite.whenHelloFunctionURLStreamHandleRequestIsCalled()
+	suite.whenStreamIsConsumed()
+
+	// Then
+	suite.thenStreamShouldContain(
+		streamedLine{Name: "Ana", Message: "Hello Ana!"},
+		streamedLine{Name: "Joanna", Error: "name exceeds maximum length"},
+	)
+}
@@ -7,14 +7,30 @@ import (
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
 	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
 )
 
+// newHelloHandler builds a HelloHandler with the real use case and a logger
+// that accepts any call.
+func newHelloHandler() *handlers.HelloHandler {
+	loggerService := new(service.MockLogger)
+	loggerService.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	return handlers.NewHelloHandler(loggerService, hello.Greeter{}.Greet, handlers.HelloHandlerConfig{})
+}
+
 type HelloHandlerTestSuite struct {
 	suite.Suite
 	ctx      context.Context
+	logger   *service.MockLogger
+	greet    handlers.GreetUseCase
+	config   handlers.HelloHandlerConfig
 	request  events.APIGatewayProxyRequest
 	response events.APIGatewayProxyResponse
 	err      error
@@ -27,6 +43,10 @@ func TestHelloHandlerTestSuite(t *testing.T) {
 
 func (suite *HelloHandlerTestSuite) SetupTest() {
 	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.greet = hello.Greeter{}.Greet
+	suite.config = handlers.HelloHandlerConfig{}
 	suite.request = events.APIGatewayProxyRequest{}
 	suite.err = nil
 }
@@ -54,8 +74,24 @@ func (suite *HelloHandlerTestSuite) givenRequestWithInvalidName(invalidName stri
 	suite.request.QueryStringParameters = map[string]string{"name": invalidName}
 }
 
+// givenRequestWithNames mirrors API Gateway, which keeps the last value in the
+// single-value map and every value in the multi-value map.
+func (suite *HelloHandlerTestSuite) givenRequestWithNames(names ...string) {
+	suite.request.QueryStringParameters = map[string]string{"name": names[len(names)-1]}
+	suite.request.MultiValueQueryStringParameters = map[string][]string{"name": names}
+}
+
+func (suite *HelloHandlerTestSuite) givenAcceptHeader(accept string) {
+	suite.request.Headers = map[string]string{"Accept": accept}
+}
+
 func (suite *HelloHandlerTestSuite) whenHelloHandleRequestIsCalled() {
-	suite.response, suite.err = handlers.HelloHandleRequest(suite.ctx, suite.request)
+	handler := handlers.NewHelloHandler(suite.logger, suite.greet, suite.config)
+	suite.response, suite.err = handler.Handle(suite.ctx, suite.request)
+}
+
+func (suite *HelloHandlerTestSuite) thenLoggerShouldHaveLogged(level services.Level, msg string) {
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, level, msg, mock.Anything)
 }
 
 func (suite *HelloHandlerTestSuite) thenResponseShouldBeSuccessful() {
@@ -68,14 +104,32 @@ func (suite *HelloHandlerTestSuite) thenResponseShouldBeBadRequest() {
 	suite.Equal(400, suite.response.StatusCode)
 }
 
-func (suite *HelloHandlerTestSuite) thenResponseBodyShouldBe(expectedBody string) {
-	suite.Equal(expectedBody, suite.response.Body)
+func (suite *HelloHandlerTestSuite) thenGreetingMessageShouldBe(expectedMessage string) {
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+
+	var greeting map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &greeting))
+	suite.Equal(expectedMessage, greeting["message"])
+}
+
+func (suite *HelloHandlerTestSuite) thenResponseShouldBeRendered(contentType, body string) {
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal(contentType, suite.response.Headers["Content-Type"])
+	suite.Equal("Accept", suite.response.Headers["Vary"])
+	suite.Equal(body, suite.response.Body)
 }
 
 func (suite *HelloHandlerTestSuite) thenResponseBodyShouldContain(expectedText string) {
 	suite.Contains(suite.response.Body, expectedText)
 }
 
+func (suite *HelloHandlerTestSuite) thenErrorCodeShouldBe(code string) {
+	var jsonResponse map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &jsonResponse))
+	suite.Equal(code, jsonResponse["code"])
+}
+
 func (suite *HelloHandlerTestSuite) thenResponseShouldBeValidJSON() {
 	var jsonResponse map[string]string
 	err := json.Unmarshal([]byte(suite.response.Body), &jsonResponse)
@@ -92,7 +146,7 @@ func (suite *HelloHandlerTestSuite) TestHelloHandlerWithName() {
 
 	// Then
 	suite.thenResponseShouldBeSuccessful()
-	suite.thenResponseBodyShouldBe("Hello Joe!")
+	suite.thenGreetingMessageShouldBe("Hello Joe!")
 }
 
 func (suite *HelloHandlerTestSuite) TestHelloHandlerWithoutName() {
@@ -104,7 +158,7 @@ func (suite *HelloHandlerTestSuite) TestHelloHandlerWithoutName() {
 
 	// Then
 	suite.thenResponseShouldBeSuccessful()
-	suite.thenResponseBodyShouldBe("Hello world!")
+	suite.thenGreetingMessageShouldBe("Hello world!")
 }
 
 func (suite *HelloHandlerTestSuite) TestValidName_WithSpaces() {
@@ -116,7 +170,7 @@ func (suite *HelloHandlerTestSuite) TestValidName_WithSpaces() {
 
 	// Then
 	suite.thenResponseShouldBeSuccessful()
-	suite.thenResponseBodyShouldBe("Hello John Doe!")
+	suite.thenGreetingMessageShouldBe("Hello John Doe!")
 }
 
 func (suite *HelloHandlerTestSuite) TestValidName_WithInternationalCharacters() {
@@ -128,7 +182,7 @@ func (suite *HelloHandlerTestSuite) TestValidName_WithInternationalCharacters()
 
 	// Then
 	suite.thenResponseShouldBeSuccessful()
-	suite.thenResponseBodyShouldBe("Hello José María!")
+	suite.thenGreetingMessageShouldBe("Hello José María!")
 }
 
 func (suite *HelloHandlerTestSuite) TestValidName_WithHyphen() {
@@ -140,7 +194,7 @@ func (suite *HelloHandlerTestSuite) TestValidName_WithHyphen() {
 
 	// Then
 	suite.thenResponseShouldBeSuccessful()
-	suite.thenResponseBodyShouldBe("Hello Mary-Jane!")
+	suite.thenGreetingMessageShouldBe("Hello Mary-Jane!")
 }
 
 func (suite *HelloHandlerTestSuite) TestValidName_WithApostrophe() {
@@ -152,7 +206,7 @@ func (suite *HelloHandlerTestSuite) TestValidName_WithApostrophe() {
 
 	// Then
 	suite.thenResponseShouldBeSuccessful()
-	suite.thenResponseBodyShouldBe("Hello O'Brien!")
+	suite.thenGreetingMessageShouldBe("Hello O'Brien!")
 }
 
 func (suite *HelloHandlerTestSuite) TestNameWithOnlySpaces_ShouldDefaultToWorld() {
@@ -164,7 +218,7 @@ func (suite *HelloHandlerTestSuite) TestNameWithOnlySpaces_ShouldDefaultToWorld(
 
 	// Then
 	suite.thenResponseShouldBeSuccessful()
-	suite.thenResponseBodyShouldBe("Hello world!")
+	suite.thenGreetingMessageShouldBe("Hello world!")
 }
 
 func (suite *HelloHandlerTestSuite) TestNameTooLong_ShouldReject() {
@@ -179,6 +233,7 @@ func (suite *HelloHandlerTestSuite) TestNameTooLong_ShouldReject() {
 	suite.thenResponseBodyShouldContain("exceeds maximum length")
 	suite.thenResponseBodyShouldContain("100")
 	suite.thenResponseShouldBeValidJSON()
+	suite.thenErrorCodeShouldBe(handlers.CodeNameTooLong)
 }
 
 func (suite *HelloHandlerTestSuite) TestInvalidCharacters_ScriptTag_ShouldReject() {
@@ -192,6 +247,7 @@ func (suite *HelloHandlerTestSuite) TestInvalidCharacters_ScriptTag_ShouldReject
 	suite.thenResponseShouldBeBadRequest()
 	suite.thenResponseBodyShouldContain("contains invalid characters")
 	suite.thenResponseShouldBeValidJSON()
+	suite.thenErrorCodeShouldBe(handlers.CodeInvalidCharacters)
 }
 
 func (suite *HelloHandlerTestSuite) TestInvalidCharacters_SQLInjection_ShouldReject() {
@@ -205,6 +261,7 @@ func (suite *HelloHandlerTestSuite) TestInvalidCharacters_SQLInjection_ShouldRej
 	suite.thenResponseShouldBeBadRequest()
 	suite.thenResponseBodyShouldContain("contains invalid characters")
 	suite.thenResponseShouldBeValidJSON()
+	suite.thenErrorCodeShouldBe(handlers.CodeInvalidCharacters)
 }
 
 func (suite *HelloHandlerTestSuite) TestInvalidCharacters_SpecialSymbols_ShouldReject() {
@@ -218,6 +275,7 @@ func (suite *HelloHandlerTestSuite) TestInvalidCharacters_SpecialSymbols_ShouldR
 	suite.thenResponseShouldBeBadRequest()
 	suite.thenResponseBodyShouldContain("contains invalid characters")
 	suite.thenResponseShouldBeValidJSON()
+	suite.thenErrorCodeShouldBe(handlers.CodeInvalidCharacters)
 }
 
 func (suite *HelloHandlerTestSuite) TestInvalidCharacters_PathTraversal_ShouldReject() {
@@ -231,4 +289,315 @@ func (suite *HelloHandlerTestSuite) TestInvalidCharacters_PathTraversal_ShouldRe
 	suite.thenResponseShouldBeBadRequest()
 	suite.thenResponseBodyShouldContain("contains invalid characters")
 	suite.thenResponseShouldBeValidJSON()
+	suite.thenErrorCodeShouldBe(handlers.CodeInvalidCharacters)
+}
+
+func (suite *HelloHandlerTestSuite) TestAcceptJSON_ShouldRenderNameAndMessage() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenAcceptHeader("application/json")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/json", `{"message":"Hello Joe!","name":"Joe"}`)
+}
+
+func (suite *HelloHandlerTestSuite) TestWithoutName_JSONShouldReportDefaultName() {
+	// Given
+	suite.givenRequestWithoutName()
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/json", `{"message":"Hello world!","name":"world"}`)
+}
+
+func (suite *HelloHandlerTestSuite) TestAcceptText_ShouldRenderPlainGreeting() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenAcceptHeader("text/plain")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("text/plain; charset=utf-8", "Hello Joe!")
+}
+
+func (suite *HelloHandlerTestSuite) TestAcceptHTML_ShouldEscapeName() {
+	// Given
+	suite.givenRequestWithName("O'Brien")
+	suite.givenAcceptHeader("text/html")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("text/html; charset=utf-8",
+		`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Greeting</title></head>`+
+			`<body><p>Hello O&#39;Brien!</p></body></html>`)
+}
+
+func (suite *HelloHandlerTestSuite) TestAcceptXML_ShouldRenderGreetingDocument() {
+	// Given
+	suite.givenRequestWithName("O'Brien")
+	suite.givenAcceptHeader("text/xml")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/xml",
+		`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<greeting><message>Hello O&#39;Brien!</message><name>O&#39;Brien</name></greeting>`)
+}
+
+func (suite *HelloHandlerTestSuite) TestAccept_ShouldHonourQualityValues() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenAcceptHeader("application/json;q=0.5, text/plain;q=0.9, image/png")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("text/plain; charset=utf-8", "Hello Joe!")
+}
+
+func (suite *HelloHandlerTestSuite) TestAcceptWildcard_ShouldDefaultToJSON() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenAcceptHeader("*/*")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/json", `{"message":"Hello Joe!","name":"Joe"}`)
+}
+
+func (suite *HelloHandlerTestSuite) TestAcceptWildcard_ShouldSkipExcludedTypes() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenAcceptHeader("application/json;q=0, */*")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("text/plain; charset=utf-8", "Hello Joe!")
+}
+
+func (suite *HelloHandlerTestSuite) TestAccept_ShouldPreferSpecificRangeOverExclusion() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenAcceptHeader("text/*;q=0, text/html;q=0.5, application/json;q=0.1")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal("text/html; charset=utf-8", suite.response.Headers["Content-Type"])
+}
+
+func (suite *HelloHandlerTestSuite) TestAcceptProblemJSON_ShouldRenderJSONGreeting() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenAcceptHeader("application/problem+json")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/json", `{"message":"Hello Joe!","name":"Joe"}`)
+}
+
+func (suite *HelloHandlerTestSuite) TestUnsupportedAccept_ShouldReturnNotAcceptable() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenAcceptHeader("image/png, application/json;q=0")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(406, suite.response.StatusCode)
+	suite.thenResponseBodyShouldContain("Supported media types")
+	suite.thenResponseShouldBeValidJSON()
+	suite.thenErrorCodeShouldBe(handlers.CodeNotAcceptable)
+}
+
+func (suite *HelloHandlerTestSuite) TestRepeatedName_ShouldGreetEveryName() {
+	// Given
+	suite.givenRequestWithNames("Ana", "Luis", "Eva")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/json",
+		`{"message":"Hello Ana, Luis and Eva!","name":"Ana, Luis and Eva","names":["Ana","Luis","Eva"]}`)
+}
+
+func (suite *HelloHandlerTestSuite) TestRepeatedName_ShouldRenderNamesInXML() {
+	// Given
+	suite.givenRequestWithNames("Ana", "Luis")
+	suite.givenAcceptHeader("application/xml")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/xml",
+		`<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<greeting><message>Hello Ana and Luis!</message><name>Ana and Luis</name>`+
+			`<names><name>Ana</name><name>Luis</name></names></greeting>`)
+}
+
+func (suite *HelloHandlerTestSuite) TestRepeatedName_ShouldSkipBlankValues() {
+	// Given
+	suite.givenRequestWithNames("", "Ana", "  ")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/json", `{"message":"Hello Ana!","name":"Ana"}`)
+}
+
+func (suite *HelloHandlerTestSuite) TestRepeatedName_WithInvalidName_ShouldReject() {
+	// Given
+	suite.givenRequestWithNames("Ana", "<script>")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenErrorCodeShouldBe(handlers.CodeInvalidCharacters)
+}
+
+func (suite *HelloHandlerTestSuite) TestRepeatedFormName_ShouldGreetEveryName() {
+	// Given
+	suite.request.HTTPMethod = "POST"
+	suite.request.Headers = map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+	suite.request.Body = "name=Ana&name=Luis"
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/json",
+		`{"message":"Hello Ana and Luis!","name":"Ana and Luis","names":["Ana","Luis"]}`)
+}
+
+func (suite *HelloHandlerTestSuite) TestInjectedUseCase_ShouldGreet() {
+	// Given
+	var greeted []string
+	suite.greet = func(_ context.Context, names []string) (hello.Greeting, error) {
+		greeted = names
+		return hello.Greeting{Message: "Hi " + hello.JoinNames(names) + "!", Names: names}, nil
+	}
+	suite.givenRequestWithNames("Ana", "Luis")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.Equal([]string{"Ana", "Luis"}, greeted)
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenResponseBodyShouldContain(`"message":"Hi Ana and Luis!"`)
+}
+
+func (suite *HelloHandlerTestSuite) TestInjectedLogger_ShouldReceiveValidationFailure() {
+	// Given
+	suite.givenRequestWithInvalidName("<script>")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenLoggerShouldHaveLogged(services.LevelWarn, "Validation failed")
+}
+
+func (suite *HelloHandlerTestSuite) TestMaxNames_ShouldRejectTooManyNames() {
+	// Given
+	suite.config.MaxNames = 2
+	suite.givenRequestWithNames("Ana", "Luis", "Eva")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenErrorCodeShouldBe(handlers.CodeTooManyNames)
+}
+
+func (suite *HelloHandlerTestSuite) TestMaxNames_ShouldAllowNamesUpToLimit() {
+	// Given
+	suite.config.MaxNames = 2
+	suite.givenRequestWithNames("Ana", "Luis")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+}
+
+func (suite *HelloHandlerTestSuite) TestHandler_ShouldBeReusableAcrossInvocations() {
+	// Given
+	handler := handlers.NewHelloHandler(suite.logger, suite.greet, suite.config)
+
+	for _, name := range []string{"Ana", "Luis"} {
+		// When
+		suite.givenRequestWithName(name)
+		suite.response, suite.err = handler.Handle(suite.ctx, suite.request)
+
+		// Then
+		suite.thenResponseShouldBeSuccessful()
+		suite.thenGreetingMessageShouldBe("Hello " + name + "!")
+	}
+}
+
+func (suite *HelloHandlerTestSuite) TestHelloHandleRequest_ShouldUseDefaults() {
+	// Given
+	suite.givenRequestWithName("Joe")
+
+	// When
+	suite.response, suite.err = handlers.HelloHandleRequest(suite.ctx, suite.request)
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Joe!")
+}
+
+func (suite *HelloHandlerTestSuite) TestConfiguredRules_ShouldReportDefaultNameAndLimit() {
+	// Given
+	rules := hello.Rules{DefaultName: "friend", MaxNameLength: 5}
+	suite.greet = hello.Greeter{Rules: rules}.Greet
+	suite.givenRequestWithoutName()
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeRendered("application/json", `{"message":"Hello friend!","name":"friend"}`)
+
+	// Given
+	suite.givenRequestWithName("Joanna")
+
+	// When
+	suite.whenHelloHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenErrorCodeShouldBe(handlers.CodeNameTooLong)
+	suite.thenResponseBodyShouldContain("Maximum 5 characters allowed.")
 }
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type HelloHTTPAPIHandlerTestSuite struct {
+	suite.Suite
+	logger   *service.MockLogger
+	greet    handlers.GreetUseCase
+	ctx      context.Context
+	request  events.APIGatewayV2HTTPRequest
+	response events.APIGatewayV2HTTPResponse
+	err      error
+}
+
+func TestHelloHTTPAPIHandlerTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(HelloHTTPAPIHandlerTestSuite))
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.greet = hello.Greeter{}.Greet
+	suite.request = events.APIGatewayV2HTTPRequest{}
+	suite.err = nil
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) givenRequestWithName(name string) {
+	suite.request.QueryStringParameters = map[string]string{
+		"name": name,
+	}
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) givenRequestWithoutName() {
+	suite.request.QueryStringParameters = map[string]string{}
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) givenRequestWithNameContainingOnlySpaces() {
+	suite.request.QueryStringParameters = map[string]string{"name": "   "}
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) givenRequestWithLongName(length int) {
+	longName := strings.Repeat("a", length)
+	suite.request.QueryStringParameters = map[string]string{"name": longName}
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) givenRequestWithInvalidName(invalidName string) {
+	suite.request.QueryStringParameters = map[string]string{"name": invalidName}
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) givenRequestWithRawQueryString(rawQueryString string) {
+	suite.request.RawQueryString = rawQueryString
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) givenRequestWithCookies(cookies ...string) {
+	suite.request.Cookies = cookies
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) givenRequestWithRequestID(requestID string) {
+	suite.request.RequestContext.RequestID = requestID
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) whenHelloHTTPAPIHandleRequestIsCalled() {
+	suite.response, suite.err = handlers.NewHelloHTTPAPIHandler(suite.logger, suite.greet).Handle(suite.ctx, suite.request)
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) thenResponseShouldBeSuccessful() {
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) thenResponseShouldBeBadRequest() {
+	suite.NoError(suite.err)
+	suite.Equal(400, suite.response.StatusCode)
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) thenGreetingMessageShouldBe(expectedMessage string) {
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+
+	var greeting map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &greeting))
+	suite.Equal(expectedMessage, greeting["message"])
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) thenResponseBodyShouldContain(expectedText string) {
+	suite.Contains(suite.response.Body, expectedText)
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) thenResponseShouldHaveJSONContentType() {
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) thenResponseShouldBeValidJSON() {
+	var jsonResponse map[string]string
+	err := json.Unmarshal([]byte(suite.response.Body), &jsonResponse)
+	suite.NoError(err)
+	suite.Contains(jsonResponse, "error")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestHelloHTTPAPIHandlerWithName() {
+	// Given
+	suite.givenRequestWithName("Joe")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Joe!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestHelloHTTPAPIHandlerWithoutName() {
+	// Given
+	suite.givenRequestWithoutName()
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello world!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestValidName_WithSpaces() {
+	// Given
+	suite.givenRequestWithName("John Doe")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello John Doe!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestValidName_WithInternationalCharacters() {
+	// Given
+	suite.givenRequestWithName("José María")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello José María!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestValidName_WithHyphen() {
+	// Given
+	suite.givenRequestWithName("Mary-Jane")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Mary-Jane!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestValidName_WithApostrophe() {
+	// Given
+	suite.givenRequestWithName("O'Brien")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello O'Brien!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestNameWithOnlySpaces_ShouldDefaultToWorld() {
+	// Given
+	suite.givenRequestWithNameContainingOnlySpaces()
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello world!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestNameTooLong_ShouldReject() {
+	// Given
+	suite.givenRequestWithLongName(101)
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("exceeds maximum length")
+	suite.thenResponseBodyShouldContain("100")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestInvalidCharacters_ScriptTag_ShouldReject() {
+	// Given
+	suite.givenRequestWithInvalidName("<script>alert('xss')</script>")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestInvalidCharacters_SQLInjection_ShouldReject() {
+	// Given
+	suite.givenRequestWithInvalidName("'; DROP TABLE users--")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestInvalidCharacters_SpecialSymbols_ShouldReject() {
+	// Given
+	suite.givenRequestWithInvalidName("John@Doe")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestInvalidCharacters_PathTraversal_ShouldReject() {
+	// Given
+	suite.givenRequestWithInvalidName("../../../etc/passwd")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("contains invalid characters")
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestRawQueryString_TakesPrecedenceOverParsedParameters() {
+	// Given
+	suite.givenRequestWithName("Ana,Luis")
+	suite.givenRequestWithRawQueryString("name=Ana&name=Luis")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Ana!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestRawQueryString_WithEncodedName() {
+	// Given
+	suite.givenRequestWithRawQueryString("name=Jos%C3%A9+Mar%C3%ADa")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello José María!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestRawQueryString_WithoutName_FallsBackToParameters() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenRequestWithRawQueryString("lang=en")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Joe!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestCookiesAndRequestID_DoNotAffectResponse() {
+	// Given
+	suite.givenRequestWithName("Joe")
+	suite.givenRequestWithCookies("session=secret", "theme=dark")
+	suite.givenRequestWithRequestID("req-abc-123")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello Joe!")
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestValidationError_ShouldReturnJSONContentType() {
+	// Given
+	suite.givenRequestWithInvalidName("John@Doe")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseShouldHaveJSONContentType()
+	suite.thenResponseShouldBeValidJSON()
+}
+
+func (suite *HelloHTTPAPIHandlerTestSuite) TestConfiguredRules_ShouldBeApplied() {
+	// Given
+	suite.greet = hello.Greeter{Rules: hello.Rules{DefaultName: "friend", MaxNameLength: 5}}.Greet
+	suite.givenRequestWithoutName()
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenGreetingMessageShouldBe("Hello friend!")
+	suite.thenResponseBodyShouldContain(`"name":"friend"`)
+
+	// Given
+	suite.givenRequestWithName("Joanna")
+
+	// When
+	suite.whenHelloHTTPAPIHandleRequestIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeBadRequest()
+	suite.thenResponseBodyShouldContain("Maximum 5 characters allowed.")
+}
@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/idempotency"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type IdempotencyTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	logger   *service.MockLogger
+	table    *service.DynamoDBStandIn
+	config   handlers.IdempotencyConfig
+	now      time.Time
+	request  events.APIGatewayProxyRequest
+	response events.APIGatewayProxyResponse
+	err      error
+	calls    int
+	status   int
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func TestIdempotencyTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(IdempotencyTestSuite))
+}
+
+func (suite *IdempotencyTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.config = handlers.IdempotencyConfig{
+		Store:  idempotency.NewMemoryStore(),
+		Logger: suite.logger,
+		Now:    func() time.Time { return suite.now },
+	}
+	suite.request = events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodPost,
+		Path:       "/orders",
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       `{"order":{"id":7},"note":"first"}`,
+	}
+	suite.calls = 0
+	suite.status = http.StatusCreated
+	suite.release = nil
+	suite.err = nil
+}
+
+func (suite *IdempotencyTestSuite) givenIdempotencyKey(key string) {
+	suite.request.Headers["Idempotency-Key"] = key
+}
+
+func (suite *IdempotencyTestSuite) givenDynamoDBStore() {
+	suite.table = service.NewDynamoDBStandIn()
+	suite.config.Store = idempotency.NewDynamoDBStore(suite.table, idempotency.DynamoDBStoreConfig{Table: "idempotency"})
+}
+
+func (suite *IdempotencyTestSuite) givenTimePasses(d time.Duration) {
+	suite.now = suite.now.Add(d)
+}
+
+func (suite *IdempotencyTestSuite) handler() handlers.Handler {
+	var mu sync.Mutex
+
+	return handlers.Chain(func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		mu.Lock()
+		suite.calls++
+		calls := suite.calls
+		mu.Unlock()
+
+		if suite.release != nil {
+			suite.started <- struct{}{}
+			<-suite.release
+		}
+
+		body, _ := json.Marshal(map[string]int{"call": calls})
+		return events.APIGatewayProxyResponse{
+			StatusCode: suite.status,
+			Headers:    map[string]string{"Content-Type": "application/json", "Location": "/orders/7"},
+			Body:       string(body),
+		}, nil
+	}, handlers.Idempotency(suite.config))
+}
+
+func (suite *IdempotencyTestSuite) whenRequestIsSent() {
+	suite.response, suite.err = suite.handler()(suite.ctx, suite.request)
+}
+
+func (suite *IdempotencyTestSuite) thenHandlerShouldRun(times int) {
+	suite.Equal(times, suite.calls)
+}
+
+func (suite *IdempotencyTestSuite) thenResponseShouldBeReplayOfFirstCall() {
+	suite.NoError(suite.err)
+	suite.Equal(http.StatusCreated, suite.response.StatusCode)
+	suite.Equal(`{"call":1}`, suite.response.Body)
+	suite.Equal("/orders/7", suite.response.Headers["Location"])
+	suite.Equal("true", suite.response.Headers["Idempotent-Replayed"])
+}
+
+func (suite *IdempotencyTestSuite) thenErrorCodeShouldBe(status int, code string) {
+	suite.NoError(suite.err)
+	suite.Equal(status, suite.response.StatusCode)
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal(code, body["code"])
+}
+
+func (suite *IdempotencyTestSuite) TestDuplicateWithHeaderKey_ShouldReplayResponse() {
+	// Given
+	suite.givenIdempotencyKey("key-1")
+	suite.whenRequestIsSent()
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(1)
+	suite.thenResponseShouldBeReplayOfFirstCall()
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelInfo, "Idempotent request replayed", mock.Anything)
+}
+
+func (suite *IdempotencyTestSuite) TestReplay_ShouldNotKeepHeadersOfOuterMiddleware() {
+	// Given
+	handler := handlers.Chain(suite.handler(),
+		handlers.Compress(handlers.CompressionConfig{MinSize: 1, Encodings: []string{handlers.EncodingGzip}}),
+	)
+	suite.givenIdempotencyKey("key-1")
+	suite.request.Headers["Accept-Encoding"] = "gzip"
+	first, _ := handler(suite.ctx, suite.request)
+	delete(suite.request.Headers, "Accept-Encoding")
+
+	// When
+	suite.response, suite.err = handler(suite.ctx, suite.request)
+
+	// Then
+	suite.Equal(handlers.EncodingGzip, first.Headers["Content-Encoding"])
+	suite.thenHandlerShouldRun(1)
+	suite.thenResponseShouldBeReplayOfFirstCall()
+	suite.Empty(suite.response.Headers["Content-Encoding"])
+	suite.False(suite.response.IsBase64Encoded)
+}
+
+func (suite *IdempotencyTestSuite) TestFirstRequest_ShouldNotBeMarkedReplayed() {
+	// Given
+	suite.givenIdempotencyKey("key-1")
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(1)
+	suite.Equal(http.StatusCreated, suite.response.StatusCode)
+	suite.NotContains(suite.response.Headers, "Idempotent-Replayed")
+}
+
+func (suite *IdempotencyTestSuite) TestRequestWithoutKey_ShouldAlwaysRun() {
+	// Given
+	suite.whenRequestIsSent()
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(2)
+	suite.Equal(`{"call":2}`, suite.response.Body)
+}
+
+func (suite *IdempotencyTestSuite) TestGetRequest_ShouldNotBeTracked() {
+	// Given
+	suite.request.HTTPMethod = http.MethodGet
+	suite.givenIdempotencyKey("key-1")
+	suite.whenRequestIsSent()
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(2)
+}
+
+func (suite *IdempotencyTestSuite) TestKeyReusedWithDifferentPayload_ShouldBeRejected() {
+	// Given
+	suite.givenIdempotencyKey("key-1")
+	suite.whenRequestIsSent()
+	suite.request.Body = `{"order":{"id":8}}`
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(1)
+	suite.thenErrorCodeShouldBe(http.StatusUnprocessableEntity, handlers.CodeIdempotencyKeyReused)
+}
+
+func (suite *IdempotencyTestSuite) TestSameKeyOnOtherPath_ShouldRun() {
+	// Given
+	suite.givenIdempotencyKey("key-1")
+	suite.whenRequestIsSent()
+	suite.request.Path = "/payments"
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(2)
+}
+
+func (suite *IdempotencyTestSuite) TestSameKeyFromOtherPrincipal_ShouldRun() {
+	// Given
+	suite.givenIdempotencyKey("key-1")
+	suite.ctx = entities.ContextWithPrincipal(context.Background(), entities.Principal{Subject: "alice"})
+	suite.whenRequestIsSent()
+	suite.ctx = entities.ContextWithPrincipal(context.Background(), entities.Principal{Subject: "bob"})
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(2)
+}
+
+func (suite *IdempotencyTestSuite) TestSelectorKey_ShouldIgnoreOtherFields() {
+	// Given
+	suite.config.Selector = idempotency.MustParseSelector("order.id")
+	suite.whenRequestIsSent()
+	suite.request.Body = `{"order":{"id":7},"note":"retried"}`
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(1)
+	suite.thenResponseShouldBeReplayOfFirstCall()
+}
+
+func (suite *IdempotencyTestSuite) TestSelectorWithoutMatch_ShouldRun() {
+	// Given
+	suite.config.Selector = idempotency.MustParseSelector("payment.id")
+	suite.whenRequestIsSent()
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(2)
+}
+
+func (suite *IdempotencyTestSuite) TestServerError_ShouldNotBeStored() {
+	// Given
+	suite.givenIdempotencyKey("key-1")
+	suite.status = http.StatusBadGateway
+	suite.whenRequestIsSent()
+	suite.status = http.StatusCreated
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(2)
+	suite.Equal(http.StatusCreated, suite.response.StatusCode)
+}
+
+func (suite *IdempotencyTestSuite) TestExpiredRecord_ShouldRunAgain() {
+	// Given
+	suite.givenIdempotencyKey("key-1")
+	suite.whenRequestIsSent()
+	suite.givenTimePasses(handlers.DefaultIdempotencyTTL)
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(2)
+}
+
+func (suite *IdempotencyTestSuite) TestConcurrentDuplicate_ShouldConflict() {
+	// Given
+	suite.givenDynamoDBStore()
+	suite.givenIdempotencyKey("key-1")
+	suite.started = make(chan struct{})
+	suite.release = make(chan struct{})
+	handler := suite.handler()
+	first := make(chan events.APIGatewayProxyResponse)
+	go func() {
+		response, _ := handler(suite.ctx, suite.request)
+		first <- response
+	}()
+	<-suite.started
+
+	// When
+	suite.response, suite.err = handler(suite.ctx, suite.request)
+	close(suite.release)
+
+	// Then
+	suite.thenErrorCodeShouldBe(http.StatusConflict, handlers.CodeIdempotencyInProgress)
+	suite.Equal("1", suite.response.Headers["Retry-After"])
+	suite.Equal(http.StatusCreated, (<-first).StatusCode)
+	suite.thenHandlerShouldRun(1)
+}
+
+func (suite *IdempotencyTestSuite) TestStoreFailure_ShouldFailClosed() {
+	// Given
+	suite.givenDynamoDBStore()
+	suite.table.Err = errors.New("throttled")
+	suite.givenIdempotencyKey("key-1")
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(0)
+	suite.thenErrorCodeShouldBe(http.StatusServiceUnavailable, handlers.CodeServiceUnavailable)
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelError, "Idempotency store failed", mock.Anything)
+}
+
+func (suite *IdempotencyTestSuite) TestReplayFromDynamoDB_ShouldRestoreResponse() {
+	// Given
+	suite.givenDynamoDBStore()
+	suite.givenIdempotencyKey("key-1")
+	suite.whenRequestIsSent()
+
+	// When
+	suite.whenRequestIsSent()
+
+	// Then
+	suite.thenHandlerShouldRun(1)
+	suite.thenResponseShouldBeReplayOfFirstCall()
+}
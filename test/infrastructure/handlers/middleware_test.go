@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	logger     *service.MockLogger
+	request    events.APIGatewayProxyRequest
+	response   events.APIGatewayProxyResponse
+	err        error
+	handler    handlers.Handler
+	calls      []string
+	handlerCtx context.Context
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(MiddlewareTestSuite))
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.request = events.APIGatewayProxyRequest{}
+	suite.calls = nil
+	suite.handlerCtx = nil
+	suite.err = nil
+}
+
+func (suite *MiddlewareTestSuite) recordingMiddleware(name string) handlers.Middleware {
+	return func(next handlers.Handler) handlers.Handler {
+		return func(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			suite.calls = append(suite.calls, name+":before")
+			response, err := next(ctx, request)
+			suite.calls = append(suite.calls, name+":after")
+			return response, err
+		}
+	}
+}
+
+func (suite *MiddlewareTestSuite) finalHandler(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	suite.calls = append(suite.calls, "handler")
+	suite.handlerCtx = ctx
+	return events.APIGatewayProxyResponse{StatusCode: 200, Body: "ok"}, nil
+}
+
+func (suite *MiddlewareTestSuite) panickingHandler(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	panic("boom")
+}
+
+func (suite *MiddlewareTestSuite) givenPanickingChain(middlewares ...handlers.Middleware) {
+	suite.handler = handlers.Chain(suite.panickingHandler, middlewares...)
+}
+
+func (suite *MiddlewareTestSuite) givenLoggerExpectsPanicRecovered() {
+	suite.logger.On("Log", mock.Anything, services.LevelError, "Panic recovered", mock.Anything).Return()
+}
+
+func (suite *MiddlewareTestSuite) givenChain(middlewares ...handlers.Middleware) {
+	suite.handler = handlers.Chain(suite.finalHandler, middlewares...)
+}
+
+func (suite *MiddlewareTestSuite) givenRequestID(requestID string) {
+	suite.request.RequestContext.RequestID = requestID
+}
+
+func (suite *MiddlewareTestSuite) givenLoggerExpectsRequestReceived() {
+	suite.logger.On("Log", mock.Anything, services.LevelDebug, "Request received", mock.Anything).Return()
+}
+
+func (suite *MiddlewareTestSuite) whenHandlerIsCalled() {
+	suite.response, suite.err = suite.handler(suite.ctx, suite.request)
+}
+
+func (suite *MiddlewareTestSuite) thenCallOrderShouldBe(expected ...string) {
+	suite.Equal(expected, suite.calls)
+}
+
+func (suite *MiddlewareTestSuite) thenResponseShouldBeSuccessful() {
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+}
+
+func (suite *MiddlewareTestSuite) thenResponseShouldBeInternalServerError() {
+	suite.NoError(suite.err)
+	suite.Equal(500, suite.response.StatusCode)
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal(map[string]string{"error": "Internal server error", "status": "500", "code": "INTERNAL_ERROR"}, body)
+}
+
+func (suite *MiddlewareTestSuite) thenPanicShouldBeLogged(requestID string) {
+	suite.logger.AssertCalled(suite.T(), "Log",
+		mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value("request_id") == requestID }),
+		services.LevelError, "Panic recovered",
+		mock.MatchedBy(func(fields []services.Field) bool {
+			values := make(map[string]interface{}, len(fields))
+			for _, field := range fields {
+				values[field.Key] = field.Value
+			}
+			stack, _ := values["stack"].(string)
+			return values["panic"] == "boom" && strings.Contains(stack, "panickingHandler")
+		}),
+	)
+}
+
+func (suite *MiddlewareTestSuite) thenHandlerContextRequestIDShouldBe(expected interface{}) {
+	suite.Equal(expected, suite.handlerCtx.Value("request_id"))
+}
+
+func (suite *MiddlewareTestSuite) TestChain_WithoutMiddlewares() {
+	// Given
+	suite.givenChain()
+
+	// When
+	suite.whenHandlerIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenCallOrderShouldBe("handler")
+}
+
+func (suite *MiddlewareTestSuite) TestChain_FirstMiddlewareIsOutermost() {
+	// Given
+	suite.givenChain(suite.recordingMiddleware("outer"), suite.recordingMiddleware("inner"))
+
+	// When
+	suite.whenHandlerIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenCallOrderShouldBe("outer:before", "inner:before", "handler", "inner:after", "outer:after")
+}
+
+func (suite *MiddlewareTestSuite) TestRequestID_ShouldStoreRequestIDInContext() {
+	// Given
+	suite.givenRequestID("req-abc-123")
+	suite.givenChain(handlers.RequestID())
+
+	// When
+	suite.whenHandlerIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenHandlerContextRequestIDShouldBe("req-abc-123")
+}
+
+func (suite *MiddlewareTestSuite) TestRequestID_WithoutRequestID_ShouldLeaveContextUntouched() {
+	// Given
+	suite.givenChain(handlers.RequestID())
+
+	// When
+	suite.whenHandlerIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.thenHandlerContextRequestIDShouldBe(nil)
+}
+
+func (suite *MiddlewareTestSuite) TestRequestLogger_ShouldLogWithRequestIDInContext() {
+	// Given
+	suite.givenRequestID("req-abc-123")
+	suite.givenLoggerExpectsRequestReceived()
+	suite.givenChain(handlers.RequestID(), handlers.RequestLogger(suite.logger))
+
+	// When
+	suite.whenHandlerIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.logger.AssertCalled(suite.T(), "Log",
+		mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value("request_id") == "req-abc-123" }),
+		services.LevelDebug, "Request received", mock.Anything,
+	)
+}
+
+func (suite *MiddlewareTestSuite) TestRecover_WithoutPanic_ShouldPassThrough() {
+	// Given
+	suite.givenChain(handlers.Recover(suite.logger))
+
+	// When
+	suite.whenHandlerIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeSuccessful()
+	suite.logger.AssertNotCalled(suite.T(), "Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *MiddlewareTestSuite) TestRecover_ShouldReturnStructuredInternalServerError() {
+	// Given
+	suite.givenRequestID("req-abc-123")
+	suite.givenLoggerExpectsPanicRecovered()
+	suite.givenPanickingChain(handlers.RequestID(), handlers.Recover(suite.logger))
+
+	// When
+	suite.whenHandlerIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeInternalServerError()
+	suite.thenPanicShouldBeLogged("req-abc-123")
+}
+
+func (suite *MiddlewareTestSuite) TestRecover_WithoutRequestIDMiddleware_ShouldStillLogRequestID() {
+	// Given
+	suite.givenRequestID("req-xyz-789")
+	suite.givenLoggerExpectsPanicRecovered()
+	suite.givenPanickingChain(handlers.Recover(suite.logger))
+
+	// When
+	suite.whenHandlerIsCalled()
+
+	// Then
+	suite.thenResponseShouldBeInternalServerError()
+	suite.thenPanicShouldBeLogged("req-xyz-789")
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/application/use_cases/hello"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/openapi"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type OpenAPITestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	handler *handlers.HelloHandler
+}
+
+func TestOpenAPITestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(OpenAPITestSuite))
+}
+
+func (suite *OpenAPITestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.handler = newHelloHandler()
+}
+
+// thenExampleShouldMatch asserts that the error served for request is the
+// example documented under its status code and error code.
+func (suite *OpenAPITestSuite) thenExampleShouldMatch(operation openapi.Operation, request events.APIGatewayProxyRequest) {
+	response, err := suite.handler.Handle(suite.ctx, request)
+	suite.Require().NoError(err)
+
+	var served map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(response.Body), &served))
+
+	documented, ok := operation.Responses[served["status"]]
+	suite.Require().True(ok, "status %s is not documented", served["status"])
+	example, ok := documented.Content[handlers.MediaTypeJSON].Examples[served["code"]]
+	suite.Require().True(ok, "code %s is not documented", served["code"])
+
+	encoded, _ := json.Marshal(example.Value)
+	suite.JSONEq(response.Body, string(encoded))
+}
+
+func (suite *OpenAPITestSuite) TestHelloErrorExamples_ShouldMatchServedErrors() {
+	// Given
+	get := suite.handler.Operation(http.MethodGet, hello.Rules{})
+	post := suite.handler.Operation(http.MethodPost, hello.Rules{})
+
+	// When / Then
+	suite.thenExampleShouldMatch(get, events.APIGatewayProxyRequest{
+		HTTPMethod:            http.MethodGet,
+		QueryStringParameters: map[string]string{"name": "<script>"},
+	})
+	suite.thenExampleShouldMatch(get, events.APIGatewayProxyRequest{
+		HTTPMethod:            http.MethodGet,
+		QueryStringParameters: map[string]string{"name": strings.Repeat("a", hello.MaxNameLength+1)},
+	})
+	suite.thenExampleShouldMatch(get, events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodGet,
+		Headers:    map[string]string{"Accept": "image/png"},
+	})
+	suite.thenExampleShouldMatch(post, events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodPost,
+		Headers:    map[string]string{"Content-Type": "text/csv"},
+		Body:       "John",
+	})
+}
+
+func (suite *OpenAPITestSuite) TestHelloOperations_ShouldDescribeInputs() {
+	// When
+	get := suite.handler.Operation(http.MethodGet, hello.Rules{})
+	post := suite.handler.Operation(http.MethodPost, hello.Rules{})
+
+	// Then
+	suite.Equal("getHello", get.OperationID)
+	suite.Equal("name", get.Parameters[0].Name)
+	suite.Equal(openapi.InQuery, get.Parameters[0].In)
+	suite.Nil(get.RequestBody)
+	suite.NotContains(get.Responses, "415")
+
+	suite.Equal("postHello", post.OperationID)
+	suite.Empty(post.Parameters)
+	suite.Contains(post.RequestBody.Content, handlers.MediaTypeJSON)
+	suite.Contains(post.RequestBody.Content, handlers.MediaTypeForm)
+	suite.Contains(post.Responses["400"].Content[handlers.MediaTypeJSON].Examples, handlers.CodeMalformedBody)
+}
+
+func (suite *OpenAPITestSuite) TestMaxNames_ShouldBeDocumented() {
+	// Given
+	loggerService := new(service.MockLogger)
+	loggerService.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.handler = handlers.NewHelloHandler(loggerService, hello.Greeter{}.Greet, handlers.HelloHandlerConfig{MaxNames: 2})
+
+	// When / Then
+	suite.thenExampleShouldMatch(suite.handler.Operation(http.MethodGet, hello.Rules{}), events.APIGatewayProxyRequest{
+		HTTPMethod:                      http.MethodGet,
+		MultiValueQueryStringParameters: map[string][]string{"name": {"Ana", "Luis", "Eva"}},
+	})
+}
+
+func (suite *OpenAPITestSuite) TestMiddlewareDocumenters_ShouldNotModifyOperation() {
+	// Given
+	operation := suite.handler.Operation(http.MethodGet, hello.Rules{})
+
+	// When
+	documented := handlers.DocumentConditionalGET(handlers.DocumentJWTAuth(handlers.DocumentRateLimit(operation)))
+
+	// Then
+	suite.Contains(documented.Responses, "304")
+	suite.Contains(documented.Responses, "401")
+	suite.Contains(documented.Responses, "429")
+	suite.Contains(documented.Responses["200"].Headers, "ETag")
+	suite.Equal([]map[string][]string{{handlers.SecuritySchemeBearer: {}}}, documented.Security)
+
+	suite.NotContains(operation.Responses, "304")
+	suite.NotContains(operation.Responses, "429")
+	suite.Empty(operation.Responses["200"].Headers)
+	suite.Len(operation.Parameters, 1)
+}
+
+func (suite *OpenAPITestSuite) TestOpenAPIHandler_ShouldServeDocumentBuiltOnce() {
+	// Given
+	builds := 0
+	handler := handlers.OpenAPIHandler(func() *openapi.Document {
+		builds++
+		return &openapi.Document{OpenAPI: openapi.Version, Info: openapi.Info{Title: "Hello API", Version: "1.0.0"}}
+	})
+
+	// When
+	handler(suite.ctx, events.APIGatewayProxyRequest{})
+	response, err := handler(suite.ctx, events.APIGatewayProxyRequest{})
+
+	// Then
+	suite.NoError(err)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.Equal(handlers.MediaTypeJSON, response.Headers["Content-Type"])
+	suite.Contains(response.Body, `"openapi": "3.1.0"`)
+	suite.Equal(1, builds)
+}
+
+func (suite *OpenAPITestSuite) TestMalformedBodyExample_ShouldMatchServedError() {
+	// When / Then
+	suite.thenExampleShouldMatch(suite.handler.Operation(http.MethodPost, hello.Rules{}), events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodPost,
+		Headers:    map[string]string{"Content-Type": handlers.MediaTypeJSON},
+		Body:       `{"name":`,
+	})
+}
+
+func (suite *OpenAPITestSuite) TestConfiguredRules_ShouldBeDocumented() {
+	// Given
+	rules := hello.Rules{MaxNameLength: 5}
+	loggerService := new(service.MockLogger)
+	loggerService.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.handler = handlers.NewHelloHandler(loggerService, hello.Greeter{Rules: rules}.Greet, handlers.HelloHandlerConfig{})
+
+	// When
+	get := suite.handler.Operation(http.MethodGet, rules)
+	post := suite.handler.Operation(http.MethodPost, rules)
+	batch := handlers.HelloBatchOperation(handlers.BatchConfig{}, rules)
+
+	// Then
+	suite.Equal(openapi.Int(5), get.Parameters[0].Schema.Items.MaxLength)
+	suite.Equal(openapi.Int(5), post.RequestBody.Content[handlers.MediaTypeJSON].Schema.Properties["name"].MaxLength)
+	suite.Equal(openapi.Int(5), post.RequestBody.Content[handlers.MediaTypeForm].Schema.Properties["name"].Items.MaxLength)
+	suite.Equal(openapi.Int(5), batch.RequestBody.Content[handlers.MediaTypeJSON].Schema.OneOf[1].Items.MaxLength)
+	suite.thenExampleShouldMatch(get, events.APIGatewayProxyRequest{
+		HTTPMethod:            http.MethodGet,
+		QueryStringParameters: map[string]string{"name": "Joanna"},
+	})
+}
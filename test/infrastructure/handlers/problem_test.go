@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+)
+
+type ProblemDetailsTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	config   handlers.ProblemConfig
+	request  events.APIGatewayProxyRequest
+	response events.APIGatewayProxyResponse
+	err      error
+	problem  map[string]interface{}
+}
+
+func TestProblemDetailsTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(ProblemDetailsTestSuite))
+}
+
+func (suite *ProblemDetailsTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.config = handlers.ProblemConfig{}
+	suite.request = events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/hello",
+		RequestContext: events.APIGatewayProxyRequestContext{
+			RequestID: "req-abc-123",
+		},
+	}
+	suite.problem = nil
+	suite.err = nil
+}
+
+func (suite *ProblemDetailsTestSuite) givenDefaultFormat(format handlers.ErrorFormat) {
+	suite.config.DefaultFormat = format
+}
+
+func (suite *ProblemDetailsTestSuite) givenTypeBaseURI(uri string) {
+	suite.config.TypeBaseURI = uri
+}
+
+func (suite *ProblemDetailsTestSuite) givenName(name string) {
+	suite.request.QueryStringParameters = map[string]string{"name": name}
+}
+
+func (suite *ProblemDetailsTestSuite) givenAcceptHeader(accept string) {
+	suite.request.Headers = map[string]string{"Accept": accept}
+}
+
+func (suite *ProblemDetailsTestSuite) whenHelloHandlerIsCalledThroughProblemDetails() {
+	handler := handlers.Chain(newHelloHandler().Handle, handlers.ProblemDetails(suite.config))
+	suite.response, suite.err = handler(suite.ctx, suite.request)
+}
+
+func (suite *ProblemDetailsTestSuite) thenResponseShouldBeProblem(status int) {
+	suite.NoError(suite.err)
+	suite.Equal(status, suite.response.StatusCode)
+	suite.Equal("application/problem+json", suite.response.Headers["Content-Type"])
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &suite.problem))
+	suite.Equal(float64(status), suite.problem["status"])
+	suite.Equal("/hello", suite.problem["instance"])
+	suite.Equal("req-abc-123", suite.problem["request_id"])
+}
+
+func (suite *ProblemDetailsTestSuite) thenProblemFieldShouldBe(key string, value interface{}) {
+	suite.Equal(value, suite.problem[key], key)
+}
+
+func (suite *ProblemDetailsTestSuite) thenResponseShouldBeLegacyError(code string) {
+	suite.NoError(suite.err)
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal("400", body["status"])
+	suite.Equal(code, body["code"])
+	suite.NotEmpty(body["error"])
+}
+
+func (suite *ProblemDetailsTestSuite) TestLegacyDefault_ShouldKeepCurrentShape() {
+	// Given
+	suite.givenName("John@Doe")
+
+	// When
+	suite.whenHelloHandlerIsCalledThroughProblemDetails()
+
+	// Then
+	suite.thenResponseShouldBeLegacyError(handlers.CodeInvalidCharacters)
+}
+
+func (suite *ProblemDetailsTestSuite) TestProblemDefault_ShouldConvertValidationErrors() {
+	// Given
+	suite.givenDefaultFormat(handlers.ErrorFormatProblem)
+	suite.givenTypeBaseURI("https://example.com/problems/")
+	suite.givenName("John@Doe")
+
+	// When
+	suite.whenHelloHandlerIsCalledThroughProblemDetails()
+
+	// Then
+	suite.thenResponseShouldBeProblem(400)
+	suite.thenProblemFieldShouldBe("type", "https://example.com/problems/invalid-characters")
+	suite.thenProblemFieldShouldBe("title", "Bad Request")
+	suite.thenProblemFieldShouldBe("code", handlers.CodeInvalidCharacters)
+	suite.Contains(suite.problem["detail"], "contains invalid characters")
+}
+
+func (suite *ProblemDetailsTestSuite) TestAcceptProblemJSON_ShouldSelectProblemFormat() {
+	// Given
+	suite.givenName(strings.Repeat("a", 101))
+	suite.givenAcceptHeader("application/json, application/problem+json")
+
+	// When
+	suite.whenHelloHandlerIsCalledThroughProblemDetails()
+
+	// Then
+	suite.thenResponseShouldBeProblem(400)
+	suite.thenProblemFieldShouldBe("type", "about:blank")
+	suite.thenProblemFieldShouldBe("code", handlers.CodeNameTooLong)
+}
+
+func (suite *ProblemDetailsTestSuite) TestAcceptProblemJSONWithZeroQuality_ShouldKeepLegacyFormat() {
+	// Given
+	suite.givenName("John@Doe")
+	suite.givenAcceptHeader("application/json, application/problem+json;q=0")
+
+	// When
+	suite.whenHelloHandlerIsCalledThroughProblemDetails()
+
+	// Then
+	suite.thenResponseShouldBeLegacyError(handlers.CodeInvalidCharacters)
+}
+
+func (suite *ProblemDetailsTestSuite) TestNotAcceptable_ShouldUseStableCode() {
+	// Given
+	suite.givenDefaultFormat(handlers.ErrorFormatProblem)
+	suite.givenAcceptHeader("image/png")
+
+	// When
+	suite.whenHelloHandlerIsCalledThroughProblemDetails()
+
+	// Then
+	suite.thenResponseShouldBeProblem(406)
+	suite.thenProblemFieldShouldBe("code", handlers.CodeNotAcceptable)
+}
+
+func (suite *ProblemDetailsTestSuite) TestSuccess_ShouldPassThrough() {
+	// Given
+	suite.givenDefaultFormat(handlers.ErrorFormatProblem)
+	suite.givenName("Joe")
+
+	// When
+	suite.whenHelloHandlerIsCalledThroughProblemDetails()
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+}
+
+func (suite *ProblemDetailsTestSuite) TestSuccess_WithAcceptProblemJSON_ShouldGreet() {
+	// Given
+	suite.givenName("Joe")
+	suite.givenAcceptHeader("application/problem+json")
+
+	// When
+	suite.whenHelloHandlerIsCalledThroughProblemDetails()
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+	suite.JSONEq(`{"message":"Hello Joe!","name":"Joe"}`, suite.response.Body)
+}
+
+func (suite *ProblemDetailsTestSuite) TestErrorResponse_ShouldDeriveCodeFromStatus() {
+	// When
+	response, err := handlers.ErrorResponse(503, "Service unavailable")
+
+	// Then
+	suite.NoError(err)
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(response.Body), &body))
+	suite.Equal("SERVICE_UNAVAILABLE", body["code"])
+	suite.Equal("503", body["status"])
+}
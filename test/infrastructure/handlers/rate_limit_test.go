@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/ratelimit"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit, time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+type RateLimitTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	logger   *service.MockLogger
+	config   handlers.RateLimitConfig
+	now      time.Time
+	request  events.APIGatewayProxyRequest
+	response events.APIGatewayProxyResponse
+	err      error
+	calls    int
+}
+
+func TestRateLimitTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(RateLimitTestSuite))
+}
+
+func (suite *RateLimitTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.config = handlers.RateLimitConfig{
+		Store:  ratelimit.NewMemoryStore(),
+		Limit:  ratelimit.Limit{Requests: 1, Per: 2 * time.Second, Burst: 2},
+		Logger: suite.logger,
+		Now:    func() time.Time { return suite.now },
+	}
+	suite.request = events.APIGatewayProxyRequest{
+		HTTPMethod: "GET",
+		Path:       "/hello",
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Identity: events.APIGatewayRequestIdentity{SourceIP: "203.0.113.7"},
+		},
+	}
+	suite.calls = 0
+	suite.err = nil
+}
+
+func (suite *RateLimitTestSuite) givenSourceIP(ip string) {
+	suite.request.RequestContext.Identity.SourceIP = ip
+}
+
+func (suite *RateLimitTestSuite) givenAPIKey(apiKey string) {
+	suite.request.RequestContext.Identity.APIKey = apiKey
+}
+
+func (suite *RateLimitTestSuite) givenAPIKeyHeader(apiKey string) {
+	suite.request.Headers = map[string]string{"X-Api-Key": apiKey}
+}
+
+func (suite *RateLimitTestSuite) givenUserInContext(userID string) {
+	suite.ctx = context.WithValue(suite.ctx, "user_id", userID)
+}
+
+func (suite *RateLimitTestSuite) whenRequestsAreMade(times int) {
+	handler := handlers.Chain(func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		suite.calls++
+		return events.APIGatewayProxyResponse{StatusCode: 200, Body: "ok"}, nil
+	}, handlers.RateLimit(suite.config))
+
+	for i := 0; i < times; i++ {
+		suite.response, suite.err = handler(suite.ctx, suite.request)
+	}
+}
+
+func (suite *RateLimitTestSuite) thenResponseShouldBeAllowed(remaining string) {
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal("2", suite.response.Headers["X-RateLimit-Limit"])
+	suite.Equal(remaining, suite.response.Headers["X-RateLimit-Remaining"])
+}
+
+func (suite *RateLimitTestSuite) thenResponseShouldBeTooManyRequests(retryAfter string) {
+	suite.NoError(suite.err)
+	suite.Equal(429, suite.response.StatusCode)
+	suite.Equal(retryAfter, suite.response.Headers["Retry-After"])
+	suite.Equal("2", suite.response.Headers["X-RateLimit-Limit"])
+	suite.Equal("0", suite.response.Headers["X-RateLimit-Remaining"])
+	suite.Equal("4", suite.response.Headers["X-RateLimit-Reset"])
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal(handlers.CodeRateLimited, body["code"])
+	suite.Equal("429", body["status"])
+}
+
+func (suite *RateLimitTestSuite) thenHandlerShouldHaveBeenCalled(times int) {
+	suite.Equal(times, suite.calls)
+}
+
+func (suite *RateLimitTestSuite) TestUnderLimit_ShouldServeWithHeaders() {
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.thenResponseShouldBeAllowed("1")
+	suite.Equal("2", suite.response.Headers["X-RateLimit-Reset"])
+	suite.thenHandlerShouldHaveBeenCalled(1)
+}
+
+func (suite *RateLimitTestSuite) TestOverLimit_ShouldReturnTooManyRequests() {
+	// When
+	suite.whenRequestsAreMade(3)
+
+	// Then
+	suite.thenResponseShouldBeTooManyRequests("2")
+	suite.thenHandlerShouldHaveBeenCalled(2)
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelWarn, "Rate limit exceeded", mock.Anything)
+}
+
+func (suite *RateLimitTestSuite) TestRefill_ShouldAllowAgain() {
+	// Given
+	suite.whenRequestsAreMade(3)
+	suite.now = suite.now.Add(2 * time.Second)
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.thenResponseShouldBeAllowed("0")
+	suite.thenHandlerShouldHaveBeenCalled(3)
+}
+
+func (suite *RateLimitTestSuite) TestDifferentSourceIPs_ShouldBeLimitedSeparately() {
+	// Given
+	suite.whenRequestsAreMade(3)
+	suite.givenSourceIP("198.51.100.1")
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.thenResponseShouldBeAllowed("1")
+}
+
+func (suite *RateLimitTestSuite) TestAPIKey_ShouldTakePrecedenceOverSourceIP() {
+	// Given
+	suite.givenAPIKey("key-1")
+	suite.whenRequestsAreMade(3)
+	suite.givenSourceIP("198.51.100.1")
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.thenResponseShouldBeTooManyRequests("2")
+}
+
+func (suite *RateLimitTestSuite) TestUser_ShouldTakePrecedenceOverAPIKey() {
+	// Given
+	suite.givenAPIKey("shared-key")
+	suite.givenUserInContext("user-1")
+	suite.whenRequestsAreMade(3)
+	suite.ctx = context.Background()
+	suite.givenUserInContext("user-2")
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.thenResponseShouldBeAllowed("1")
+}
+
+func (suite *RateLimitTestSuite) TestAPIKeyHeader_ShouldNotEscapeSourceIPLimit() {
+	// Given
+	for i := 0; i < 3; i++ {
+		suite.givenAPIKeyHeader(fmt.Sprintf("random-%d", i))
+		suite.whenRequestsAreMade(1)
+	}
+	suite.givenAPIKeyHeader("random-3")
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.thenResponseShouldBeTooManyRequests("2")
+}
+
+func (suite *RateLimitTestSuite) TestAPIKeyHeader_WhenOptedIn_ShouldKeyByHeader() {
+	// Given
+	suite.config.KeyFunc = handlers.RateLimitKeyChain(handlers.RateLimitByAPIKeyHeader, handlers.RateLimitBySourceIP)
+	suite.givenAPIKeyHeader("key-1")
+	suite.whenRequestsAreMade(3)
+	suite.givenAPIKeyHeader("key-2")
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.thenResponseShouldBeAllowed("1")
+}
+
+func (suite *RateLimitTestSuite) TestCustomKeyFunc_ShouldBeUsed() {
+	// Given
+	suite.config.KeyFunc = handlers.RateLimitBySourceIP
+	suite.givenAPIKeyHeader("key-1")
+	suite.whenRequestsAreMade(3)
+	suite.givenAPIKeyHeader("key-2")
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.thenResponseShouldBeTooManyRequests("2")
+}
+
+func (suite *RateLimitTestSuite) TestNoKey_ShouldNotLimit() {
+	// Given
+	suite.givenSourceIP("")
+
+	// When
+	suite.whenRequestsAreMade(5)
+
+	// Then
+	suite.thenHandlerShouldHaveBeenCalled(5)
+	suite.Empty(suite.response.Headers["X-RateLimit-Limit"])
+}
+
+func (suite *RateLimitTestSuite) TestInvalidLimit_ShouldPanicAtConstruction() {
+	// Given
+	suite.config.Limit = ratelimit.Limit{Requests: 10, Per: 0}
+
+	// When / Then
+	suite.PanicsWithValue("handlers: invalid rate limit: per must be positive, got 0s", func() {
+		handlers.RateLimit(suite.config)
+	})
+}
+
+func (suite *RateLimitTestSuite) TestZeroLimit_ShouldUseDefault() {
+	// Given
+	suite.config.Limit = ratelimit.Limit{}
+	suite.givenSourceIP("203.0.113.7")
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal(fmt.Sprint(handlers.DefaultRateLimit.Burst), suite.response.Headers["X-RateLimit-Limit"])
+}
+
+func (suite *RateLimitTestSuite) TestStoreFailure_ShouldFailOpen() {
+	// Given
+	suite.config.Store = failingStore{}
+
+	// When
+	suite.whenRequestsAreMade(1)
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelError, "Rate limit store failed", mock.Anything)
+}
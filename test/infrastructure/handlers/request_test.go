@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+)
+
+type RequestAccessorsTestSuite struct {
+	suite.Suite
+	request events.APIGatewayProxyRequest
+}
+
+func TestRequestAccessorsTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(RequestAccessorsTestSuite))
+}
+
+func (suite *RequestAccessorsTestSuite) SetupTest() {
+	suite.request = events.APIGatewayProxyRequest{}
+}
+
+func (suite *RequestAccessorsTestSuite) givenQuery(single map[string]string, multi map[string][]string) {
+	suite.request.QueryStringParameters = single
+	suite.request.MultiValueQueryStringParameters = multi
+}
+
+func (suite *RequestAccessorsTestSuite) givenHeaders(single map[string]string, multi map[string][]string) {
+	suite.request.Headers = single
+	suite.request.MultiValueHeaders = multi
+}
+
+func (suite *RequestAccessorsTestSuite) TestQueryValues_ShouldPreferMultiValueMap() {
+	// Given
+	suite.givenQuery(map[string]string{"name": "Luis"}, map[string][]string{"name": {"Ana", "Luis"}})
+
+	// Then
+	suite.Equal([]string{"Ana", "Luis"}, handlers.QueryValues(suite.request, "name"))
+	suite.Equal("Ana", handlers.QueryValue(suite.request, "name"))
+}
+
+func (suite *RequestAccessorsTestSuite) TestQueryValues_ShouldFallBackToSingleValueMap() {
+	// Given
+	suite.givenQuery(map[string]string{"name": "Ana"}, nil)
+
+	// Then
+	suite.Equal([]string{"Ana"}, handlers.QueryValues(suite.request, "name"))
+	suite.Equal("Ana", handlers.QueryValue(suite.request, "name"))
+}
+
+func (suite *RequestAccessorsTestSuite) TestQueryValues_ShouldKeepEmptyValue() {
+	// Given
+	suite.givenQuery(map[string]string{"name": ""}, nil)
+
+	// Then
+	suite.Equal([]string{""}, handlers.QueryValues(suite.request, "name"))
+}
+
+func (suite *RequestAccessorsTestSuite) TestQueryValues_MissingParameter_ShouldBeEmpty() {
+	// Given
+	suite.givenQuery(map[string]string{"other": "x"}, map[string][]string{"other": {"x"}})
+
+	// Then
+	suite.Nil(handlers.QueryValues(suite.request, "name"))
+	suite.Equal("", handlers.QueryValue(suite.request, "name"))
+}
+
+func (suite *RequestAccessorsTestSuite) TestQueryValues_ShouldBeCaseSensitive() {
+	// Given
+	suite.givenQuery(map[string]string{"Name": "Ana"}, nil)
+
+	// Then
+	suite.Nil(handlers.QueryValues(suite.request, "name"))
+}
+
+func (suite *RequestAccessorsTestSuite) TestHeaderValues_ShouldPreferMultiValueMap() {
+	// Given
+	suite.givenHeaders(map[string]string{"Accept": "text/html"}, map[string][]string{"accept": {"application/json", "text/html"}})
+
+	// Then
+	suite.Equal([]string{"application/json", "text/html"}, handlers.HeaderValues(suite.request, "Accept"))
+	suite.Equal("application/json", handlers.HeaderValue(suite.request, "ACCEPT"))
+}
+
+func (suite *RequestAccessorsTestSuite) TestHeaderValues_ShouldFallBackToSingleValueMap() {
+	// Given
+	suite.givenHeaders(map[string]string{"x-api-key": "k1"}, nil)
+
+	// Then
+	suite.Equal([]string{"k1"}, handlers.HeaderValues(suite.request, "X-Api-Key"))
+	suite.Equal("k1", handlers.HeaderValue(suite.request, "X-Api-Key"))
+}
+
+func (suite *RequestAccessorsTestSuite) TestHeaderValues_MissingHeader_ShouldBeEmpty() {
+	// Then
+	suite.Nil(handlers.HeaderValues(suite.request, "Accept"))
+	suite.Equal("", handlers.HeaderValue(suite.request, "Accept"))
+}
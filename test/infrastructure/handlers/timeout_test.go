@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type TimeoutTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	cancel    context.CancelFunc
+	logger    *service.MockLogger
+	config    handlers.TimeoutConfig
+	work      time.Duration
+	cancelled chan error
+	response  events.APIGatewayProxyResponse
+	err       error
+}
+
+func TestTimeoutTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(TimeoutTestSuite))
+}
+
+func (suite *TimeoutTestSuite) SetupTest() {
+	suite.ctx, suite.cancel = context.Background(), func() {}
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.config = handlers.TimeoutConfig{Logger: suite.logger}
+	suite.cancelled = make(chan error, 1)
+	suite.err = nil
+}
+
+func (suite *TimeoutTestSuite) TearDownTest() {
+	suite.cancel()
+}
+
+func (suite *TimeoutTestSuite) givenInvocationDeadlineIn(d time.Duration) {
+	suite.ctx, suite.cancel = context.WithTimeout(context.Background(), d)
+}
+
+func (suite *TimeoutTestSuite) givenWorkTaking(d time.Duration) {
+	suite.work = d
+}
+
+func (suite *TimeoutTestSuite) whenRequestIsHandled() {
+	// The handler outlives the request on timeout, so it must not touch the suite.
+	work, cancelled := suite.work, suite.cancelled
+	handler := handlers.Chain(func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		select {
+		case <-time.After(work):
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "done"}, nil
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+			return events.APIGatewayProxyResponse{}, ctx.Err()
+		}
+	}, handlers.Timeout(suite.config))
+
+	suite.response, suite.err = handler(suite.ctx, events.APIGatewayProxyRequest{HTTPMethod: http.MethodGet, Path: "/hello"})
+}
+
+func (suite *TimeoutTestSuite) thenResponseShouldBeGatewayTimeout() {
+	suite.NoError(suite.err)
+	suite.Equal(http.StatusGatewayTimeout, suite.response.StatusCode)
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal("504", body["status"])
+	suite.Equal(handlers.CodeGatewayTimeout, body["code"])
+	suite.NotEmpty(body["error"])
+
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, services.LevelError, "Request timed out", mock.Anything)
+}
+
+func (suite *TimeoutTestSuite) thenWorkShouldBeCancelled() {
+	select {
+	case err := <-suite.cancelled:
+		suite.True(errors.Is(err, context.DeadlineExceeded))
+	case <-time.After(time.Second):
+		suite.Fail("handler context was not cancelled")
+	}
+}
+
+func (suite *TimeoutTestSuite) TestFastHandler_ShouldPassThrough() {
+	// Given
+	suite.givenInvocationDeadlineIn(time.Second)
+	suite.givenWorkTaking(0)
+
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(http.StatusOK, suite.response.StatusCode)
+	suite.logger.AssertNotCalled(suite.T(), "Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *TimeoutTestSuite) TestSlowHandler_ShouldTimeOutBeforeDeadline() {
+	// Given
+	suite.givenInvocationDeadlineIn(time.Second)
+	suite.config.SafetyMargin = 900 * time.Millisecond
+	suite.givenWorkTaking(time.Minute)
+	started := time.Now()
+
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.thenResponseShouldBeGatewayTimeout()
+	suite.thenWorkShouldBeCancelled()
+	suite.Less(time.Since(started), 500*time.Millisecond)
+}
+
+func (suite *TimeoutTestSuite) TestDefaultSafetyMargin_ShouldBeReserved() {
+	// Given
+	suite.givenInvocationDeadlineIn(handlers.DefaultTimeoutSafetyMargin + 50*time.Millisecond)
+	suite.givenWorkTaking(time.Minute)
+
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.thenResponseShouldBeGatewayTimeout()
+	suite.NoError(suite.ctx.Err(), "the 504 must be sent before the invocation deadline")
+}
+
+func (suite *TimeoutTestSuite) TestDeadlineWithinMargin_ShouldTimeOutImmediately() {
+	// Given
+	suite.givenInvocationDeadlineIn(100 * time.Millisecond)
+	suite.givenWorkTaking(time.Minute)
+
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.thenResponseShouldBeGatewayTimeout()
+}
+
+func (suite *TimeoutTestSuite) TestNoDeadline_ShouldRunUnbounded() {
+	// Given
+	suite.givenWorkTaking(20 * time.Millisecond)
+
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.NoError(suite.err)
+	suite.Equal(http.StatusOK, suite.response.StatusCode)
+}
+
+func (suite *TimeoutTestSuite) TestTimeout_ShouldCapRequestsWithoutDeadline() {
+	// Given
+	suite.config.Timeout = 50 * time.Millisecond
+	suite.givenWorkTaking(time.Minute)
+
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.thenResponseShouldBeGatewayTimeout()
+	suite.thenWorkShouldBeCancelled()
+}
+
+func (suite *TimeoutTestSuite) TestTimeout_ShouldCapLongInvocationDeadline() {
+	// Given
+	suite.givenInvocationDeadlineIn(time.Minute)
+	suite.config.Timeout = 50 * time.Millisecond
+	suite.givenWorkTaking(time.Minute)
+
+	// When
+	suite.whenRequestIsHandled()
+
+	// Then
+	suite.thenResponseShouldBeGatewayTimeout()
+}
+
+func (suite *TimeoutTestSuite) TestPanic_ShouldReachOuterRecover() {
+	// Given
+	suite.givenInvocationDeadlineIn(time.Second)
+	handler := handlers.Chain(func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		panic("boom")
+	}, handlers.Recover(suite.logger), handlers.Timeout(suite.config))
+
+	// When
+	response, err := handler(suite.ctx, events.APIGatewayProxyRequest{})
+
+	// Then
+	suite.NoError(err)
+	suite.Equal(http.StatusInternalServerError, response.StatusCode)
+}
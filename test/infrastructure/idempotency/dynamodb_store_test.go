@@ -0,0 +1,181 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/idempotency"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type DynamoDBStoreTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	table    *service.DynamoDBStandIn
+	store    *idempotency.DynamoDBStore
+	now      time.Time
+	record   idempotency.Record
+	reserved bool
+	err      error
+}
+
+func TestDynamoDBStoreTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(DynamoDBStoreTestSuite))
+}
+
+func (suite *DynamoDBStoreTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.table = service.NewDynamoDBStandIn()
+	suite.store = idempotency.NewDynamoDBStore(suite.table, idempotency.DynamoDBStoreConfig{Table: "idempotency"})
+	suite.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.err = nil
+}
+
+func (suite *DynamoDBStoreTestSuite) inProgress(key string) idempotency.Record {
+	return idempotency.Record{
+		Key:         key,
+		Status:      idempotency.StatusInProgress,
+		Fingerprint: "fp",
+		ExpiresAt:   suite.now.Add(30 * time.Second),
+	}
+}
+
+func (suite *DynamoDBStoreTestSuite) givenCompletedRecord(key string) {
+	suite.Require().NoError(suite.store.Complete(suite.ctx, idempotency.Record{
+		Key:         key,
+		Status:      idempotency.StatusCompleted,
+		Fingerprint: "fp",
+		Response: &idempotency.Response{
+			StatusCode: 201,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       `{"id":1}`,
+		},
+		ExpiresAt: suite.now.Add(time.Hour),
+	}))
+}
+
+func (suite *DynamoDBStoreTestSuite) givenTimePasses(d time.Duration) {
+	suite.now = suite.now.Add(d)
+}
+
+func (suite *DynamoDBStoreTestSuite) whenReserveIsCalled(key string) {
+	suite.record, suite.reserved, suite.err = suite.store.Reserve(suite.ctx, suite.inProgress(key), suite.now)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestReserve_ShouldPersistInProgressRecord() {
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.True(suite.reserved)
+
+	item := suite.table.Item("k1")
+	suite.Equal(&types.AttributeValueMemberS{Value: "IN_PROGRESS"}, item["status"])
+	suite.Equal(&types.AttributeValueMemberS{Value: "fp"}, item["fingerprint"])
+	suite.Equal(&types.AttributeValueMemberN{Value: "1704067230"}, item["expires_at"])
+	suite.NotContains(item, "response")
+}
+
+func (suite *DynamoDBStoreTestSuite) TestSecondReserve_ShouldReturnInProgressRecord() {
+	// Given
+	suite.whenReserveIsCalled("k1")
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.False(suite.reserved)
+	suite.Equal(idempotency.StatusInProgress, suite.record.Status)
+	suite.Equal("fp", suite.record.Fingerprint)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestReserve_ShouldReturnCompletedResponse() {
+	// Given
+	suite.givenCompletedRecord("k1")
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.False(suite.reserved)
+	suite.Equal(idempotency.StatusCompleted, suite.record.Status)
+	suite.Equal(&idempotency.Response{
+		StatusCode: 201,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       `{"id":1}`,
+	}, suite.record.Response)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestExpiredRecord_ShouldBeTakenOverBeforeTTLDeletion() {
+	// Given
+	suite.whenReserveIsCalled("k1")
+	suite.givenTimePasses(30 * time.Second)
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.True(suite.reserved)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestRelease_ShouldOnlyDeleteInProgressRecords() {
+	// Given
+	suite.whenReserveIsCalled("k1")
+	suite.givenCompletedRecord("k2")
+
+	// When
+	suite.Require().NoError(suite.store.Release(suite.ctx, "k1"))
+	suite.Require().NoError(suite.store.Release(suite.ctx, "k2"))
+
+	// Then
+	suite.Nil(suite.table.Item("k1"))
+	suite.NotNil(suite.table.Item("k2"))
+}
+
+func (suite *DynamoDBStoreTestSuite) TestConcurrentReserves_ShouldSucceedOnce() {
+	// Given
+	var reserved int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// When
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := suite.store.Reserve(suite.ctx, suite.inProgress("k1"), suite.now)
+			suite.NoError(err)
+			if ok {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	suite.Equal(1, reserved)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestTableError_ShouldBeReturned() {
+	// Given
+	suite.table.Err = errors.New("throttled")
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.ErrorContains(suite.err, "throttled")
+}
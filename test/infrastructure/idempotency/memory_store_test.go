@@ -0,0 +1,173 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/idempotency"
+)
+
+type MemoryStoreTestSuite struct {
+	suite.Suite
+	ctx      context.Context
+	store    *idempotency.MemoryStore
+	now      time.Time
+	record   idempotency.Record
+	reserved bool
+	err      error
+}
+
+func TestMemoryStoreTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(MemoryStoreTestSuite))
+}
+
+func (suite *MemoryStoreTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.store = idempotency.NewMemoryStore()
+	suite.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.err = nil
+}
+
+func (suite *MemoryStoreTestSuite) inProgress(key string) idempotency.Record {
+	return idempotency.Record{
+		Key:         key,
+		Status:      idempotency.StatusInProgress,
+		Fingerprint: "fp",
+		ExpiresAt:   suite.now.Add(30 * time.Second),
+	}
+}
+
+func (suite *MemoryStoreTestSuite) givenCompletedRecord(key string, ttl time.Duration) {
+	suite.Require().NoError(suite.store.Complete(suite.ctx, idempotency.Record{
+		Key:       key,
+		Status:    idempotency.StatusCompleted,
+		Response:  &idempotency.Response{StatusCode: 201, Body: `{"id":1}`},
+		ExpiresAt: suite.now.Add(ttl),
+	}))
+}
+
+func (suite *MemoryStoreTestSuite) givenTimePasses(d time.Duration) {
+	suite.now = suite.now.Add(d)
+}
+
+func (suite *MemoryStoreTestSuite) whenReserveIsCalled(key string) {
+	suite.record, suite.reserved, suite.err = suite.store.Reserve(suite.ctx, suite.inProgress(key), suite.now)
+}
+
+func (suite *MemoryStoreTestSuite) TestFirstReserve_ShouldSucceed() {
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.True(suite.reserved)
+	suite.Equal(idempotency.StatusInProgress, suite.record.Status)
+}
+
+func (suite *MemoryStoreTestSuite) TestSecondReserve_ShouldReturnInProgressRecord() {
+	// Given
+	suite.whenReserveIsCalled("k1")
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.NoError(suite.err)
+	suite.False(suite.reserved)
+	suite.Equal(idempotency.StatusInProgress, suite.record.Status)
+	suite.Equal("fp", suite.record.Fingerprint)
+}
+
+func (suite *MemoryStoreTestSuite) TestReserve_ShouldReturnCompletedRecord() {
+	// Given
+	suite.givenCompletedRecord("k1", time.Hour)
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.False(suite.reserved)
+	suite.Equal(idempotency.StatusCompleted, suite.record.Status)
+	suite.Equal(201, suite.record.Response.StatusCode)
+}
+
+func (suite *MemoryStoreTestSuite) TestExpiredLock_ShouldBeTakenOver() {
+	// Given
+	suite.whenReserveIsCalled("k1")
+	suite.givenTimePasses(30 * time.Second)
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.True(suite.reserved)
+}
+
+func (suite *MemoryStoreTestSuite) TestExpiredRecord_ShouldBeSwept() {
+	// Given
+	suite.givenCompletedRecord("k1", time.Minute)
+	suite.givenTimePasses(2 * time.Minute)
+
+	// When
+	suite.whenReserveIsCalled("k2")
+
+	// Then
+	suite.True(suite.reserved)
+	suite.whenReserveIsCalled("k1")
+	suite.True(suite.reserved)
+}
+
+func (suite *MemoryStoreTestSuite) TestRelease_ShouldAllowRetry() {
+	// Given
+	suite.whenReserveIsCalled("k1")
+	suite.Require().NoError(suite.store.Release(suite.ctx, "k1"))
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.True(suite.reserved)
+}
+
+func (suite *MemoryStoreTestSuite) TestRelease_ShouldKeepCompletedRecord() {
+	// Given
+	suite.givenCompletedRecord("k1", time.Hour)
+	suite.Require().NoError(suite.store.Release(suite.ctx, "k1"))
+
+	// When
+	suite.whenReserveIsCalled("k1")
+
+	// Then
+	suite.False(suite.reserved)
+}
+
+func (suite *MemoryStoreTestSuite) TestConcurrentReserves_ShouldSucceedOnce() {
+	// Given
+	var reserved int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// When
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := suite.store.Reserve(suite.ctx, suite.inProgress("k1"), suite.now)
+			suite.NoError(err)
+			if ok {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	suite.Equal(1, reserved)
+}
@@ -0,0 +1,123 @@
+package idempotency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/idempotency"
+)
+
+type SelectorTestSuite struct {
+	suite.Suite
+	selector *idempotency.Selector
+	key      string
+	found    bool
+	err      error
+}
+
+func TestSelectorTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(SelectorTestSuite))
+}
+
+func (suite *SelectorTestSuite) SetupTest() {
+	suite.selector = nil
+	suite.err = nil
+}
+
+const payload = `{
+	"order": {"id": 1042, "total": 9.5, "lines": [{"sku": "A-1"}, {"sku": "B-2"}]},
+	"customer": {"id": "c-7"},
+	"request-id": "r-1"
+}`
+
+func (suite *SelectorTestSuite) givenSelector(expression string) {
+	suite.selector, suite.err = idempotency.ParseSelector(expression)
+	suite.Require().NoError(suite.err)
+}
+
+func (suite *SelectorTestSuite) whenKeyIsSelected(payload string) {
+	suite.key, suite.found = suite.selector.Key([]byte(payload))
+}
+
+func (suite *SelectorTestSuite) thenKeyShouldBe(expected string) {
+	suite.True(suite.found)
+	suite.Equal(expected, suite.key)
+}
+
+func (suite *SelectorTestSuite) TestSelectors_ShouldSelectValues() {
+	cases := map[string]string{
+		"order.id":                  `1042`,
+		"customer.id":               `"c-7"`,
+		`"request-id"`:              `"r-1"`,
+		"order.lines[0].sku":        `"A-1"`,
+		"order.lines[-1].sku":       `"B-2"`,
+		"[customer.id, order.id]":   `["c-7",1042]`,
+		"order.[id, total]":         `[1042,9.5]`,
+		"order":                     `{"id":1042,"lines":[{"sku":"A-1"},{"sku":"B-2"}],"total":9.5}`,
+		" order . lines [ 1 ] .sku": `"B-2"`,
+	}
+
+	for expression, expected := range cases {
+		// Given
+		suite.givenSelector(expression)
+
+		// When
+		suite.whenKeyIsSelected(payload)
+
+		// Then
+		suite.True(suite.found, expression)
+		suite.Equal(expected, suite.key, expression)
+	}
+}
+
+func (suite *SelectorTestSuite) TestMissingValue_ShouldSelectNothing() {
+	for _, expression := range []string{"order.missing", "order.lines[5].sku", "customer.id.deeper", "[a, b.c]"} {
+		// Given
+		suite.givenSelector(expression)
+
+		// When
+		suite.whenKeyIsSelected(payload)
+
+		// Then
+		suite.False(suite.found, expression)
+	}
+}
+
+func (suite *SelectorTestSuite) TestPartialMultiSelect_ShouldKeepNulls() {
+	// Given
+	suite.givenSelector("[customer.id, missing]")
+
+	// When
+	suite.whenKeyIsSelected(payload)
+
+	// Then
+	suite.thenKeyShouldBe(`["c-7",null]`)
+}
+
+func (suite *SelectorTestSuite) TestNonJSONPayload_ShouldSelectNothing() {
+	// Given
+	suite.givenSelector("order.id")
+
+	// When
+	suite.whenKeyIsSelected("name=Ana")
+
+	// Then
+	suite.False(suite.found)
+}
+
+func (suite *SelectorTestSuite) TestInvalidExpressions_ShouldFail() {
+	for _, expression := range []string{"", "order.", "order..id", "order[x]", "[a, b", `"unterminated`, "order.id]", "1abc"} {
+		// When
+		_, err := idempotency.ParseSelector(expression)
+
+		// Then
+		suite.Error(err, expression)
+	}
+}
+
+func (suite *SelectorTestSuite) TestMustParseSelector_ShouldPanicOnInvalidExpression() {
+	suite.Panics(func() { idempotency.MustParseSelector("order.") })
+	suite.Equal("order.id", idempotency.MustParseSelector("order.id").String())
+}
@@ -0,0 +1,78 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/openapi"
+)
+
+type DocumentTestSuite struct {
+	suite.Suite
+	operation openapi.Operation
+}
+
+func TestDocumentTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(DocumentTestSuite))
+}
+
+func (suite *DocumentTestSuite) SetupTest() {
+	suite.operation = openapi.Operation{
+		Parameters: []openapi.Parameter{{Name: "name", In: openapi.InQuery}},
+		Responses:  map[string]openapi.Response{"200": {Description: "Greeting"}},
+	}
+}
+
+func (suite *DocumentTestSuite) TestWithResponse_ShouldAddMissingStatus() {
+	// When
+	operation := suite.operation.WithResponse(429, openapi.Response{Description: "Rate limited"})
+
+	// Then
+	suite.Equal("Rate limited", operation.Responses["429"].Description)
+	suite.NotContains(suite.operation.Responses, "429")
+}
+
+func (suite *DocumentTestSuite) TestWithResponse_ShouldKeepDocumentedStatus() {
+	// When
+	operation := suite.operation.WithResponse(200, openapi.Response{Description: "Other"})
+
+	// Then
+	suite.Equal("Greeting", operation.Responses["200"].Description)
+}
+
+func (suite *DocumentTestSuite) TestWithParameter_ShouldNotShareParameters() {
+	// Given
+	first := suite.operation.WithParameter(openapi.Parameter{Name: "If-None-Match", In: openapi.InHeader})
+
+	// When
+	second := suite.operation.WithParameter(openapi.Parameter{Name: "Idempotency-Key", In: openapi.InHeader})
+
+	// Then
+	suite.Equal("If-None-Match", first.Parameters[1].Name)
+	suite.Equal("Idempotency-Key", second.Parameters[1].Name)
+	suite.Len(suite.operation.Parameters, 1)
+}
+
+func (suite *DocumentTestSuite) TestWriteFile_ShouldWriteReadableJSON() {
+	// Given
+	path := filepath.Join(suite.T().TempDir(), "openapi.json")
+	document := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info:    openapi.Info{Title: "Hello API", Version: "1.0.0", Description: "Greets <names>"},
+		Paths:   map[string]openapi.PathItem{"/hello": {"get": &suite.operation}},
+	}
+
+	// When
+	err := document.WriteFile(path)
+
+	// Then
+	suite.Require().NoError(err)
+	data, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Contains(string(data), "\n  \"openapi\": \"3.1.0\",\n")
+	suite.Contains(string(data), `"description": "Greets <names>"`)
+}
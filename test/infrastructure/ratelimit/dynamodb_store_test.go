@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/ratelimit"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type DynamoDBStoreTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	table   *service.DynamoDBStandIn
+	store   *ratelimit.DynamoDBStore
+	limit   ratelimit.Limit
+	now     time.Time
+	results []ratelimit.Result
+	err     error
+}
+
+func TestDynamoDBStoreTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(DynamoDBStoreTestSuite))
+}
+
+func (suite *DynamoDBStoreTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.table = service.NewDynamoDBStandIn()
+	suite.store = ratelimit.NewDynamoDBStore(suite.table, ratelimit.DynamoDBStoreConfig{Table: "rate-limits"})
+	suite.limit = ratelimit.Limit{Requests: 2, Per: time.Second, Burst: 2}
+	suite.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.results = nil
+	suite.err = nil
+}
+
+func (suite *DynamoDBStoreTestSuite) givenTimePasses(d time.Duration) {
+	suite.now = suite.now.Add(d)
+}
+
+func (suite *DynamoDBStoreTestSuite) whenTakeIsCalled(key string, times int) {
+	for i := 0; i < times; i++ {
+		var result ratelimit.Result
+		result, suite.err = suite.store.Take(suite.ctx, key, suite.limit, suite.now)
+		suite.results = append(suite.results, result)
+	}
+}
+
+func (suite *DynamoDBStoreTestSuite) thenAllowedShouldBe(expected ...bool) {
+	suite.NoError(suite.err)
+
+	actual := make([]bool, len(suite.results))
+	for i, result := range suite.results {
+		actual[i] = result.Allowed
+	}
+	suite.Equal(expected, actual)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestTake_ShouldPersistBucket() {
+	// When
+	suite.whenTakeIsCalled("ip:1", 1)
+
+	// Then
+	suite.thenAllowedShouldBe(true)
+
+	item := suite.table.Item("ip:1")
+	suite.Equal(&types.AttributeValueMemberN{Value: "1"}, item["tokens"])
+	suite.Equal(&types.AttributeValueMemberN{Value: "1"}, item["version"])
+	suite.Equal(&types.AttributeValueMemberN{Value: "1704067201"}, item["expires_at"])
+}
+
+func (suite *DynamoDBStoreTestSuite) TestLimit_ShouldBeSharedAcrossStores() {
+	// Given
+	other := ratelimit.NewDynamoDBStore(suite.table, ratelimit.DynamoDBStoreConfig{Table: "rate-limits"})
+	suite.whenTakeIsCalled("ip:1", 2)
+
+	// When
+	result, err := other.Take(suite.ctx, "ip:1", suite.limit, suite.now)
+
+	// Then
+	suite.NoError(err)
+	suite.False(result.Allowed)
+	suite.Equal(500*time.Millisecond, result.RetryAfter)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestRefill_ShouldUseStoredTimestamp() {
+	// Given
+	suite.whenTakeIsCalled("ip:1", 3)
+	suite.givenTimePasses(time.Second)
+
+	// When
+	suite.whenTakeIsCalled("ip:1", 3)
+
+	// Then
+	suite.thenAllowedShouldBe(true, true, false, true, true, false)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestConcurrentTakes_ShouldUseOptimisticLocking() {
+	// Given
+	suite.store = ratelimit.NewDynamoDBStore(suite.table, ratelimit.DynamoDBStoreConfig{Table: "rate-limits", MaxAttempts: 1000})
+	suite.limit = ratelimit.Limit{Requests: 1, Per: time.Hour, Burst: 10}
+	var allowed int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// When
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := suite.store.Take(suite.ctx, "ip:1", suite.limit, suite.now)
+			suite.NoError(err)
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	suite.Equal(10, allowed)
+}
+
+func (suite *DynamoDBStoreTestSuite) TestClientError_ShouldBeReturned() {
+	// Given
+	suite.table.Err = errors.New("connection refused")
+
+	// When
+	suite.whenTakeIsCalled("ip:1", 1)
+
+	// Then
+	suite.ErrorContains(suite.err, "connection refused")
+}
@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/ratelimit"
+)
+
+type MemoryStoreTestSuite struct {
+	suite.Suite
+	ctx     context.Context
+	store   *ratelimit.MemoryStore
+	limit   ratelimit.Limit
+	now     time.Time
+	results []ratelimit.Result
+}
+
+func TestMemoryStoreTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(MemoryStoreTestSuite))
+}
+
+func (suite *MemoryStoreTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.store = ratelimit.NewMemoryStore()
+	suite.limit = ratelimit.Limit{Requests: 1, Per: time.Second, Burst: 3}
+	suite.now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	suite.results = nil
+}
+
+func (suite *MemoryStoreTestSuite) givenTimePasses(d time.Duration) {
+	suite.now = suite.now.Add(d)
+}
+
+func (suite *MemoryStoreTestSuite) whenTakeIsCalled(key string, times int) {
+	for i := 0; i < times; i++ {
+		result, err := suite.store.Take(suite.ctx, key, suite.limit, suite.now)
+		suite.Require().NoError(err)
+		suite.results = append(suite.results, result)
+	}
+}
+
+func (suite *MemoryStoreTestSuite) thenResultShouldBe(index int, allowed bool, remaining int) {
+	suite.Equal(allowed, suite.results[index].Allowed, "allowed at %d", index)
+	suite.Equal(remaining, suite.results[index].Remaining, "remaining at %d", index)
+	suite.Equal(3, suite.results[index].Limit)
+}
+
+func (suite *MemoryStoreTestSuite) TestBurst_ShouldBeAllowedThenRejected() {
+	// When
+	suite.whenTakeIsCalled("ip:1", 4)
+
+	// Then
+	suite.thenResultShouldBe(0, true, 2)
+	suite.thenResultShouldBe(1, true, 1)
+	suite.thenResultShouldBe(2, true, 0)
+	suite.thenResultShouldBe(3, false, 0)
+	suite.Equal(time.Second, suite.results[3].RetryAfter)
+	suite.Equal(3*time.Second, suite.results[3].Reset)
+}
+
+func (suite *MemoryStoreTestSuite) TestInvalidLimit_ShouldBeRejected() {
+	for _, limit := range []ratelimit.Limit{
+		{Requests: 0, Per: time.Second},
+		{Requests: 1, Per: 0},
+		{Requests: 1, Per: time.Second, Burst: -1},
+	} {
+		// When
+		_, err := suite.store.Take(suite.ctx, "ip:1", limit, suite.now)
+
+		// Then
+		suite.ErrorIs(err, ratelimit.ErrInvalidLimit, "%+v", limit)
+	}
+}
+
+func (suite *MemoryStoreTestSuite) TestRefill_ShouldAddTokensOverTime() {
+	// Given
+	suite.whenTakeIsCalled("ip:1", 3)
+	suite.givenTimePasses(1500 * time.Millisecond)
+
+	// When
+	suite.whenTakeIsCalled("ip:1", 2)
+
+	// Then
+	suite.thenResultShouldBe(3, true, 0)
+	suite.thenResultShouldBe(4, false, 0)
+	suite.Equal(500*time.Millisecond, suite.results[4].RetryAfter)
+}
+
+func (suite *MemoryStoreTestSuite) TestRefill_ShouldNotExceedBurst() {
+	// Given
+	suite.whenTakeIsCalled("ip:1", 1)
+	suite.givenTimePasses(time.Hour)
+
+	// When
+	suite.whenTakeIsCalled("ip:1", 1)
+
+	// Then
+	suite.thenResultShouldBe(1, true, 2)
+}
+
+func (suite *MemoryStoreTestSuite) TestKeys_ShouldHaveSeparateBuckets() {
+	// Given
+	suite.whenTakeIsCalled("ip:1", 4)
+
+	// When
+	suite.whenTakeIsCalled("ip:2", 1)
+
+	// Then
+	suite.thenResultShouldBe(3, false, 0)
+	suite.thenResultShouldBe(4, true, 2)
+}
+
+func (suite *MemoryStoreTestSuite) TestSweptBucket_ShouldStartFull() {
+	// Given
+	suite.whenTakeIsCalled("ip:1", 3)
+	suite.givenTimePasses(2 * time.Minute)
+
+	// When
+	suite.whenTakeIsCalled("ip:2", 1)
+	suite.whenTakeIsCalled("ip:1", 1)
+
+	// Then
+	suite.thenResultShouldBe(4, true, 2)
+}
+
+func (suite *MemoryStoreTestSuite) TestConcurrentTakes_ShouldNotOverspend() {
+	// Given
+	suite.limit = ratelimit.Limit{Requests: 1, Per: time.Hour, Burst: 20}
+	var allowed int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// When
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := suite.store.Take(suite.ctx, "ip:1", suite.limit, suite.now)
+			suite.NoError(err)
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then
+	suite.Equal(20, allowed)
+}
@@ -0,0 +1,319 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/openapi"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/router"
+)
+
+type RouterTestSuite struct {
+	suite.Suite
+	ctx        context.Context
+	router     *router.Router
+	request    events.APIGatewayProxyRequest
+	response   events.APIGatewayProxyResponse
+	err        error
+	calledWith events.APIGatewayProxyRequest
+	calledName string
+}
+
+func TestRouterTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(RouterTestSuite))
+}
+
+func (suite *RouterTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.router = router.New()
+	suite.request = events.APIGatewayProxyRequest{}
+	suite.err = nil
+	suite.calledName = ""
+}
+
+func (suite *RouterTestSuite) recordingHandler(name string) handlers.Handler {
+	return func(_ context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		suite.calledName = name
+		suite.calledWith = request
+		return events.APIGatewayProxyResponse{StatusCode: 200, Body: name}, nil
+	}
+}
+
+func (suite *RouterTestSuite) givenRoute(method, pattern, name string) {
+	suite.router.Handle(method, pattern, suite.recordingHandler(name))
+}
+
+func (suite *RouterTestSuite) givenRequest(method, path string) {
+	suite.request.HTTPMethod = method
+	suite.request.Path = path
+}
+
+func (suite *RouterTestSuite) whenHandleRequestIsCalled() {
+	suite.response, suite.err = suite.router.HandleRequest(suite.ctx, suite.request)
+}
+
+func (suite *RouterTestSuite) thenHandlerShouldBeCalled(name string) {
+	suite.NoError(suite.err)
+	suite.Equal(200, suite.response.StatusCode)
+	suite.Equal(name, suite.calledName)
+}
+
+func (suite *RouterTestSuite) thenPathParameterShouldBe(key, value string) {
+	suite.Equal(value, suite.calledWith.PathParameters[key])
+}
+
+func (suite *RouterTestSuite) thenErrorResponseShouldBe(statusCode int, code, message string) {
+	suite.NoError(suite.err)
+	suite.Empty(suite.calledName)
+	suite.Equal(statusCode, suite.response.StatusCode)
+	suite.Equal("application/json", suite.response.Headers["Content-Type"])
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal([]byte(suite.response.Body), &body))
+	suite.Equal(message, body["error"])
+	suite.Equal(strconv.Itoa(statusCode), body["status"])
+	suite.Equal(code, body["code"])
+}
+
+func (suite *RouterTestSuite) thenAllowHeaderShouldBe(allow string) {
+	suite.Equal(allow, suite.response.Headers["Allow"])
+}
+
+func (suite *RouterTestSuite) TestStaticRoute() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "hello")
+	suite.givenRequest(http.MethodGet, "/hello")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenHandlerShouldBeCalled("hello")
+}
+
+func (suite *RouterTestSuite) TestTrailingSlash_ShouldMatch() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "hello")
+	suite.givenRequest(http.MethodGet, "/hello/")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenHandlerShouldBeCalled("hello")
+}
+
+func (suite *RouterTestSuite) TestMethod_ShouldBeCaseInsensitive() {
+	// Given
+	suite.givenRoute("get", "/hello", "hello")
+	suite.givenRequest("GET", "/hello")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenHandlerShouldBeCalled("hello")
+}
+
+func (suite *RouterTestSuite) TestPathParameter() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/users/{id}/greetings", "greetings")
+	suite.givenRequest(http.MethodGet, "/users/42/greetings")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenHandlerShouldBeCalled("greetings")
+	suite.thenPathParameterShouldBe("id", "42")
+}
+
+func (suite *RouterTestSuite) TestGreedyPathParameter() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/files/{proxy+}", "files")
+	suite.givenRequest(http.MethodGet, "/files/a/b/c.txt")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenHandlerShouldBeCalled("files")
+	suite.thenPathParameterShouldBe("proxy", "a/b/c.txt")
+}
+
+func (suite *RouterTestSuite) TestFirstRegisteredRouteWins() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/users/me", "me")
+	suite.givenRoute(http.MethodGet, "/users/{id}", "user")
+	suite.givenRequest(http.MethodGet, "/users/me")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenHandlerShouldBeCalled("me")
+}
+
+func (suite *RouterTestSuite) TestMethodMismatch_ShouldFallThroughToLaterRoute() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "get")
+	suite.givenRoute(http.MethodPost, "/hello", "post")
+	suite.givenRequest(http.MethodPost, "/hello")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenHandlerShouldBeCalled("post")
+}
+
+func (suite *RouterTestSuite) TestUnknownPath_ShouldReturnNotFound() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "hello")
+	suite.givenRequest(http.MethodGet, "/goodbye")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorResponseShouldBe(404, handlers.CodeRouteNotFound, "Route not found")
+}
+
+func (suite *RouterTestSuite) TestExtraSegments_ShouldReturnNotFound() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/users/{id}", "user")
+	suite.givenRequest(http.MethodGet, "/users/42/extra")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorResponseShouldBe(404, handlers.CodeRouteNotFound, "Route not found")
+}
+
+func (suite *RouterTestSuite) TestWrongMethod_ShouldReturnMethodNotAllowed() {
+	// Given
+	suite.givenRoute(http.MethodPost, "/hello", "post")
+	suite.givenRoute(http.MethodGet, "/hello", "get")
+	suite.givenRequest(http.MethodDelete, "/hello")
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorResponseShouldBe(405, handlers.CodeMethodNotAllowed, "Method not allowed")
+	suite.thenAllowHeaderShouldBe("GET, POST")
+}
+
+func (suite *RouterTestSuite) TestRoutes_ShouldListRegistrationOrder() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "hello")
+	suite.givenRoute(http.MethodPost, "/users/{id}", "user")
+
+	// Then
+	suite.Equal([]string{"GET /hello", "POST /users/{id}"}, suite.router.Routes())
+}
+
+func (suite *RouterTestSuite) TestInvalidPatterns_ShouldPanic() {
+	for _, pattern := range []string{
+		"hello",
+		"/users/id-{id}",
+		"/users/{}",
+		"/users/{id}/{id}",
+		"/files/{proxy+}/tail",
+	} {
+		suite.Panics(func() {
+			suite.router.Handle(http.MethodGet, pattern, suite.recordingHandler("invalid"))
+		}, pattern)
+	}
+}
+
+func (suite *RouterTestSuite) TestUse_ShouldWrapUnmatchedRequests() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "hello")
+	suite.router.Use(handlers.CORS(handlers.DefaultCORSConfig()))
+	suite.givenRequest(http.MethodGet, "/missing")
+	suite.request.Headers = map[string]string{"Origin": "https://app.example.com"}
+
+	// When
+	suite.whenHandleRequestIsCalled()
+
+	// Then
+	suite.thenErrorResponseShouldBe(404, handlers.CodeRouteNotFound, "Route not found")
+	suite.Equal("*", suite.response.Headers["Access-Control-Allow-Origin"])
+}
+
+func (suite *RouterTestSuite) TestOpenAPI_ShouldDocumentEveryRoute() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "hello")
+	suite.givenRoute(http.MethodPost, "/hello", "create")
+	suite.givenRoute(http.MethodGet, "/files/{proxy+}", "files")
+	suite.router.Describe(http.MethodGet, "/hello", openapi.Operation{
+		OperationID: "getHello",
+		Responses:   map[string]openapi.Response{"200": {Description: "Greeting"}},
+	})
+
+	// When
+	document := suite.router.OpenAPI(openapi.Document{Info: openapi.Info{Title: "Test", Version: "1"}})
+
+	// Then
+	suite.Equal(openapi.Version, document.OpenAPI)
+	suite.Equal("Test", document.Info.Title)
+	suite.Equal("getHello", document.Paths["/hello"]["get"].OperationID)
+	suite.Contains(document.Paths["/hello"]["post"].Responses, "default")
+	suite.Equal([]openapi.Parameter{{
+		Name:     "proxy",
+		In:       openapi.InPath,
+		Required: true,
+		Schema:   &openapi.Schema{Type: "string"},
+	}}, document.Paths["/files/{proxy}"]["get"].Parameters)
+}
+
+func (suite *RouterTestSuite) TestOpenAPI_ShouldKeepDescribedPathParameters() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/users/{id}", "user")
+	described := openapi.Parameter{Name: "id", In: openapi.InPath, Required: true, Description: "User ID"}
+	suite.router.Describe(http.MethodGet, "/users/{id}", openapi.Operation{
+		Parameters: []openapi.Parameter{described},
+		Responses:  map[string]openapi.Response{"200": {Description: "User"}},
+	})
+
+	// When
+	document := suite.router.OpenAPI(openapi.Document{})
+
+	// Then
+	suite.Equal([]openapi.Parameter{described}, document.Paths["/users/{id}"]["get"].Parameters)
+}
+
+func (suite *RouterTestSuite) TestOpenAPI_ShouldNotModifyBase() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "hello")
+	base := openapi.Document{Paths: map[string]openapi.PathItem{
+		"/hello": {"delete": &openapi.Operation{OperationID: "external"}},
+	}}
+
+	// When
+	document := suite.router.OpenAPI(base)
+
+	// Then
+	suite.Len(document.Paths["/hello"], 2)
+	suite.Len(base.Paths["/hello"], 1)
+}
+
+func (suite *RouterTestSuite) TestDescribeUnregisteredRoute_ShouldPanic() {
+	// Given
+	suite.givenRoute(http.MethodGet, "/hello", "hello")
+
+	// When / Then
+	suite.PanicsWithValue("router: cannot describe unregistered route POST /hello", func() {
+		suite.router.Describe(http.MethodPost, "/hello", openapi.Operation{})
+	})
+}
@@ -0,0 +1,341 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/config"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/handlers"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/routes"
+	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/server"
+	service "github.com/javiertelioz/aws-lambda-golang/test/mocks"
+)
+
+type HTTPAdapterTestSuite struct {
+	suite.Suite
+	logger   *service.MockLogger
+	adapter  *server.HTTPAdapter
+	request  *http.Request
+	recorder *httptest.ResponseRecorder
+	captured events.APIGatewayProxyRequest
+}
+
+func TestHTTPAdapterTestSuite(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, new(HTTPAdapterTestSuite))
+}
+
+func (suite *HTTPAdapterTestSuite) SetupTest() {
+	suite.logger = new(service.MockLogger)
+	suite.logger.On("Log", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	suite.recorder = httptest.NewRecorder()
+	suite.captured = events.APIGatewayProxyRequest{}
+}
+
+func (suite *HTTPAdapterTestSuite) givenRealRoutes() {
+	suite.adapter = server.NewHTTPAdapter(routes.New(routes.Config{Logger: suite.logger}).HandleRequest, suite.logger)
+}
+
+func (suite *HTTPAdapterTestSuite) givenHandlerReturning(response events.APIGatewayProxyResponse, err error) {
+	suite.adapter = server.NewHTTPAdapter(func(_ context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		suite.captured = request
+		return response, err
+	}, suite.logger)
+}
+
+func (suite *HTTPAdapterTestSuite) givenRequest(method, target string, body []byte) {
+	suite.request = httptest.NewRequest(method, target, bytes.NewReader(body))
+}
+
+func (suite *HTTPAdapterTestSuite) whenServeHTTPIsCalled() {
+	suite.adapter.ServeHTTP(suite.recorder, suite.request)
+}
+
+func (suite *HTTPAdapterTestSuite) thenStatusShouldBe(statusCode int) {
+	suite.Equal(statusCode, suite.recorder.Code)
+}
+
+func (suite *HTTPAdapterTestSuite) thenBodyShouldBe(expected string) {
+	suite.Equal(expected, suite.recorder.Body.String())
+}
+
+func (suite *HTTPAdapterTestSuite) thenBodyShouldContain(expected string) {
+	suite.Contains(suite.recorder.Body.String(), expected)
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_WithName() {
+	// Given
+	suite.givenRealRoutes()
+	suite.givenRequest(http.MethodGet, "/hello?name=Ana", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.thenBodyShouldBe(`{"message":"Hello Ana!","name":"Ana"}`)
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_ValidationError() {
+	// Given
+	suite.givenRealRoutes()
+	suite.givenRequest(http.MethodGet, "/hello?name=%3Cscript%3E", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(400)
+	suite.Equal("application/json", suite.recorder.Header().Get("Content-Type"))
+	suite.thenBodyShouldContain("contains invalid characters")
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_UnknownRoute() {
+	// Given
+	suite.givenRealRoutes()
+	suite.givenRequest(http.MethodGet, "/missing", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(404)
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_OpenAPIDocument() {
+	// Given
+	suite.givenRealRoutes()
+	suite.givenRequest(http.MethodGet, "/openapi.json", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+
+	var document struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	suite.Require().NoError(json.Unmarshal(suite.recorder.Body.Bytes(), &document))
+	suite.Equal("3.1.0", document.OpenAPI)
+	suite.Contains(document.Paths, "/hello")
+	suite.Contains(document.Paths, "/hello/batch")
+	suite.Contains(document.Paths, "/openapi.json")
+	suite.Contains(document.Paths, "/health")
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_Health() {
+	// Given
+	suite.givenRealRoutes()
+	suite.givenRequest(http.MethodGet, "/health", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.Equal("no-store", suite.recorder.Header().Get("Cache-Control"))
+	suite.thenBodyShouldContain(`"status":"ok"`)
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_Hello_ShouldBeSharedCacheable() {
+	// Given
+	suite.givenRealRoutes()
+	suite.givenRequest(http.MethodGet, "/hello?name=Ana", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.Equal("public, max-age=300, s-maxage=3600", suite.recorder.Header().Get("Cache-Control"))
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_WithFeatureFlags_HelloShouldBePrivate() {
+	// Given
+	flagsFile := filepath.Join(suite.T().TempDir(), "flags.json")
+	suite.Require().NoError(os.WriteFile(flagsFile, []byte(`{"flags":{}}`), 0o600))
+	cfg, err := config.Load(func(key string) string {
+		if key == config.EnvFeatureFlagsFile {
+			return flagsFile
+		}
+		return ""
+	})
+	suite.Require().NoError(err)
+	suite.adapter = server.NewHTTPAdapter(routes.NewFromConfig(cfg, suite.logger).HandleRequest, suite.logger)
+	suite.givenRequest(http.MethodGet, "/hello?name=Ana", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(200)
+	suite.Equal("private, max-age=300", suite.recorder.Header().Get("Cache-Control"))
+	suite.Contains(suite.recorder.Header().Get("Vary"), "Authorization")
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_WithJWTConfig_ShouldRequireTokenButForHealth() {
+	// Given
+	cfg, err := config.Load(func(key string) string {
+		if key == config.EnvJWTHS256Secret {
+			return "secret"
+		}
+		return ""
+	})
+	suite.Require().NoError(err)
+	suite.adapter = server.NewHTTPAdapter(routes.NewFromConfig(cfg, suite.logger).HandleRequest, suite.logger)
+
+	// When
+	suite.givenRequest(http.MethodGet, "/hello?name=Ana", nil)
+	suite.whenServeHTTPIsCalled()
+	hello := suite.recorder
+	suite.recorder = httptest.NewRecorder()
+	suite.givenRequest(http.MethodGet, "/health", nil)
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.Equal(http.StatusUnauthorized, hello.Code)
+	suite.Contains(hello.Body.String(), handlers.CodeMissingToken)
+	suite.thenStatusShouldBe(http.StatusOK)
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_Panic_ShouldReturnInternalError() {
+	// Given
+	suite.adapter = server.NewHTTPAdapter(routes.New(routes.Config{
+		Logger: suite.logger,
+		Health: handlers.HealthConfig{Now: func() time.Time { panic("clock unavailable") }},
+	}).HandleRequest, suite.logger)
+	suite.givenRequest(http.MethodGet, "/health", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(500)
+	suite.thenBodyShouldContain(handlers.CodeInternalError)
+}
+
+func (suite *HTTPAdapterTestSuite) TestRealHandler_RouterLogs_ShouldCarryRequestID() {
+	// Given
+	suite.givenRealRoutes()
+
+	// When
+	for i := 0; i < 25; i++ {
+		suite.recorder = httptest.NewRecorder()
+		suite.givenRequest(http.MethodGet, "/health", nil)
+		suite.whenServeHTTPIsCalled()
+	}
+
+	// Then
+	suite.thenStatusShouldBe(http.StatusTooManyRequests)
+	hasRequestID := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value("request_id") != nil })
+	suite.logger.AssertCalled(suite.T(), "Log", hasRequestID, services.LevelDebug, "Request received", mock.Anything)
+	suite.logger.AssertCalled(suite.T(), "Log", hasRequestID, services.LevelWarn, "Rate limit exceeded", mock.Anything)
+	withoutRequestID := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value("request_id") == nil })
+	suite.logger.AssertNotCalled(suite.T(), "Log", withoutRequestID, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *HTTPAdapterTestSuite) TestRequestTranslation() {
+	// Given
+	suite.givenHandlerReturning(events.APIGatewayProxyResponse{StatusCode: 204}, nil)
+	suite.givenRequest(http.MethodPost, "/items?tag=a&tag=b", []byte(`{"ok":true}`))
+	suite.request.Header.Add("X-Custom", "one")
+	suite.request.Header.Add("X-Custom", "two")
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(204)
+	suite.Equal(http.MethodPost, suite.captured.HTTPMethod)
+	suite.Equal("/items", suite.captured.Path)
+	suite.Equal("a", suite.captured.QueryStringParameters["tag"])
+	suite.Equal([]string{"a", "b"}, suite.captured.MultiValueQueryStringParameters["tag"])
+	suite.Equal("one", suite.captured.Headers["X-Custom"])
+	suite.Equal([]string{"one", "two"}, suite.captured.MultiValueHeaders["X-Custom"])
+	suite.Equal(`{"ok":true}`, suite.captured.Body)
+	suite.False(suite.captured.IsBase64Encoded)
+	suite.Equal(server.LocalStage, suite.captured.RequestContext.Stage)
+	suite.NotEmpty(suite.captured.RequestContext.RequestID)
+	suite.NotEmpty(suite.captured.RequestContext.Identity.SourceIP)
+}
+
+func (suite *HTTPAdapterTestSuite) TestBinaryBody_ShouldBeBase64Encoded() {
+	// Given
+	binary := []byte{0xff, 0xfe, 0x00}
+	suite.givenHandlerReturning(events.APIGatewayProxyResponse{StatusCode: 200}, nil)
+	suite.givenRequest(http.MethodPost, "/upload", binary)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.True(suite.captured.IsBase64Encoded)
+	suite.Equal(base64.StdEncoding.EncodeToString(binary), suite.captured.Body)
+}
+
+func (suite *HTTPAdapterTestSuite) TestOversizedBody_ShouldReturnRequestTooLong() {
+	// Given
+	suite.givenHandlerReturning(events.APIGatewayProxyResponse{StatusCode: 200}, nil)
+	suite.givenRequest(http.MethodPost, "/upload", []byte(strings.Repeat("a", server.MaxBodyBytes+1)))
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(413)
+}
+
+func (suite *HTTPAdapterTestSuite) TestResponseTranslation() {
+	// Given
+	suite.givenHandlerReturning(events.APIGatewayProxyResponse{
+		StatusCode:        201,
+		Headers:           map[string]string{"Content-Type": "text/plain"},
+		MultiValueHeaders: map[string][]string{"Set-Cookie": {"a=1", "b=2"}},
+		Body:              base64.StdEncoding.EncodeToString([]byte("created")),
+		IsBase64Encoded:   true,
+	}, nil)
+	suite.givenRequest(http.MethodPost, "/items", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(201)
+	suite.thenBodyShouldBe("created")
+	suite.Equal("text/plain", suite.recorder.Header().Get("Content-Type"))
+	suite.Equal([]string{"a=1", "b=2"}, suite.recorder.Header().Values("Set-Cookie"))
+}
+
+func (suite *HTTPAdapterTestSuite) TestHandlerError_ShouldReturnBadGateway() {
+	// Given
+	suite.givenHandlerReturning(events.APIGatewayProxyResponse{}, errors.New("boom"))
+	suite.givenRequest(http.MethodGet, "/hello", nil)
+
+	// When
+	suite.whenServeHTTPIsCalled()
+
+	// Then
+	suite.thenStatusShouldBe(502)
+
+	var body map[string]string
+	suite.Require().NoError(json.Unmarshal(suite.recorder.Body.Bytes(), &body))
+	suite.Equal("Internal server error", body["message"])
+	suite.logger.AssertCalled(suite.T(), "Log", mock.Anything, mock.Anything, "Handler returned an error", mock.Anything)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
 	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/services"
 	"github.com/javiertelioz/aws-lambda-golang/pkg/infrastructure/sevices/logger"
 )
@@ -209,6 +210,22 @@ func (suite *ZerologLoggerTestSuite) TestLogWithUserID_ShouldIncludeInLog() {
 	suite.Equal(float64(12345), logEntry["user_id"]) // JSON unmarshals numbers as float64
 }
 
+func (suite *ZerologLoggerTestSuite) TestLogWithPrincipal_ShouldIncludeSubjectAsUserID() {
+	// Given
+	ctx := context.WithValue(context.Background(), "user_id", "legacy")
+	ctx = entities.ContextWithPrincipal(ctx, entities.Principal{Subject: "user-42"})
+	suite.logMessage = "User action"
+
+	// When
+	suite.logger.Log(ctx, services.LevelInfo, suite.logMessage)
+
+	// Then
+	var logEntry map[string]interface{}
+	err := json.Unmarshal(suite.logOutput.Bytes(), &logEntry)
+	suite.NoError(err)
+	suite.Equal("user-42", logEntry["user_id"])
+}
+
 func (suite *ZerologLoggerTestSuite) TestLogWithAllContextValues_ShouldIncludeAll() {
 	// Given
 	ctx := context.Background()
@@ -307,3 +324,46 @@ func (suite *ZerologLoggerTestSuite) TestLogShouldIncludeFileAndLine() {
 	_, ok := logEntry["line"].(float64)
 	suite.True(ok)
 }
+
+func (suite *ZerologLoggerTestSuite) TestConfiguredLevel_ShouldDropLowerLevels() {
+	// Given
+	output := &bytes.Buffer{}
+	configured := logger.New(logger.Config{Level: services.LevelWarn, Output: output})
+
+	// When
+	configured.Log(context.Background(), services.LevelInfo, "dropped")
+	configured.Log(context.Background(), services.LevelWarn, "kept")
+
+	// Then
+	var logEntry map[string]interface{}
+	suite.Require().NoError(json.Unmarshal(output.Bytes(), &logEntry))
+	suite.Equal("kept", logEntry["message"])
+	suite.Equal("warn", logEntry["level"])
+	suite.Empty(suite.logOutput.String(), "a configured output must not write to the global logger")
+}
+
+func (suite *ZerologLoggerTestSuite) TestConsoleFormat_ShouldWriteReadableLines() {
+	// Given
+	output := &bytes.Buffer{}
+	configured := logger.New(logger.Config{Format: logger.FormatConsole, Output: output})
+
+	// When
+	configured.Log(context.Background(), services.LevelInfo, "Readable message",
+		services.Field{Key: "name", Value: "Ana"},
+	)
+
+	// Then
+	suite.Contains(output.String(), "Readable message")
+	suite.Contains(output.String(), "name=")
+	suite.False(json.Valid(output.Bytes()))
+}
+
+func (suite *ZerologLoggerTestSuite) TestParseFormat() {
+	// When / Then
+	format, err := logger.ParseFormat(" Console ")
+	suite.NoError(err)
+	suite.Equal(logger.FormatConsole, format)
+
+	_, err = logger.ParseFormat("xml")
+	suite.Error(err)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBStandIn is an in-memory stand-in for a DynamoDB table, for testing
+// stores against DynamoDB semantics without a network. Items are keyed by the
+// "pk" attribute. Condition expressions support attribute_exists,
+// attribute_not_exists and =, <>, <, <=, >, >= comparisons, joined by AND or OR
+// (AND binds tighter, no parentheses).
+type DynamoDBStandIn struct {
+	mu    sync.Mutex
+	items map[string]map[string]types.AttributeValue
+
+	// Err, when set, is returned by every call.
+	Err error
+	// Writes counts successful PutItem and DeleteItem calls.
+	Writes int
+}
+
+// NewDynamoDBStandIn returns an empty DynamoDBStandIn.
+func NewDynamoDBStandIn() *DynamoDBStandIn {
+	return &DynamoDBStandIn{items: make(map[string]map[string]types.AttributeValue)}
+}
+
+// Item returns a copy of the stored item for pk, or nil.
+func (d *DynamoDBStandIn) Item(pk string) map[string]types.AttributeValue {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return copyItem(d.items[pk])
+}
+
+// GetItem implements the DynamoDB GetItem call.
+func (d *DynamoDBStandIn) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+
+	return &dynamodb.GetItemOutput{Item: copyItem(d.items[keyOf(params.Key)])}, nil
+}
+
+// PutItem implements the DynamoDB PutItem call, honouring ConditionExpression.
+func (d *DynamoDBStandIn) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+
+	key := keyOf(params.Item)
+	if err := d.check(key, params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues); err != nil {
+		return nil, err
+	}
+
+	d.items[key] = copyItem(params.Item)
+	d.Writes++
+
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// DeleteItem implements the DynamoDB DeleteItem call, honouring ConditionExpression.
+func (d *DynamoDBStandIn) DeleteItem(_ context.Context, params *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+
+	key := keyOf(params.Key)
+	if err := d.check(key, params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues); err != nil {
+		return nil, err
+	}
+
+	delete(d.items, key)
+	d.Writes++
+
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (d *DynamoDBStandIn) check(key string, condition *string, names map[string]string, values map[string]types.AttributeValue) error {
+	expression := aws.ToString(condition)
+	if expression == "" {
+		return nil
+	}
+
+	item := d.items[key]
+	for _, alternative := range strings.Split(expression, " OR ") {
+		matched := true
+		for _, term := range strings.Split(alternative, " AND ") {
+			ok, err := evaluate(strings.TrimSpace(term), item, names, values)
+			if err != nil {
+				return err
+			}
+			matched = matched && ok
+		}
+		if matched {
+			return nil
+		}
+	}
+
+	return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+}
+
+func evaluate(term string, item map[string]types.AttributeValue, names map[string]string, values map[string]types.AttributeValue) (bool, error) {
+	resolve := func(name string) string {
+		if resolved, ok := names[name]; ok {
+			return resolved
+		}
+		return name
+	}
+
+	for _, function := range []string{"attribute_not_exists", "attribute_exists"} {
+		if strings.HasPrefix(term, function+"(") && strings.HasSuffix(term, ")") {
+			_, exists := item[resolve(strings.TrimSuffix(strings.TrimPrefix(term, function+"("), ")"))]
+			return exists == (function == "attribute_exists"), nil
+		}
+	}
+
+	for _, operator := range []string{"<>", "<=", ">=", "=", "<", ">"} {
+		left, right, ok := strings.Cut(term, " "+operator+" ")
+		if !ok {
+			continue
+		}
+
+		actual, exists := item[resolve(strings.TrimSpace(left))]
+		expected, found := values[strings.TrimSpace(right)]
+		if !found {
+			return false, fmt.Errorf("stand-in: missing expression value %s", right)
+		}
+		if !exists {
+			return false, nil
+		}
+
+		return compare(actual, expected, operator)
+	}
+
+	return false, fmt.Errorf("stand-in: unsupported condition %q", term)
+}
+
+func compare(actual, expected types.AttributeValue, operator string) (bool, error) {
+	var cmp int
+	switch a := actual.(type) {
+	case *types.AttributeValueMemberN:
+		e, ok := expected.(*types.AttributeValueMemberN)
+		if !ok {
+			return false, nil
+		}
+		x, _ := strconv.ParseFloat(a.Value, 64)
+		y, _ := strconv.ParseFloat(e.Value, 64)
+		switch {
+		case x < y:
+			cmp = -1
+		case x > y:
+			cmp = 1
+		}
+	case *types.AttributeValueMemberS:
+		e, ok := expected.(*types.AttributeValueMemberS)
+		if !ok {
+			return false, nil
+		}
+		cmp = strings.Compare(a.Value, e.Value)
+	default:
+		return false, fmt.Errorf("stand-in: unsupported attribute type %T", actual)
+	}
+
+	switch operator {
+	case "=":
+		return cmp == 0, nil
+	case "<>":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func keyOf(item map[string]types.AttributeValue) string {
+	if pk, ok := item["pk"].(*types.AttributeValueMemberS); ok {
+		return pk.Value
+	}
+
+	return ""
+}
+
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	copied := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		copied[k] = v
+	}
+
+	return copied
+}
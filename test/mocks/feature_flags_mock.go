@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/javiertelioz/aws-lambda-golang/pkg/domain/entities"
+)
+
+// MockFeatureFlags is a mock implementation of the FeatureFlags interface for testing.
+type MockFeatureFlags struct {
+	mock.Mock
+}
+
+// Bool mocks the Bool method of the FeatureFlags interface.
+func (m *MockFeatureFlags) Bool(ctx context.Context, flag string, target entities.FlagTarget, def bool) bool {
+	return m.Called(ctx, flag, target, def).Bool(0)
+}
+
+// Variant mocks the Variant method of the FeatureFlags interface.
+func (m *MockFeatureFlags) Variant(ctx context.Context, flag string, target entities.FlagTarget, def string) string {
+	return m.Called(ctx, flag, target, def).String(0)
+}
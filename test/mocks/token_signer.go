@@ -0,0 +1,117 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+)
+
+// TokenSigner issues JWTs signed with freshly generated HS256, RS256 and ES256
+// keys, and renders the matching JWKS document, for testing token verification.
+type TokenSigner struct {
+	Secret     []byte
+	RSAKey     *rsa.PrivateKey
+	ECDSAKey   *ecdsa.PrivateKey
+	RSAKeyID   string
+	ECDSAKeyID string
+}
+
+// NewTokenSigner generates a new set of signing keys.
+func NewTokenSigner() *TokenSigner {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	return &TokenSigner{
+		Secret:     []byte("test-secret-with-enough-entropy!"),
+		RSAKey:     rsaKey,
+		ECDSAKey:   ecdsaKey,
+		RSAKeyID:   "rsa-1",
+		ECDSAKeyID: "ec-1",
+	}
+}
+
+// Sign returns a compact JWT with the given header alg and kid and claims.
+// Unknown algorithms produce an empty signature.
+func (s *TokenSigner) Sign(alg, kid string, claims map[string]interface{}) string {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+
+	signingInput := encodeSegment(header) + "." + encodeSegment(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, s.Secret)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case "RS256":
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, s.RSAKey, crypto.SHA256, digest[:])
+	case "ES256":
+		r, sig, _ := ecdsa.Sign(rand.Reader, s.ECDSAKey, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), sig.FillBytes(make([]byte, 32))...)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// JWKS renders the public RSA and EC keys, plus the HMAC secret as an "oct" key
+// with kid "hmac-1", as a JWKS document.
+func (s *TokenSigner) JWKS() []byte {
+	point, _ := s.ECDSAKey.PublicKey.Bytes()
+
+	document := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": s.RSAKeyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(s.RSAKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.RSAKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": s.ECDSAKeyID,
+				"use": "sig",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(point[1:33]),
+				"y":   base64.RawURLEncoding.EncodeToString(point[33:]),
+			},
+			{
+				"kty": "oct",
+				"kid": "hmac-1",
+				"k":   base64.RawURLEncoding.EncodeToString(s.Secret),
+			},
+			{
+				"kty": "RSA",
+				"kid": "encryption-only",
+				"use": "enc",
+			},
+		},
+	}
+
+	data, _ := json.Marshal(document)
+
+	return data
+}
+
+func encodeSegment(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}